@@ -1,16 +1,13 @@
 package api
 
 import (
-	"encoding/json"
-	"errors"
 	api_models "filmoteka/api/models"
 	"filmoteka/db"
-	"log/slog"
+	"filmoteka/errs"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/go-pg/pg/v10"
 )
 
@@ -19,49 +16,118 @@ import (
 // @Description  Availible only for authenticated user, getting actors list from db
 // @Tags         actors
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
 // @Router       /actors [get]
+// @Param include query string false "Included relations, films, also accepted as expand" example(films)
+// @Param fields[actors] query string false "Comma separated fields of actors to return, also accepted as fields" example(id,name)
+// @Param fields[films] query string false "Comma separated fields of included films" example(id,name,rate)
+// @Param limit query int false "Page size from 1 to 1000, all actors when omitted" minimum(1) maximum(1000)
+// @Param offset query int false "Number of actors to skip, used with limit" minimum(0)
 // @Security BasicAuth
 // @Success 200 {object} api_models.ActorsResponse
 // @Failure 401 {object}  ErrorResponse
 // @Failure 400 {object} ErrorResponse
 func getActors(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
 	_, err := checkBasicAuth(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
 
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+	view, err := parseView(r, api_models.ActorResource, false)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	actors, err := db.GetActors(pgdb)
+	sel := view.Selection(api_models.ActorResource)
+	err = parsePage(r, sel)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
+		return
+	}
+
+	actors, err := db.GetActors(pgdb, sel)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
 	res := &api_models.ActorsResponse{
 		Success: true,
 		Error:   "",
-		Actors:  actors,
+		Actors:  api_models.NewActors(actors, view),
 	}
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(res)
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// getActor godoc
+// @Summary      Get actor
+// @Description  Availible only for authenticated user, getting actor by id with films
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param actorID path int true "Actors Id"
+// @Param include query string false "Included relations, films" example(films)
+// @Param fields[actors] query string false "Comma separated fields of actor to return" example(id,name)
+// @Param fields[films] query string false "Comma separated fields of included films" example(id,name,rate)
+// @Router       /actors/{actorID} [get]
+// @Security BasicAuth
+// @Success 200 {object} api_models.ActorResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object} ErrorResponse
+func getActor(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
+	view, err := parseView(r, api_models.ActorResource, true)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	actorID, err := idParam(r, "actorID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	actor, err := db.GetActor(pgdb, actorID, view.Selection(api_models.ActorResource))
+	if err != nil {
+		HandleError(w, r, notFound(err, "actor"))
+		return
+	}
+
+	res := &api_models.ActorResponse{
+		Success: true,
+		Error:   "",
+		Actor:   api_models.NewActor(actor, view),
+	}
+	writeResponse(w, r, http.StatusOK, res)
 }
 
 // createActor godoc
@@ -69,71 +135,74 @@ func getActors(w http.ResponseWriter, r *http.Request) {
 // @Description  Availible only for admin user, creating actor using data from request body and return new actor
 // @Tags         actors
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
 // @Router       /actors [post]
-// @Param Actor body db.Actor true "actor info"
+// @Param expand query string false "Nested relations, films" example(films)
+// @Param fields query string false "Comma separated fields of actors to return" example(id,name)
+// @Param Actor body api_models.CreateActorRequest true "actor info"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request, repeated requests get the first response" example(9f4c2a1e-create-film)
 // @Security BasicAuth
 // @Success 200 {object} api_models.ActorResponse
 // @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 409 {object}  ErrorResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 422 {object}  ErrorResponse
 func createActor(w http.ResponseWriter, r *http.Request) {
-	auth_role, err := checkBasicAuth(r)
-	if err != nil || auth_role != db.Admin {
-		if err == nil {
-			err = errors.New("wrong access level")
-		}
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
-	req := &api_models.CreateActorRequest{}
-	err = json.NewDecoder(r.Body).Decode(req)
-	err = Validate.Struct(req)
 
+	view, err := parseView(r, api_models.ActorResource, true)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
+	req := &api_models.CreateActorRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
 
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, errors.New("could not get the DB from context"))
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	birthday, err := time.Parse("2006-01-02", req.Birth)
+	birthday, err := time.Parse(db.DateLayout, req.Birth)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	actor, err := db.CreateActor(pgdb, &db.Actor{
-		Name:  req.Name,
-		Sex:   req.Sex,
-		Birth: birthday,
+	var actor *db.Actor
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		actor, err = db.CreateActor(tx, &db.Actor{
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: birthday,
+		})
+		return err
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
 	res := &api_models.ActorResponse{
 		Success: true,
 		Error:   "",
-		Actor:   actor,
+		Actor:   api_models.NewActor(actor, view),
 	}
-	err = json.NewEncoder(w).Encode(res)
-	if err != nil {
-		slog.Error("error encoding after creating actor %s\n", err)
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
-		return
-	}
-	w.WriteHeader(http.StatusOK)
+	writeResponse(w, r, http.StatusOK, res)
 }
 
 // updateActor godoc
@@ -141,83 +210,82 @@ func createActor(w http.ResponseWriter, r *http.Request) {
 // @Description  Availible only for admin user, updating actor using id from request params and return actor
 // @Tags         actors
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
-// @Param actorID query string true "Actors Id"
-// @Param Actor body db.Actor true "actor info"
-// @Router       /actors [put]
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param actorID path int true "Actors Id"
+// @Param expand query string false "Nested relations, films" example(films)
+// @Param fields query string false "Comma separated fields of actors to return" example(id,name)
+// @Param Actor body api_models.UpdateActorRequest true "actor info, omitted fields are left as is"
+// @Router       /actors/{actorID} [put]
 // @Security BasicAuth
 // @Success 200 {object} api_models.ActorResponse
 // @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
 // @Failure 400 {object} ErrorResponse
 func updateActor(w http.ResponseWriter, r *http.Request) {
-	auth_role, err := checkBasicAuth(r)
-	if err != nil || auth_role != db.Admin {
-		if err == nil {
-			err = errors.New("wrong access level")
-		}
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
-	req := &api_models.UpdateActorRequest{}
 
-	err = json.NewDecoder(r.Body).Decode(req)
-	err = Validate.Struct(req)
+	view, err := parseView(r, api_models.ActorResource, true)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+
+	req := &api_models.UpdateActorRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	actorID := chi.URLParam(r, "actorID")
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
 
-	intActorID, err := strconv.ParseInt(actorID, 10, 64)
+	actorID, err := idParam(r, "actorID")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 	var datetime time.Time
 	if req.Birth != "" {
-		datetime, err = time.Parse("2006-01-02", req.Birth)
+		datetime, err = time.Parse(db.DateLayout, req.Birth)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			HandleError(w, err)
+			HandleError(w, r, err)
 			return
 		}
 	}
-	actor, err := db.UpdateActor(pgdb, &db.Actor{
-		ID:    intActorID,
-		Name:  req.Name,
-		Sex:   req.Sex,
-		Birth: datetime,
+	var actor *db.Actor
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		actor, err = db.UpdateActor(tx, &db.Actor{
+			ID:    actorID,
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: datetime,
+		})
+		return err
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, notFound(err, "actor"))
 		return
 	}
 
 	res := &api_models.ActorResponse{
 		Success: true,
 		Error:   "",
-		Actor:   actor,
-	}
-
-	err = json.NewEncoder(w).Encode(res)
-	if err != nil {
-		slog.Error("error encoding actor: %v\n", err)
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		Actor:   api_models.NewActor(actor, view),
 	}
-
-	w.WriteHeader(http.StatusOK)
+	writeResponse(w, r, http.StatusOK, res)
 }
 
 // deleteActor godoc
@@ -225,44 +293,217 @@ func updateActor(w http.ResponseWriter, r *http.Request) {
 // @Description  Availible only for admin user, deleting actor using id from request params
 // @Tags         actors
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
-// @Param actorID query string true "Actors Id"
-// @Router       /actors [delete]
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param actorID path int true "Actors Id"
+// @Router       /actors/{actorID} [delete]
 // @Security BasicAuth
 // @Success 200 {object} nil
 // @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
 // @Failure 400 {object} ErrorResponse
 func deleteActor(w http.ResponseWriter, r *http.Request) {
-	auth_role, err := checkBasicAuth(r)
-	if err != nil || auth_role != db.Admin {
-		if err == nil {
-			err = errors.New("wrong access level")
-		}
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	actorID := chi.URLParam(r, "actorID")
-	intActorID, err := strconv.ParseInt(actorID, 10, 64)
+	actorID, err := idParam(r, "actorID")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
-	err = db.DeleteActor(pgdb, intActorID)
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		return db.DeleteActor(tx, actorID)
+	})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, notFound(err, "actor"))
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// getFilmography godoc
+// @Summary      Get actor filmography
+// @Description  Availible only for authenticated user, getting actor films sorted by date with roles, also grouped by year
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param actorID path int true "Actors Id"
+// @Router       /actors/{actorID}/filmography [get]
+// @Security BasicAuth
+// @Success 200 {object} api_models.FilmographyResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object} ErrorResponse
+func getFilmography(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	actorID, err := idParam(r, "actorID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	films, err := db.GetFilmography(pgdb, actorID)
+	if err != nil {
+		HandleError(w, r, notFound(err, "actor"))
+		return
+	}
+
+	res := &api_models.FilmographyResponse{
+		Success: true,
+		Error:   "",
+		Films:   films,
+		Years:   groupByYear(films),
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// getCostars godoc
+// @Summary      Get actor co-stars
+// @Description  Availible only for authenticated user, getting actors who played in the same films, ranked by number of shared films
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param actorID path int true "Actors Id"
+// @Router       /actors/{actorID}/costars [get]
+// @Security BasicAuth
+// @Success 200 {object} api_models.CostarsResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object} ErrorResponse
+func getCostars(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	actorID, err := idParam(r, "actorID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	costars, err := db.GetCostars(pgdb, actorID)
+	if err != nil {
+		HandleError(w, r, notFound(err, "actor"))
+		return
+	}
+
+	res := &api_models.CostarsResponse{
+		Success: true,
+		Error:   "",
+		Costars: costars,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// getCostarPath godoc
+// @Summary      Get co-star path between actors
+// @Description  Availible only for authenticated user, getting the shortest chain of actors linked by shared films ("six degrees")
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param from query int true "Actor Id the chain starts from"
+// @Param to query int true "Actor Id the chain ends with"
+// @Router       /actors/path [get]
+// @Security BasicAuth
+// @Success 200 {object} api_models.CostarPathResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object} ErrorResponse
+func getCostarPath(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	fromID, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param from must be an actor id"))
+		return
+	}
+	toID, err := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+	if err != nil {
+		HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param to must be an actor id"))
+		return
+	}
+
+	path, err := db.GetCostarPath(pgdb, fromID, toID)
+	if err != nil {
+		HandleError(w, r, notFound(err, "actor"))
+		return
+	}
+
+	res := &api_models.CostarPathResponse{
+		Success: true,
+		Error:   "",
+		Degrees: len(path) - 1,
+		Path:    path,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// groupByYear splits filmography sorted by date into release years.
+func groupByYear(films []*db.FilmographyEntry) []*api_models.FilmographyYear {
+	years := make([]*api_models.FilmographyYear, 0)
+	for _, film := range films {
+		year := film.Date.Year()
+		if len(years) == 0 || years[len(years)-1].Year != year {
+			years = append(years, &api_models.FilmographyYear{Year: year})
+		}
+		last := years[len(years)-1]
+		last.Films = append(last.Films, film)
+	}
+	return years
+}
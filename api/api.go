@@ -1,71 +1,151 @@
 package api
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"filmoteka/config"
 	"filmoteka/db"
+	_ "filmoteka/docs"
+	"filmoteka/errs"
+	"filmoteka/importer"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-pg/pg/v10"
-	_ "github.com/swaggo/http-swagger/example/go-chi/docs"
-
-	"github.com/go-playground/validator/v10"
 )
 
-var Validate *validator.Validate = validator.New()
+// API routes requests, its background jobs are run by Run.
+type API struct {
+	*chi.Mux
+	pgdb    *pg.DB
+	limiter *rateLimiter
+	imports *importer.Jobs
+}
 
-func StartAPI(pgdb *pg.DB, cfg *config.Config) *chi.Mux {
+func StartAPI(pgdb *pg.DB, cfg *config.Config) *API {
 	r := chi.NewRouter()
+	spec := loadSpec()
+
+	imports := importer.NewJobs(pgdb)
+	imports.MaxSize = cfg.Import.MaxSize
+
+	store := newResponseCache(cfg.Cache)
+	checks := newChecks(pgdb, store, cfg.Health)
+	limiter := newRateLimiter(cfg.RateLimit)
 
-	r.Use(middleware.Logger, middleware.RequestID, middleware.Recoverer, middleware.WithValue("DB", pgdb))
+	r.Use(middleware.Logger, middleware.RequestID, middleware.Recoverer, middleware.WithValue("DB", pgdb),
+		middleware.WithValue("Suggester", newSuggester(pgdb, cfg)), middleware.WithValue("Importer", imports),
+		middleware.WithValue("Health", checks),
+		limiter.limit, validateRequests(spec, cfg.OpenAPI), idempotency(pgdb, cfg.Idempotency))
+	// Spec is requested from the same host the UI is opened on.
 	r.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL(cfg.HTTPServer.Address+"/swagger/doc.json"),
+		httpSwagger.URL("/swagger/doc.json"),
 	))
 
-	r.Route("/films", func(r chi.Router) {
-		r.Get("/", getFilms)
-		r.Post("/", createFilm)
-		r.Put("/{filmID}", updateFilm)
-		r.Delete("/{filmID}", deleteFilm)
-	})
-	r.Route("/actors", func(r chi.Router) {
-		r.Get("/", getActors)
-		r.Post("/", createActor)
-		r.Put("/{actorID}", updateActor)
-		r.Delete("/{actorID}", deleteActor)
+	// Version routers inherit it, routes missing in v1 are problems too.
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		HandleError(w, r, errs.NotFound(errs.CodeNotFound, "route not found"))
 	})
 
-	r.Get("/healthcheck", func(w http.ResponseWriter, r *http.Request) {
-		_, ok := r.Context().Value("DB").(*pg.DB)
-		if !ok {
-			msg := "could not get the DB from context"
-			slog.Error(msg)
-			w.Write([]byte(msg))
+	// Counters of the response cache and runtime stats.
+	r.Get("/debug/vars", debugVars)
+	// Probes of orchestrators, readiness reports each dependency check.
+	r.Get("/livez", livez)
+	r.Get("/readyz", readyz)
+
+	events := streamEvents(cfg.Events)
+	graphqlServer := newGraphQLServer(cfg.GraphQL)
+	cached := cacheResponses(store, cfg.Cache)
+	for _, version := range []int{apiV1, apiV2} {
+		r.With(withVersion(version)).Route("/v"+strconv.Itoa(version), versionRoutes(version, events, graphqlServer, cached))
+	}
+	// Unprefixed routes are kept for clients of the API before versioning.
+	r.With(negotiateVersion(cfg.Versioning)).Group(versionRoutes(0, events, graphqlServer, cached))
+
+	slog.Info("Success start API routes")
+	return &API{Mux: r, pgdb: pgdb, limiter: limiter, imports: imports}
+}
+
+// Run purges expired idempotency keys and buckets of idle clients until
+// ctx is done.
+func (a *API) Run(ctx context.Context) {
+	keys := time.NewTicker(idempotencyPurgeInterval)
+	defer keys.Stop()
+	buckets := time.NewTicker(bucketPurgeInterval)
+	defer buckets.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case now := <-keys.C:
+			purgeIdempotencyKeys(a.pgdb, now)
+		case now := <-buckets.C:
+			a.limiter.purge(now)
 		}
-		w.Write([]byte("OK"))
-		w.WriteHeader(http.StatusOK)
-	})
+	}
+}
 
-	slog.Info("Success start API routes")
-	return r
+// Shutdown waits for background imports until ctx is done, then cancels
+// them. It is called when the server no longer accepts requests, the
+// database must stay open until it returns.
+func (a *API) Shutdown(ctx context.Context) error {
+	err := a.imports.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("draining imports: %w", err)
+	}
+	return nil
 }
 
+// checkBasicAuth returns the role of the user, credentials verified by
+// rate limiting are not looked up again.
 func checkBasicAuth(r *http.Request) (string, error) {
+	if role, ok := r.Context().Value("Role").(string); ok {
+		return role, nil
+	}
+	if err, ok := r.Context().Value("AuthError").(error); ok {
+		return "", err
+	}
 	user, pass, ok := r.BasicAuth()
-	var err error
 	if !ok {
-		err := errors.New("failed to get username and password")
+		return "", errs.Unauthorized("failed to get username and password")
+	}
+	pgdb, err := getDB(r)
+	if err != nil {
 		return "", err
 	}
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
 	user_role, err := db.GetUser(pgdb, user, pass)
+	if err != nil {
+		// Wrong credentials are unauthorized, failures of the database are not.
+		return "", errs.From(err)
+	}
 
-	return user_role, err
+	return user_role, nil
+}
+
+// checkAdmin allows only admin users, other authenticated users are forbidden.
+func checkAdmin(r *http.Request) error {
+	auth_role, err := checkBasicAuth(r)
+	if err != nil {
+		return err
+	}
+	if auth_role != db.Admin {
+		return errs.Forbidden("wrong access level")
+	}
+	return nil
+}
+
+func idParam(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, name), 10, 64)
+	if err != nil {
+		return 0, errs.BadRequest(errs.CodeInvalidParam, name+" must be an integer")
+	}
+	return id, nil
 }
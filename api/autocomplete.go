@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	api_models "filmoteka/api/models"
+	"filmoteka/config"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	autocompleteMemory = "memory"
+
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 50
+)
+
+// newSuggester picks autocomplete backend from config, memory backend
+// keeps names loaded from the database and does not need pg_trgm. It is
+// also used when pg_trgm indexes can not be created.
+func newSuggester(pgdb *pg.DB, cfg *config.Config) db.Suggester {
+	if cfg.Autocomplete.Backend != autocompleteMemory {
+		err := db.CreateTrigramIndexes(pgdb)
+		if err == nil {
+			return db.NewPGSuggester(pgdb)
+		}
+		slog.Error("pg_trgm is not available, falling back to memory autocomplete", "err", err)
+	}
+	return db.NewMemorySuggester(func() ([]*db.Suggestion, error) {
+		return db.LoadSuggestions(pgdb)
+	}, cfg.Autocomplete.RefreshInterval)
+}
+
+// autocomplete godoc
+// @Summary      Autocomplete names
+// @Description  Availible only for authenticated user, suggesting film and actor names starting with or similar to the query, tolerates typos
+// @Tags         search
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /autocomplete [get]
+// @Param q query string true "Beginning or misspelled name" example(Кеану Ривс)
+// @Param type query string false "Suggestion type: film or actor, default both" Enums(film, actor)
+// @Param limit query int false "Max number of suggestions, default 10, max 50"
+// @Security BasicAuth
+// @Success 200 {object} api_models.AutocompleteResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func autocomplete(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param q is required"))
+		return
+	}
+
+	suggestType := r.URL.Query().Get("type")
+	if suggestType != "" && suggestType != db.SearchFilm && suggestType != db.SearchActor {
+		HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param type must be film or actor"))
+		return
+	}
+
+	limit := defaultSuggestLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		limit, err = strconv.Atoi(param)
+		if err != nil || limit < 1 || limit > maxSuggestLimit {
+			HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param limit must be between 1 and 50"))
+			return
+		}
+	}
+
+	suggester, ok := r.Context().Value("Suggester").(db.Suggester)
+	if !ok {
+		HandleError(w, r, errs.Internal(errors.New("could not get the Suggester from context")))
+		return
+	}
+
+	suggestions, err := suggester.Suggest(r.Context(), query, suggestType, limit)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	res := &api_models.AutocompleteResponse{
+		Success:     true,
+		Error:       "",
+		Suggestions: suggestions,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// bulkApply runs one operation inside tx and fills id and item of the result.
+type bulkApply func(r *http.Request, tx orm.DB, op *api_models.BulkOperation, result *api_models.BulkResult) error
+
+// bulkFilms godoc
+// @Summary      Bulk create, update and delete films
+// @Description  Availible only for admin user, applying operations in one transaction (default) or each one separately in best_effort mode. Data of operations is the same as body of POST /films and PUT /films/{filmID}.
+// @Tags         films
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /films/bulk [post]
+// @Param Operations body api_models.BulkRequest true "operations"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request, repeated requests get the first response" example(9f4c2a1e-create-film)
+// @Security BasicAuth
+// @Success 200 {object} api_models.BulkResponse "all operations succeeded"
+// @Success 207 {object} api_models.BulkResponse "some operations failed"
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+// @Failure 422 {object}  ErrorResponse
+func bulkFilms(w http.ResponseWriter, r *http.Request) {
+	runBulk(w, r, applyFilmOperation)
+}
+
+// bulkActors godoc
+// @Summary      Bulk create, update and delete actors
+// @Description  Availible only for admin user, applying operations in one transaction (default) or each one separately in best_effort mode. Data of operations is the same as body of POST /actors and PUT /actors/{actorID}.
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /actors/bulk [post]
+// @Param Operations body api_models.BulkRequest true "operations"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request, repeated requests get the first response" example(9f4c2a1e-create-film)
+// @Security BasicAuth
+// @Success 200 {object} api_models.BulkResponse "all operations succeeded"
+// @Success 207 {object} api_models.BulkResponse "some operations failed"
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+// @Failure 422 {object}  ErrorResponse
+func bulkActors(w http.ResponseWriter, r *http.Request) {
+	runBulk(w, r, applyActorOperation)
+}
+
+func runBulk(w http.ResponseWriter, r *http.Request, apply bulkApply) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	req := &api_models.BulkRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = api_models.BulkTransaction
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	results := make([]*api_models.BulkResult, len(req.Operations))
+	for i, op := range req.Operations {
+		results[i] = &api_models.BulkResult{Index: i, Op: op.Op}
+	}
+
+	success := true
+	if req.Mode == api_models.BulkTransaction {
+		failed := -1
+		err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+			for i, op := range req.Operations {
+				err := apply(r, tx, op, results[i])
+				if err != nil {
+					failed = i
+					return err
+				}
+				results[i].Status = http.StatusOK
+			}
+			return nil
+		})
+		if err != nil {
+			success = false
+			for i, result := range results {
+				switch {
+				case i < failed:
+					setBulkError(result, errs.Conflict("rolled_back", "operation rolled back because another operation failed"))
+				case i == failed:
+					setBulkError(result, err)
+				default:
+					setBulkError(result, errs.Conflict("skipped", "operation skipped because another operation failed"))
+				}
+			}
+		}
+	} else {
+		for i, op := range req.Operations {
+			err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+				return apply(r, tx, op, results[i])
+			})
+			if err != nil {
+				success = false
+				setBulkError(results[i], err)
+				continue
+			}
+			results[i].Status = http.StatusOK
+		}
+	}
+
+	res := &api_models.BulkResponse{
+		Success: success,
+		Error:   "",
+		Mode:    req.Mode,
+		Results: results,
+	}
+	status := http.StatusOK
+	if !success {
+		status = http.StatusMultiStatus
+	}
+	writeResponse(w, r, status, res)
+}
+
+// setBulkError replaces result of the operation with the error of its kind.
+func setBulkError(result *api_models.BulkResult, err error) {
+	e := errs.From(err)
+	status, ok := statusByKind[e.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	if e.Code == "rolled_back" || e.Code == "skipped" {
+		status = http.StatusFailedDependency
+	}
+	result.Status = status
+	result.ID = 0
+	result.Film = nil
+	result.Actor = nil
+	result.Error = &api_models.BulkError{
+		Code:    e.Code,
+		Message: e.Message,
+		Errors:  e.Fields,
+	}
+}
+
+// decodeData reads and validates data of the operation like request body.
+// Data of XML bodies has no types until the operation is known.
+func decodeData(r *http.Request, data []byte, req interface{}) error {
+	if requestCodec(r) == xmlBody {
+		var value interface{}
+		err := json.Unmarshal(data, &value)
+		if err == nil {
+			data, err = json.Marshal(xmlTyped(value, reflect.TypeOf(req)))
+		}
+		if err != nil {
+			return errs.Wrap(errs.KindBadRequest, errs.CodeInvalidBody, "operation data is not valid", err)
+		}
+	}
+	err := json.NewDecoder(bytes.NewReader(data)).Decode(req)
+	if err != nil {
+		return errs.Wrap(errs.KindBadRequest, errs.CodeInvalidJSON, "operation data is not valid JSON", err)
+	}
+	return validationError(r, Validate.Struct(req))
+}
+
+func applyFilmOperation(r *http.Request, tx orm.DB, op *api_models.BulkOperation, result *api_models.BulkResult) error {
+	view := &api_models.View{Expand: api_models.Expand{api_models.ExpandActors: true}}
+
+	switch op.Op {
+	case api_models.BulkCreate:
+		req := &api_models.CreateFilmRequest{}
+		err := decodeData(r, op.Data, req)
+		if err != nil {
+			return err
+		}
+		datetime, err := time.Parse(db.DateLayout, req.Date)
+		if err != nil {
+			return err
+		}
+		film, err := db.CreateFilm(tx, &db.Film{
+			Name:        req.Name,
+			Description: req.Description,
+			Date:        datetime,
+			Rate:        req.Rate,
+		}, req.Actors, req.Roles)
+		if err != nil {
+			return err
+		}
+		result.ID = int64(film.ID)
+		result.Film = api_models.NewFilm(film, view)
+
+	case api_models.BulkUpdate:
+		req := &api_models.UpdateFilmRequest{}
+		err := decodeData(r, op.Data, req)
+		if err != nil {
+			return err
+		}
+		var datetime time.Time
+		if req.Date != "" {
+			datetime, err = time.Parse(db.DateLayout, req.Date)
+			if err != nil {
+				return err
+			}
+		}
+		film, err := db.UpdateFilm(tx, &db.Film{
+			ID:          int(op.ID),
+			Name:        req.Name,
+			Description: req.Description,
+			Date:        datetime,
+			Rate:        req.Rate,
+		})
+		if err != nil {
+			return notFound(err, "film")
+		}
+		result.ID = int64(film.ID)
+		result.Film = api_models.NewFilm(film, view)
+
+	case api_models.BulkDelete:
+		err := db.DeleteFilm(tx, op.ID)
+		if err != nil {
+			return notFound(err, "film")
+		}
+		result.ID = op.ID
+	}
+	return nil
+}
+
+func applyActorOperation(r *http.Request, tx orm.DB, op *api_models.BulkOperation, result *api_models.BulkResult) error {
+	view := &api_models.View{Expand: api_models.Expand{api_models.ExpandFilms: true}}
+
+	switch op.Op {
+	case api_models.BulkCreate:
+		req := &api_models.CreateActorRequest{}
+		err := decodeData(r, op.Data, req)
+		if err != nil {
+			return err
+		}
+		birthday, err := time.Parse(db.DateLayout, req.Birth)
+		if err != nil {
+			return err
+		}
+		actor, err := db.CreateActor(tx, &db.Actor{
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: birthday,
+		})
+		if err != nil {
+			return err
+		}
+		result.ID = actor.ID
+		result.Actor = api_models.NewActor(actor, view)
+
+	case api_models.BulkUpdate:
+		req := &api_models.UpdateActorRequest{}
+		err := decodeData(r, op.Data, req)
+		if err != nil {
+			return err
+		}
+		var datetime time.Time
+		if req.Birth != "" {
+			datetime, err = time.Parse(db.DateLayout, req.Birth)
+			if err != nil {
+				return err
+			}
+		}
+		actor, err := db.UpdateActor(tx, &db.Actor{
+			ID:    op.ID,
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: datetime,
+		})
+		if err != nil {
+			return notFound(err, "actor")
+		}
+		result.ID = actor.ID
+		result.Actor = api_models.NewActor(actor, view)
+
+	case api_models.BulkDelete:
+		err := db.DeleteActor(tx, op.ID)
+		if err != nil {
+			return notFound(err, "actor")
+		}
+		result.ID = op.ID
+	}
+	return nil
+}
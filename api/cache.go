@@ -0,0 +1,196 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"filmoteka/cache"
+	"filmoteka/config"
+	"filmoteka/db"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	cacheNone = "none"
+
+	cacheHeader = "X-Cache"
+	// cacheKeyPrefix keeps keys apart from other data of a shared store.
+	cacheKeyPrefix = "filmoteka:response:"
+)
+
+// cacheMetrics counts hits, misses, stored responses and store errors,
+// they are served to admins with other expvar variables at /debug/vars.
+var cacheMetrics = expvar.NewMap("response_cache")
+
+// debugVars serves expvar variables to admins only, they include the
+// command line and memory stats of the process.
+func debugVars(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	expvar.Handler().ServeHTTP(w, r)
+}
+
+// cachedResponse is a response kept in the cache.
+type cachedResponse struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// newResponseCache picks cache backend from config, nil disables caching.
+func newResponseCache(cfg config.Cache) cache.Cache {
+	if cfg.Backend == cacheNone {
+		return nil
+	}
+	return cache.NewLRU(cfg.Size)
+}
+
+// cacheResponses serves GET responses of the same query, role and format
+// from the store. Every film, actor and cast write bumps the catalogue
+// version in its transaction, keys include the version, so a write makes
+// earlier responses unreachable on all instances sharing the store.
+func cacheResponses(store cache.Cache, cfg config.Cache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if store == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key, ok := cacheKey(r)
+			if !ok {
+				// Handler reports the problem.
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			data, found, err := store.Get(r.Context(), key)
+			if err != nil {
+				cacheMetrics.Add("errors", 1)
+				slog.Error("can not read cached response", "err", err)
+			}
+			res := &cachedResponse{}
+			if found && json.Unmarshal(data, res) == nil {
+				cacheMetrics.Add("hits", 1)
+				setCacheControl(w, cfg)
+				w.Header().Set(cacheHeader, "HIT")
+				w.Header().Set("Content-Type", res.ContentType)
+				varyAccept(w)
+				w.WriteHeader(http.StatusOK)
+				w.Write(res.Body)
+				return
+			}
+
+			cacheMetrics.Add("misses", 1)
+			cw := &cacheWriter{ResponseWriter: w, cfg: cfg, body: limitedBuffer{limit: cfg.MaxEntrySize}}
+			next.ServeHTTP(cw, r)
+			if cw.status != http.StatusOK || cw.body.truncated {
+				return
+			}
+
+			data, err = json.Marshal(&cachedResponse{
+				ContentType: w.Header().Get("Content-Type"),
+				Body:        cw.body.Bytes(),
+			})
+			if err == nil {
+				err = store.Set(r.Context(), key, data, cfg.TTL)
+			}
+			if err != nil {
+				cacheMetrics.Add("errors", 1)
+				slog.Error("can not cache response", "err", err)
+				return
+			}
+			cacheMetrics.Add("stores", 1)
+		})
+	}
+}
+
+// cacheKey hashes the version, path, sorted query, role, format and the
+// catalogue version. Writers hold the version row until they commit, so
+// versions grow in commit order and a long write committed after a later
+// one still changes the key. It is not ok when the request can not be
+// served.
+func cacheKey(r *http.Request) (string, bool) {
+	role, err := checkBasicAuth(r)
+	if err != nil {
+		return "", false
+	}
+	c, err := negotiate(r)
+	if err != nil {
+		return "", false
+	}
+	pgdb, err := getDB(r)
+	if err != nil {
+		return "", false
+	}
+	catalogueVersion, err := db.GetCatalogueVersion(pgdb)
+	if err != nil {
+		return "", false
+	}
+
+	// Prefixed and unprefixed routes of a version share responses.
+	_, path := requestVersion(r)
+	parts := []string{
+		strconv.Itoa(apiVersion(r)),
+		path,
+		r.URL.Query().Encode(),
+		role,
+		c.MediaType(),
+		strconv.FormatInt(catalogueVersion, 10),
+	}
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return cacheKeyPrefix + hex.EncodeToString(hash[:]), true
+}
+
+// setCacheControl lets only the client keep responses as they depend on
+// its credentials.
+func setCacheControl(w http.ResponseWriter, cfg config.Cache) {
+	if cfg.MaxAge > 0 {
+		w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(cfg.MaxAge.Seconds())))
+		return
+	}
+	w.Header().Set("Cache-Control", "private, no-cache")
+}
+
+// cacheWriter copies the body of successful responses and marks them
+// cacheable before they are sent.
+type cacheWriter struct {
+	http.ResponseWriter
+	cfg    config.Cache
+	status int
+	body   limitedBuffer
+}
+
+func (w *cacheWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+		if status == http.StatusOK {
+			setCacheControl(w, w.cfg)
+			w.Header().Set(cacheHeader, "MISS")
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status == http.StatusOK {
+		w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *cacheWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
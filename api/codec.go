@@ -0,0 +1,580 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"filmoteka/errs"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Media types of request and response bodies.
+const (
+	mediaTypeJSON    = "application/json"
+	mediaTypeXML     = "application/xml"
+	mediaTypeMsgpack = "application/msgpack"
+)
+
+// codec writes and reads bodies in one format. JSON encoding of models is
+// the document, other formats are its syntaxes, so views and custom JSON
+// marshalers apply to all of them.
+type codec interface {
+	// MediaType is sent in Content-Type of responses.
+	MediaType() string
+	// ProblemType is Content-Type of problem details.
+	ProblemType() string
+	// Encode writes the JSON document in the format.
+	Encode(w io.Writer, document []byte, root string) error
+	// Decode converts the body into a JSON document of the type of v.
+	Decode(r io.Reader, v interface{}) ([]byte, error)
+}
+
+var (
+	jsonBody    codec = jsonCodec{}
+	xmlBody     codec = xmlCodec{}
+	msgpackBody codec = msgpackCodec{}
+)
+
+// codecsByMediaType also has aliases which are accepted but not sent.
+var codecsByMediaType = map[string]codec{
+	mediaTypeJSON:             jsonBody,
+	mediaTypeXML:              xmlBody,
+	"text/xml":                xmlBody,
+	mediaTypeMsgpack:          msgpackBody,
+	"application/x-msgpack":   msgpackBody,
+	"application/vnd.msgpack": msgpackBody,
+	"application/*":           jsonBody,
+	"*/*":                     jsonBody,
+}
+
+// codecFor picks a codec of the media type, structured syntax suffixes
+// like +json and +xml are understood.
+func codecFor(mediaType string) (codec, bool) {
+	if c, ok := codecsByMediaType[mediaType]; ok {
+		return c, true
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return jsonBody, true
+	case strings.HasSuffix(mediaType, "+xml"):
+		return xmlBody, true
+	case strings.HasSuffix(mediaType, "+msgpack"):
+		return msgpackBody, true
+	}
+	return nil, false
+}
+
+// negotiate picks the response codec by Accept header, JSON is the
+// default. Media types are tried by their quality, equal ones in order.
+func negotiate(r *http.Request) (codec, error) {
+	accept := strings.TrimSpace(r.Header.Get("Accept"))
+	if accept == "" {
+		return jsonBody, nil
+	}
+
+	type acceptable struct {
+		mediaType string
+		quality   float64
+	}
+	ranges := make([]acceptable, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, acceptable{mediaType, quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+	for _, accepted := range ranges {
+		if c, ok := codecFor(accepted.mediaType); ok {
+			return c, nil
+		}
+	}
+	return nil, errs.Wrap(errs.KindNotAcceptable, errs.CodeNotAcceptable, "responses are available as "+mediaTypeJSON+", "+mediaTypeXML+" or "+mediaTypeMsgpack, nil)
+}
+
+// writeResponse sets status code before the body is encoded in the format
+// the client accepts.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, v interface{}) {
+	c, err := negotiate(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	document, err := json.Marshal(v)
+	if err != nil {
+		HandleError(w, r, errs.Internal(err))
+		return
+	}
+
+	varyAccept(w)
+	w.Header().Set("Content-Type", c.MediaType())
+	w.WriteHeader(status)
+	err = c.Encode(w, document, "response")
+	if err != nil {
+		slog.Error("error encoding response", "err", err)
+	}
+}
+
+// varyAccept marks responses which depend on Accept header for caches.
+func varyAccept(w http.ResponseWriter) {
+	for _, vary := range w.Header().Values("Vary") {
+		if vary == "Accept" {
+			return
+		}
+	}
+	w.Header().Add("Vary", "Accept")
+}
+
+// decodeBody reads request body and validates it.
+func decodeBody(r *http.Request, req interface{}) error {
+	err := readBody(r, req)
+	if err != nil {
+		return err
+	}
+	return validationError(r, Validate.Struct(req))
+}
+
+// readBody decodes request body in the format of its Content-Type.
+func readBody(r *http.Request, req interface{}) error {
+	c := requestCodec(r)
+	document, err := c.Decode(r.Body, req)
+	if err != nil {
+		if c == jsonBody {
+			return errs.Wrap(errs.KindBadRequest, errs.CodeInvalidJSON, "request body is not valid JSON", err)
+		}
+		return errs.Wrap(errs.KindBadRequest, errs.CodeInvalidBody, "request body is not valid "+c.MediaType(), err)
+	}
+	err = json.Unmarshal(document, req)
+	if err != nil {
+		return errs.Wrap(errs.KindBadRequest, errs.CodeInvalidJSON, "request body is not valid JSON", err)
+	}
+	return nil
+}
+
+// requestCodec is the codec of Content-Type, bodies of other types are
+// read as JSON like before formats were negotiated.
+func requestCodec(r *http.Request) codec {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return jsonBody
+	}
+	c, ok := codecFor(mediaType)
+	if !ok {
+		return jsonBody
+	}
+	return c
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) MediaType() string   { return mediaTypeJSON }
+func (jsonCodec) ProblemType() string { return problemContentType }
+
+func (jsonCodec) Encode(w io.Writer, document []byte, _ string) error {
+	_, err := w.Write(append(document, '\n'))
+	return err
+}
+
+func (jsonCodec) Decode(r io.Reader, _ interface{}) ([]byte, error) {
+	var document json.RawMessage
+	err := json.NewDecoder(r).Decode(&document)
+	return document, err
+}
+
+// msgpackCodec keeps types of JSON values, dates stay strings.
+type msgpackCodec struct{}
+
+func (msgpackCodec) MediaType() string   { return mediaTypeMsgpack }
+func (msgpackCodec) ProblemType() string { return mediaTypeMsgpack }
+
+func (msgpackCodec) Encode(w io.Writer, document []byte, _ string) error {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return err
+	}
+	encoder := msgpack.NewEncoder(w)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	return encoder.Encode(msgpackValue(value))
+}
+
+func (msgpackCodec) Decode(r io.Reader, _ interface{}) ([]byte, error) {
+	var value interface{}
+	err := msgpack.NewDecoder(r).Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonValue(value))
+}
+
+// msgpackValue replaces JSON numbers with integers where it is possible.
+func msgpackValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case json.Number:
+		if number, err := value.Int64(); err == nil {
+			return number
+		}
+		number, _ := value.Float64()
+		return number
+	case []interface{}:
+		for i, item := range value {
+			value[i] = msgpackValue(item)
+		}
+	case map[string]interface{}:
+		for key, member := range value {
+			value[key] = msgpackValue(member)
+		}
+	}
+	return value
+}
+
+// jsonValue converts decoded msgpack values which JSON can not encode,
+// maps with non string keys and timestamps.
+func jsonValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case time.Time:
+		return value.Format(time.RFC3339Nano)
+	case []interface{}:
+		for i, item := range value {
+			value[i] = jsonValue(item)
+		}
+	case map[string]interface{}:
+		for key, member := range value {
+			value[key] = jsonValue(member)
+		}
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(value))
+		for key, member := range value {
+			object[fmtKey(key)] = jsonValue(member)
+		}
+		return object
+	}
+	return value
+}
+
+func fmtKey(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(key)
+	return strings.Trim(string(data), `"`)
+}
+
+// xmlCodec writes objects as elements named after members and arrays as
+// item elements. Members which are not XML names, like map keys, are entry
+// elements with key attribute. XML has no types, so request bodies are
+// read with types of the request struct.
+type xmlCodec struct{}
+
+// xmlItem and xmlEntry are elements of arrays and of members with names
+// XML does not allow.
+const (
+	xmlItem  = "item"
+	xmlEntry = "entry"
+)
+
+func (xmlCodec) MediaType() string   { return mediaTypeXML }
+func (xmlCodec) ProblemType() string { return "application/problem+xml" }
+
+func (xmlCodec) Encode(w io.Writer, document []byte, root string) error {
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	start := xml.StartElement{Name: xml.Name{Local: root}}
+	if root == "problem" {
+		// RFC 7807 namespace of problem details.
+		start.Name.Space = "urn:ietf:rfc:7807"
+	}
+	err = encodeXML(encoder, decoder, start)
+	if err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// encodeXML writes the next JSON value of decoder as the element.
+func encodeXML(encoder *xml.Encoder, decoder *json.Decoder, start xml.StartElement) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	err = encoder.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+
+	switch token := token.(type) {
+	case json.Delim:
+		for decoder.More() {
+			child := xml.StartElement{Name: xml.Name{Local: xmlItem}}
+			if token == '{' {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				child = memberElement(key.(string))
+			}
+			err = encodeXML(encoder, decoder, child)
+			if err != nil {
+				return err
+			}
+		}
+		// Closing delimiter.
+		_, err = decoder.Token()
+		if err != nil {
+			return err
+		}
+	case nil:
+	default:
+		err = encoder.EncodeToken(xml.CharData(jsonScalar(token)))
+		if err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
+
+func memberElement(name string) xml.StartElement {
+	if isXMLName(name) {
+		return xml.StartElement{Name: xml.Name{Local: name}}
+	}
+	return xml.StartElement{
+		Name: xml.Name{Local: xmlEntry},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: name}},
+	}
+}
+
+func jsonScalar(token json.Token) string {
+	switch token := token.(type) {
+	case string:
+		return token
+	case json.Number:
+		return token.String()
+	case bool:
+		return strconv.FormatBool(token)
+	}
+	return ""
+}
+
+// isXMLName is a conservative check, names must start with a letter or _
+// and have letters, digits, _, - and . only. Names starting with xml are
+// reserved.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, char := range name {
+		letter := char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+		if i == 0 && !letter {
+			return false
+		}
+		if !letter && char != '-' && char != '.' && (char < '0' || char > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// xmlNode is a parsed element, text of elements with children is ignored.
+type xmlNode struct {
+	name     string
+	key      string
+	text     string
+	children []*xmlNode
+}
+
+func (xmlCodec) Decode(r io.Reader, v interface{}) ([]byte, error) {
+	decoder := xml.NewDecoder(r)
+	var root *xmlNode
+	stack := make([]*xmlNode, 0)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: token.Name.Local}
+			for _, attr := range token.Attr {
+				if attr.Name.Local == "key" {
+					node.key = attr.Value
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			} else if root == nil {
+				root = node
+			} else {
+				return nil, errors.New("document has many root elements")
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(token)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("document has no root element")
+	}
+	return json.Marshal(xmlTyped(root.untyped(), reflect.TypeOf(v)))
+}
+
+// untyped guesses the value: elements of items are arrays, elements with
+// children are objects, others are strings.
+func (n *xmlNode) untyped() interface{} {
+	if len(n.children) == 0 {
+		return n.text
+	}
+	if n.children[0].name == xmlItem {
+		items := make([]interface{}, 0, len(n.children))
+		for _, child := range n.children {
+			items = append(items, child.untyped())
+		}
+		return items
+	}
+	object := make(map[string]interface{}, len(n.children))
+	for _, child := range n.children {
+		object[child.memberName()] = child.untyped()
+	}
+	return object
+}
+
+func (n *xmlNode) memberName() string {
+	if n.name == xmlEntry && n.key != "" {
+		return n.key
+	}
+	return n.name
+}
+
+var (
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// xmlTyped converts strings of the untyped value into JSON values of the
+// type. Values which do not match it are kept and fail when the document
+// is decoded.
+func xmlTyped(value interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t == rawMessageType || t == timeType || t.Kind() == reflect.Interface {
+		return value
+	}
+	text, isText := value.(string)
+
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			if isText && strings.TrimSpace(text) == "" {
+				return map[string]interface{}{}
+			}
+			return value
+		}
+		var fields map[string]reflect.Type
+		if t.Kind() == reflect.Struct {
+			fields = jsonFields(t)
+		}
+		for name, member := range object {
+			if fields == nil {
+				object[name] = xmlTyped(member, t.Elem())
+			} else if field, ok := fields[name]; ok {
+				object[name] = xmlTyped(member, field)
+			}
+		}
+		return object
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return value
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			if isText && strings.TrimSpace(text) == "" {
+				return []interface{}{}
+			}
+			return value
+		}
+		for i, item := range items {
+			items[i] = xmlTyped(item, t.Elem())
+		}
+		return items
+	case reflect.Bool:
+		if flag, err := strconv.ParseBool(strings.TrimSpace(text)); isText && err == nil {
+			return flag
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		number := json.Number(strings.TrimSpace(text))
+		if _, err := number.Float64(); isText && err == nil {
+			return number
+		}
+	}
+	return value
+}
+
+// jsonFields maps JSON names of struct fields to their types, fields of
+// embedded structs are promoted.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		name := jsonFieldName(field)
+		if name == "" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && field.Tag.Get("json") == "" && fieldType.Kind() == reflect.Struct {
+			for name, promoted := range jsonFields(fieldType) {
+				fields[name] = promoted
+			}
+			continue
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -2,22 +2,101 @@ package api
 
 import (
 	"encoding/json"
-
+	"errors"
+	"filmoteka/errs"
 	"log/slog"
 	"net/http"
+
+	"github.com/go-pg/pg/v10"
 )
 
+const problemContentType = "application/problem+json"
+
+var statusByKind = map[errs.Kind]int{
+	errs.KindBadRequest:      http.StatusBadRequest,
+	errs.KindValidation:      http.StatusBadRequest,
+	errs.KindUnauthorized:    http.StatusUnauthorized,
+	errs.KindForbidden:       http.StatusForbidden,
+	errs.KindNotFound:        http.StatusNotFound,
+	errs.KindConflict:        http.StatusConflict,
+	errs.KindTooLarge:        http.StatusRequestEntityTooLarge,
+	errs.KindNotAcceptable:   http.StatusNotAcceptable,
+	errs.KindUnprocessable:   http.StatusUnprocessableEntity,
+	errs.KindTooManyRequests: http.StatusTooManyRequests,
+	errs.KindInternal:        http.StatusInternalServerError,
+}
+
+// ErrorResponse is a RFC 7807 problem details body, success and error
+// members are kept for clients of the previous error format.
 type ErrorResponse struct {
-	Success bool   `json:"success" example:"false"`
-	Error   string `json:"error" example:"Error msg"`
+	Type     string             `json:"type" example:"/problems/film_not_found"`
+	Title    string             `json:"title" example:"Not Found"`
+	Status   int                `json:"status" example:"404"`
+	Detail   string             `json:"detail,omitempty" example:"film not found"`
+	Instance string             `json:"instance,omitempty" example:"/films/45"`
+	Code     string             `json:"code" example:"film_not_found"`
+	Errors   []*errs.FieldError `json:"errors,omitempty"`
+	Success  bool               `json:"success" example:"false"`
+	Error    string             `json:"error" example:"film not found"`
 }
 
-func HandleError(w http.ResponseWriter, err error) {
+// HandleError writes err as problem details with status code of its kind,
+// causes of internal errors are logged and never sent to the client.
+func HandleError(w http.ResponseWriter, r *http.Request, err error) {
+	e := errs.From(err)
+	status, ok := statusByKind[e.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	if status >= http.StatusInternalServerError {
+		slog.Error("request failed", "err", err, "path", r.URL.Path)
+	} else {
+		slog.Debug("request rejected", "err", err, "path", r.URL.Path)
+	}
+
 	res := &ErrorResponse{
-		Success: false,
-		Error:   err.Error(),
+		Type:     "/problems/" + e.Code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Message,
+		Instance: r.URL.Path,
+		Code:     e.Code,
+		Errors:   e.Fields,
+		Success:  false,
+		Error:    e.Message,
 	}
-	slog.Error("error %s\n", err)
+	document, err := json.Marshal(res)
+	if err != nil {
+		slog.Error("error encoding problem details", "err", err)
+		return
+	}
+	// Problems are sent even when the format is not acceptable.
+	c, err := negotiate(r)
+	if err != nil {
+		c = jsonBody
+	}
+	varyAccept(w)
+	w.Header().Set("Content-Type", c.ProblemType())
+	w.WriteHeader(status)
+	err = c.Encode(w, document, "problem")
+	if err != nil {
+		slog.Error("error encoding problem details", "err", err)
+	}
+}
 
-	json.NewEncoder(w).Encode(res)
+func getDB(r *http.Request) (*pg.DB, error) {
+	pgdb, ok := r.Context().Value("DB").(*pg.DB)
+	if !ok {
+		return nil, errs.Internal(errors.New("could not get the DB from context"))
+	}
+	return pgdb, nil
+}
+
+// notFound replaces missing rows error with the resource specific one.
+func notFound(err error, resource string) error {
+	if errors.Is(err, pg.ErrNoRows) {
+		return errs.Wrap(errs.KindNotFound, resource+"_not_found", resource+" not found", err)
+	}
+	return err
 }
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"filmoteka/config"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retryDelay is how long browsers wait before reconnecting a stream.
+const retryDelay = 3 * time.Second
+
+// streamEvents godoc
+// @Summary      Stream of catalogue changes
+// @Description  Availible only for authenticated user, streaming film, actor and cast events as Server-Sent Events. Each message has the event id, the event type (film.created, actor.deleted, cast.changed...) and the event as data. Reconnecting with Last-Event-ID header or last_event_id param resumes after that event, otherwise only new events are sent.
+// @Tags         events
+// @Produce      text/event-stream
+// @Router       /events [get]
+// @Param entity query string false "Comma separated entities to stream, film, actor or cast, all by default" example(film,cast)
+// @Param Last-Event-ID header int false "Id of the last received event"
+// @Param last_event_id query int false "Id of the last received event, for clients which can not set headers"
+// @Security BasicAuth
+// @Success 200 {object} db.Event
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func streamEvents(cfg config.Events) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, err := checkBasicAuth(r)
+		if err != nil {
+			HandleError(w, r, err)
+			return
+		}
+
+		entities, err := eventEntities(r)
+		if err != nil {
+			HandleError(w, r, err)
+			return
+		}
+
+		pgdb, err := getDB(r)
+		if err != nil {
+			HandleError(w, r, err)
+			return
+		}
+
+		lastID, err := lastEventID(r)
+		if err != nil {
+			HandleError(w, r, err)
+			return
+		}
+		if lastID < 0 {
+			lastID, err = db.LastEventID(pgdb)
+			if err != nil {
+				HandleError(w, r, err)
+				return
+			}
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			HandleError(w, r, errs.Internal(errors.New("response writer does not support streaming")))
+			return
+		}
+
+		liftWriteDeadline(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		// Nginx buffers responses unless it is told not to.
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", retryDelay.Milliseconds())
+		flusher.Flush()
+
+		poll := time.NewTicker(cfg.PollInterval)
+		defer poll.Stop()
+		heartbeat := time.NewTicker(cfg.Heartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			events, err := db.GetEvents(pgdb, lastID, entities, cfg.BatchSize)
+			if err != nil {
+				// Headers are sent, the client reconnects from the last id.
+				return
+			}
+			for _, event := range events {
+				err = writeEvent(w, event)
+				if err != nil {
+					return
+				}
+				lastID = event.ID
+			}
+			if len(events) > 0 {
+				flusher.Flush()
+				heartbeat.Reset(cfg.Heartbeat)
+			}
+			if len(events) == cfg.BatchSize {
+				continue
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-shutdown(r):
+				return
+			case <-heartbeat.C:
+				_, err = fmt.Fprint(w, ": ping\n\n")
+				if err != nil {
+					return
+				}
+				flusher.Flush()
+			case <-poll.C:
+			}
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *db.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// eventEntities reads the entity filter, nil means all entities.
+func eventEntities(r *http.Request) ([]string, error) {
+	param := r.URL.Query().Get("entity")
+	if param == "" {
+		return nil, nil
+	}
+	entities := strings.Split(param, ",")
+	for _, entity := range entities {
+		if !slices.Contains(db.Entities, entity) {
+			return nil, errs.BadRequest(errs.CodeInvalidParam, "query param entity must be any of "+strings.Join(db.Entities, ", "))
+		}
+	}
+	return entities, nil
+}
+
+// lastEventID is the id to resume after, negative when it is not given.
+func lastEventID(r *http.Request) (int64, error) {
+	param := r.Header.Get("Last-Event-ID")
+	if param == "" {
+		param = r.URL.Query().Get("last_event_id")
+	}
+	if param == "" {
+		return -1, nil
+	}
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || id < 0 {
+		return 0, errs.BadRequest(errs.CodeInvalidParam, "last event id must be a non negative integer")
+	}
+	return id, nil
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"bufio"
+	"filmoteka/errs"
+	"filmoteka/exporter"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// exportBufferSize is how much of the export is kept before the response
+// is committed, errors of the query itself are reported as problems.
+const exportBufferSize = 32 << 10
+
+// exportFilms godoc
+// @Summary      Export films
+// @Description  Availible only for authenticated user, streaming all films as a file, sorted and filtered like films list. Actors column holds names of the cast separated by ";", the file can be imported back.
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Router       /export/films [get]
+// @Param format query string false "File format, default csv" Enums(csv, ndjson, xlsx)
+// @Param sortBy query string false "Sort by field, default rate" Enums(rate, name, date) example(name)
+// @Param filter query string false "Filter by field (field.value), field is name or description" format(film-filter) example(name.Name1)
+// @Security BasicAuth
+// @Success 200 {file} file
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func exportFilms(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	sortBy, filter := filmsOrder(r)
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	streamExport(w, r, "films", format, func(out io.Writer) error {
+		return exporter.Films(pgdb.WithContext(r.Context()), out, format, sortBy, filter)
+	})
+}
+
+// exportActors godoc
+// @Summary      Export actors
+// @Description  Availible only for authenticated user, streaming all actors as a file. Films column holds names of actor films separated by ";".
+// @Tags         export
+// @Produce      text/csv
+// @Produce      application/x-ndjson
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Router       /export/actors [get]
+// @Param format query string false "File format, default csv" Enums(csv, ndjson, xlsx)
+// @Security BasicAuth
+// @Success 200 {file} file
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func exportActors(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	format, err := exportFormat(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	streamExport(w, r, "actors", format, func(out io.Writer) error {
+		return exporter.Actors(pgdb.WithContext(r.Context()), out, format)
+	})
+}
+
+func exportFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exporter.FormatCSV
+	}
+	if exporter.ContentType(format) == "" {
+		return "", errs.BadRequest(errs.CodeInvalidParam, "format must be one of csv, ndjson, xlsx")
+	}
+	return format, nil
+}
+
+// streamExport writes the export as an attachment. Once part of it is sent
+// a failure can only break the connection, so client does not take a cut
+// file for a complete one.
+func streamExport(w http.ResponseWriter, r *http.Request, name string, format string, export func(io.Writer) error) {
+	liftWriteDeadline(w)
+	out := &committedWriter{w: w}
+	buf := bufio.NewWriterSize(out, exportBufferSize)
+
+	w.Header().Set("Content-Type", exporter.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+"."+format+`"`)
+
+	err := export(buf)
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err == nil {
+		return
+	}
+
+	if !out.committed {
+		w.Header().Del("Content-Disposition")
+		HandleError(w, r, err)
+		return
+	}
+	slog.Error("export failed", "err", err, "path", r.URL.Path)
+	panic(http.ErrAbortHandler)
+}
+
+type committedWriter struct {
+	w         http.ResponseWriter
+	committed bool
+}
+
+func (c *committedWriter) Write(p []byte) (int, error) {
+	c.committed = true
+	return c.w.Write(p)
+}
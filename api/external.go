@@ -0,0 +1,386 @@
+package api
+
+import (
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// getFilmByExternal godoc
+// @Summary      Get film by external id
+// @Description  Availible only for authenticated user, getting film by its id in IMDb, Kinopoisk or TMDB
+// @Tags         films
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /films/by-external/{source}/{id} [get]
+// @Param source path string true "External catalogue" Enums(imdb, kinopoisk, tmdb)
+// @Param id path string true "Film id in the catalogue" example(tt0133093)
+// @Param include query string false "Included relations, actors" example(actors)
+// @Param fields[films] query string false "Comma separated fields of film to return" example(id,name,external_ids)
+// @Param fields[actors] query string false "Comma separated fields of included actors" example(id,name)
+// @Security BasicAuth
+// @Success 200 {object} api_models.FilmResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func getFilmByExternal(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	view, err := parseView(r, api_models.FilmResource, true)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	source, id, err := externalParams(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	film, err := db.GetFilmByExternalID(pgdb, source, id, view.Selection(api_models.FilmResource))
+	if err != nil {
+		HandleError(w, r, notFound(err, "film"))
+		return
+	}
+
+	res := &api_models.FilmResponse{
+		Success: true,
+		Error:   "",
+		Film:    api_models.NewFilm(film, view),
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// upsertFilmByExternal godoc
+// @Summary      Create or replace film by external id
+// @Description  Availible only for admin user, creating film with the external id or replacing all fields of the existing one. Cast is reconciled with the given one, actors are referenced by our or external id, omitted cast is left as is. Safe to repeat.
+// @Tags         films
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /films/by-external/{source}/{id} [put]
+// @Param source path string true "External catalogue" Enums(imdb, kinopoisk, tmdb)
+// @Param id path string true "Film id in the catalogue" example(tt0133093)
+// @Param include query string false "Included relations, actors" example(actors)
+// @Param fields[films] query string false "Comma separated fields of film to return" example(id,name,external_ids)
+// @Param Film body api_models.UpsertFilmRequest true "film info"
+// @Security BasicAuth
+// @Success 200 {object} api_models.FilmResponse "film is updated"
+// @Success 201 {object} api_models.FilmResponse "film is created"
+// @Header 201 {string} Location "URL of the film"
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 409 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func upsertFilmByExternal(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	view, err := parseView(r, api_models.FilmResource, true)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	req := &api_models.UpsertFilmRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	source, id, err := externalParams(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	err = checkExternalIDs(req.ExternalIDs, source, id)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	datetime, err := time.Parse(db.DateLayout, req.Date)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	var (
+		film    *db.Film
+		created bool
+	)
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		cast, err := resolveCast(tx, req.Cast)
+		if err != nil {
+			return err
+		}
+		film, created, err = db.UpsertFilmByExternalID(tx, source, id, &db.Film{
+			Name:        req.Name,
+			Description: req.Description,
+			Date:        datetime,
+			Rate:        req.Rate,
+		}, cast, req.ExternalIDs)
+		return err
+	})
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	res := &api_models.FilmResponse{
+		Success: true,
+		Error:   "",
+		Film:    api_models.NewFilm(film, view),
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		w.Header().Set("Location", "/films/"+strconv.Itoa(film.ID))
+	}
+	writeResponse(w, r, status, res)
+}
+
+// getActorByExternal godoc
+// @Summary      Get actor by external id
+// @Description  Availible only for authenticated user, getting actor by its id in IMDb, Kinopoisk or TMDB
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /actors/by-external/{source}/{id} [get]
+// @Param source path string true "External catalogue" Enums(imdb, kinopoisk, tmdb)
+// @Param id path string true "Actor id in the catalogue" example(nm0000206)
+// @Param include query string false "Included relations, films" example(films)
+// @Param fields[actors] query string false "Comma separated fields of actor to return" example(id,name,external_ids)
+// @Param fields[films] query string false "Comma separated fields of included films" example(id,name)
+// @Security BasicAuth
+// @Success 200 {object} api_models.ActorResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func getActorByExternal(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	view, err := parseView(r, api_models.ActorResource, true)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	source, id, err := externalParams(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	actor, err := db.GetActorByExternalID(pgdb, source, id, view.Selection(api_models.ActorResource))
+	if err != nil {
+		HandleError(w, r, notFound(err, "actor"))
+		return
+	}
+
+	res := &api_models.ActorResponse{
+		Success: true,
+		Error:   "",
+		Actor:   api_models.NewActor(actor, view),
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// upsertActorByExternal godoc
+// @Summary      Create or replace actor by external id
+// @Description  Availible only for admin user, creating actor with the external id or replacing all fields of the existing one. Safe to repeat.
+// @Tags         actors
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /actors/by-external/{source}/{id} [put]
+// @Param source path string true "External catalogue" Enums(imdb, kinopoisk, tmdb)
+// @Param id path string true "Actor id in the catalogue" example(nm0000206)
+// @Param include query string false "Included relations, films" example(films)
+// @Param fields[actors] query string false "Comma separated fields of actor to return" example(id,name,external_ids)
+// @Param Actor body api_models.UpsertActorRequest true "actor info"
+// @Security BasicAuth
+// @Success 200 {object} api_models.ActorResponse "actor is updated"
+// @Success 201 {object} api_models.ActorResponse "actor is created"
+// @Header 201 {string} Location "URL of the actor"
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 409 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func upsertActorByExternal(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	view, err := parseView(r, api_models.ActorResource, true)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	req := &api_models.UpsertActorRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	source, id, err := externalParams(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	err = checkExternalIDs(req.ExternalIDs, source, id)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	birthday, err := time.Parse(db.DateLayout, req.Birth)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	var (
+		actor   *db.Actor
+		created bool
+	)
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		actor, created, err = db.UpsertActorByExternalID(tx, source, id, &db.Actor{
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: birthday,
+		}, req.ExternalIDs)
+		return err
+	})
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	res := &api_models.ActorResponse{
+		Success: true,
+		Error:   "",
+		Actor:   api_models.NewActor(actor, view),
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+		w.Header().Set("Location", "/actors/"+strconv.FormatInt(actor.ID, 10))
+	}
+	writeResponse(w, r, status, res)
+}
+
+// externalParams reads source and id of the external catalogue from path.
+func externalParams(r *http.Request) (string, string, error) {
+	source := chi.URLParam(r, "source")
+	if !db.IsExternalSource(source) {
+		return "", "", errs.BadRequest(errs.CodeInvalidParam, fmt.Sprintf("unknown source %q", source))
+	}
+	return source, chi.URLParam(r, "id"), nil
+}
+
+// checkExternalIDs rejects body ids contradicting the one in path.
+func checkExternalIDs(ids map[string]string, source string, id string) error {
+	if value, ok := ids[source]; ok && value != id {
+		return errs.BadRequest(errs.CodeInvalidParam, fmt.Sprintf("external_ids.%s differs from %s in path", source, id))
+	}
+	return nil
+}
+
+// resolveCast turns cast references into actor ids, nil cast stays nil.
+func resolveCast(tx orm.DB, cast []*api_models.CastMember) ([]*db.CastMember, error) {
+	if cast == nil {
+		return nil, nil
+	}
+
+	res := make([]*db.CastMember, 0, len(cast))
+	for _, member := range cast {
+		actorID := member.ActorID
+		if member.External != nil {
+			actor, err := db.FindActorByExternalID(tx, member.External.Source, member.External.ID)
+			if err == pg.ErrNoRows {
+				return nil, errs.Conflict(errs.CodeReferenceNotFound, fmt.Sprintf("actor %s:%s not found", member.External.Source, member.External.ID))
+			}
+			if err != nil {
+				return nil, err
+			}
+			if actorID != 0 && actorID != actor.ID {
+				return nil, errs.BadRequest(errs.CodeInvalidParam, fmt.Sprintf("actor %s:%s is not #%d", member.External.Source, member.External.ID, actorID))
+			}
+			actorID = actor.ID
+		} else {
+			ok, err := db.ActorExists(tx, actorID)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, errs.Conflict(errs.CodeReferenceNotFound, fmt.Sprintf("actor #%d not found", actorID))
+			}
+		}
+		res = append(res, &db.CastMember{ActorID: actorID, Role: member.Role})
+	}
+	return res, nil
+}
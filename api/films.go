@@ -1,17 +1,12 @@
 package api
 
 import (
-	"encoding/json"
-	"errors"
 	api_models "filmoteka/api/models"
 	"filmoteka/db"
-	"log/slog"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/go-pg/pg/v10"
 )
 
@@ -20,22 +15,72 @@ import (
 // @Description  Availible only for authenticated user, getting films list, they can be sorted by fields, default is rate. Also you can use filters in field.value template.
 // @Tags         films
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
 // @Router       /films [get]
-// @Param sortBy query string false "Sort by field, default rate" example(name)
-// @Param filter query string false "Filter by field (field.value), can be user all except actors" example(name.Name1)
+// @Param include query string false "Included relations, actors, also accepted as expand" example(actors)
+// @Param fields[films] query string false "Comma separated fields of films to return, also accepted as fields" example(id,name,rate)
+// @Param fields[actors] query string false "Comma separated fields of included actors" example(id,name)
+// @Param sortBy query string false "Sort by field, default rate" Enums(rate, name, date) example(name)
+// @Param filter query string false "Filter by field (field.value), field is name or description" format(film-filter) example(name.Name1)
+// @Param limit query int false "Page size from 1 to 1000, all films when omitted" minimum(1) maximum(1000)
+// @Param offset query int false "Number of films to skip, used with limit" minimum(0)
 // @Security BasicAuth
-// @Success 200 {object} api_models.FilmsResponse
+// @Success 200 {object} api_models.FilmsResponse "v1 lists films under film key"
 // @Failure 401 {object}  ErrorResponse
 // @Failure 400 {object}  ErrorResponse
 func getFilms(w http.ResponseWriter, r *http.Request) {
 	_, err := checkBasicAuth(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
+	sortBy, splits := filmsOrder(r)
+
+	view, err := parseView(r, api_models.FilmResource, false)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	sel := view.Selection(api_models.FilmResource)
+	err = parsePage(r, sel)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	films, err := db.GetFilms(pgdb, sortBy, splits, sel)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	list := api_models.NewFilms(films, view)
+	if apiVersion(r) == apiV1 {
+		writeResponse(w, r, http.StatusOK, &api_models.FilmsResponseV1{Success: true, Films: list})
+		return
+	}
+	res := &api_models.FilmsResponse{
+		Success: true,
+		Error:   "",
+		Films:   list,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// filmsOrder reads sortBy and filter params shared by films list and export.
+func filmsOrder(r *http.Request) (string, []string) {
 	sortBy := r.URL.Query().Get("sortBy")
 	filter := r.URL.Query().Get("filter")
 	var splits []string
@@ -45,34 +90,66 @@ func getFilms(w http.ResponseWriter, r *http.Request) {
 	if sortBy == "" || sortBy == "rate" {
 		sortBy = "rate DESC"
 	}
+	return sortBy, splits
+}
 
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+// getFilm godoc
+// @Summary      Get film
+// @Description  Availible only for authenticated user, getting film by id with its actors
+// @Tags         films
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /films/{filmID} [get]
+// @Param filmID path int true "Film Id"
+// @Param include query string false "Included relations, actors" example(actors)
+// @Param fields[films] query string false "Comma separated fields of film to return" example(id,name,rate)
+// @Param fields[actors] query string false "Comma separated fields of included actors" example(id,name)
+// @Security BasicAuth
+// @Success 200 {object} api_models.FilmResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func getFilm(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	films, err := db.GetFilms(pgdb, sortBy, splits)
+	view, err := parseView(r, api_models.FilmResource, true)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
-	res := &api_models.FilmsResponse{
-		Success: true,
-		Error:   "",
-		Films:   films,
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
 	}
 
-	err = json.NewEncoder(w).Encode(res)
+	filmID, err := idParam(r, "filmID")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+
+	film, err := db.GetFilm(pgdb, int(filmID), view.Selection(api_models.FilmResource))
+	if err != nil {
+		HandleError(w, r, notFound(err, "film"))
+		return
+	}
+
+	res := &api_models.FilmResponse{
+		Success: true,
+		Error:   "",
+		Film:    api_models.NewFilm(film, view),
+	}
+	writeResponse(w, r, http.StatusOK, res)
 }
 
 // createFilm godoc
@@ -80,170 +157,160 @@ func getFilms(w http.ResponseWriter, r *http.Request) {
 // @Description  Availible only for admin user, creating film using data from request body and return new film
 // @Tags         films
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
 // @Router       /films [post]
-// @Param Film body db.Film true "film info"
-// @Param filmID query string true "Film Id"
+// @Param expand query string false "Nested relations, actors" example(actors)
+// @Param fields query string false "Comma separated fields of films to return" example(id,name)
+// @Param Film body api_models.CreateFilmRequest true "film info, roles are keyed by actor ids"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request, repeated requests get the first response" example(9f4c2a1e-create-film)
 // @Security BasicAuth
 // @Success 200 {object} api_models.FilmResponse
 // @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 409 {object}  ErrorResponse
 // @Failure 400 {object}  ErrorResponse
+// @Failure 422 {object}  ErrorResponse
 func createFilm(w http.ResponseWriter, r *http.Request) {
-	auth_role, err := checkBasicAuth(r)
-	if err != nil || auth_role != db.Admin {
-		if err == nil {
-			err = errors.New("wrong access level")
-		}
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	req := &api_models.CreateFilmRequest{}
-	err = json.NewDecoder(r.Body).Decode(req)
+	view, err := parseView(r, api_models.FilmResource, true)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	err = Validate.Struct(req)
 
+	req := &api_models.CreateFilmRequest{}
+	err = decodeBody(r, req)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
-
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	datetime, err := time.Parse("2006-01-02", req.Date)
+	datetime, err := time.Parse(db.DateLayout, req.Date)
 	if err != nil {
-		slog.Debug(req.Date)
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	film, err := db.CreateFilm(pgdb, &db.Film{
-		Name:        req.Name,
-		Description: req.Description,
-		Date:        datetime,
-		Rate:        req.Rate,
-	}, req.Actors)
+	var film *db.Film
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		film, err = db.CreateFilm(tx, &db.Film{
+			Name:        req.Name,
+			Description: req.Description,
+			Date:        datetime,
+			Rate:        req.Rate,
+		}, req.Actors, req.Roles)
+		return err
+	})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
 	res := &api_models.FilmResponse{
 		Success: true,
 		Error:   "",
-		Film:    film,
-	}
-	err = json.NewEncoder(w).Encode(res)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
-		return
+		Film:    api_models.NewFilm(film, view),
 	}
-	w.WriteHeader(http.StatusOK)
+	writeResponse(w, r, http.StatusOK, res)
 }
 
 // updateFilm godoc
 // @Summary      Update film
-// @Description  Availible only for admin user, updating film using data from request body and return new film
+// @Description  Availible only for admin user, updating film using data from request body and return new film. All fields are written, omitted ones are reset, actors are left as is.
 // @Tags         films
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
-// @Router       /films [put]
-// @Param Film body db.Film true "film info"
-// @Param filmID query string true "Film Id"
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /films/{filmID} [put]
+// @Param filmID path int true "Film Id"
+// @Param expand query string false "Nested relations, actors" example(actors)
+// @Param fields query string false "Comma separated fields of films to return" example(id,name)
+// @Param Film body api_models.UpdateFilmRequest true "film info"
 // @Security BasicAuth
 // @Success 200 {object} api_models.FilmResponse
 // @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
 // @Failure 400 {object}  ErrorResponse
 func updateFilm(w http.ResponseWriter, r *http.Request) {
-	auth_role, err := checkBasicAuth(r)
-	if err != nil || auth_role != db.Admin {
-		if err == nil {
-			err = errors.New("wrong access level")
-		}
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	req := &api_models.UpdateFilmRequest{}
-	err = json.NewDecoder(r.Body).Decode(req)
+	view, err := parseView(r, api_models.FilmResource, true)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	err = Validate.Struct(req)
+
+	req := &api_models.UpdateFilmRequest{}
+	err = decodeBody(r, req)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	filmID := chi.URLParam(r, "filmID")
-
-	intFilmID, err := strconv.Atoi(filmID)
+	filmID, err := idParam(r, "filmID")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 	var datetime time.Time
 	if req.Date != "" {
-		datetime, err = time.Parse("2006-01-02", req.Date)
+		datetime, err = time.Parse(db.DateLayout, req.Date)
 		if err != nil {
-			slog.Debug(req.Date)
-			w.WriteHeader(http.StatusBadRequest)
-			HandleError(w, err)
+			HandleError(w, r, err)
 			return
 		}
 	}
 
-	film, err := db.UpdateFilm(pgdb, &db.Film{
-		ID:          intFilmID,
-		Name:        req.Name,
-		Description: req.Description,
-		Date:        datetime,
-		Rate:        req.Rate,
+	var film *db.Film
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		film, err = db.UpdateFilm(tx, &db.Film{
+			ID:          int(filmID),
+			Name:        req.Name,
+			Description: req.Description,
+			Date:        datetime,
+			Rate:        req.Rate,
+		})
+		return err
 	})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, notFound(err, "film"))
 		return
 	}
 
 	res := &api_models.FilmResponse{
 		Success: true,
 		Error:   "",
-		Film:    film,
-	}
-
-	err = json.NewEncoder(w).Encode(res)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return
+		Film:    api_models.NewFilm(film, view),
 	}
-
-	w.WriteHeader(http.StatusOK)
+	writeResponse(w, r, http.StatusOK, res)
 }
 
 // deleteFilm godoc
@@ -251,42 +318,43 @@ func updateFilm(w http.ResponseWriter, r *http.Request) {
 // @Description  Availible only for admin user, deleting film by id from params
 // @Tags         films
 // @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
 // @Produce      json
-// @Param filmID query string true "Film Id"
-// @Router       /films [delete]
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Param filmID path int true "Film Id"
+// @Router       /films/{filmID} [delete]
 // @Security BasicAuth
 // @Success 200 {object} nil
 // @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
 // @Failure 400 {object}  ErrorResponse
 func deleteFilm(w http.ResponseWriter, r *http.Request) {
-	auth_role, err := checkBasicAuth(r)
-	if err != nil || auth_role != db.Admin {
-		if err == nil {
-			err = errors.New("wrong access level")
-		}
-		w.WriteHeader(http.StatusUnauthorized)
-		HandleError(w, err)
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
-	pgdb, ok := r.Context().Value("DB").(*pg.DB)
-	if !ok {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
 		return
 	}
 
-	filmID := chi.URLParam(r, "filmID")
-	intFilmID, err := strconv.ParseInt(filmID, 10, 64)
+	filmID, err := idParam(r, "filmID")
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, err)
 		return
 	}
 
-	err = db.DeleteFilm(pgdb, intFilmID)
+	err = pgdb.RunInTransaction(r.Context(), func(tx *pg.Tx) error {
+		return db.DeleteFilm(tx, filmID)
+	})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		HandleError(w, err)
+		HandleError(w, r, notFound(err, "film"))
 		return
 	}
 
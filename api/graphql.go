@@ -0,0 +1,341 @@
+package api
+
+import (
+	"encoding/json"
+	"filmoteka/config"
+	"filmoteka/errs"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// GraphQLRequest is a body of POST /graphql, GET takes the same params.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" example:"{ film(id: 1) { name cast { role actor { name } } } }"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is a result of the operation, data is null when the
+// request can not be executed at all.
+type GraphQLResponse struct {
+	Data   interface{}                `json:"data,omitempty"`
+	Errors []gqlerrors.FormattedError `json:"errors,omitempty"`
+}
+
+// graphqlServer executes operations of GET and POST /graphql against the
+// schema built once on start.
+type graphqlServer struct {
+	schema graphql.Schema
+	cfg    config.GraphQL
+}
+
+func newGraphQLServer(cfg config.GraphQL) *graphqlServer {
+	schema, err := newGraphQLSchema()
+	if err != nil {
+		panic(err)
+	}
+	return &graphqlServer{schema: schema, cfg: cfg}
+}
+
+// query godoc
+// @Summary      GraphQL queries over GET
+// @Description  Availible only for authenticated user, executing GraphQL queries like POST /graphql does, mutations are rejected.
+// @Tags         graphql
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /graphql [get]
+// @Param query query string true "GraphQL document" example({ films { name } })
+// @Param operationName query string false "Operation to execute when the document has many"
+// @Param variables query string false "JSON object of variables" example({"id":"1"})
+// @Security BasicAuth
+// @Success 200 {object} GraphQLResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func (s *graphqlServer) query(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r)
+}
+
+// execute godoc
+// @Summary      GraphQL endpoint
+// @Description  Availible only for authenticated user, executing GraphQL queries over films, actors and their roles, mutations are availible only for admin user. Queries deeper or more complex than the configured limits are rejected before execution. Relations of sibling objects are loaded in one query per level.
+// @Tags         graphql
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /graphql [post]
+// @Param Request body GraphQLRequest true "GraphQL operation"
+// @Security BasicAuth
+// @Success 200 {object} GraphQLResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func (s *graphqlServer) execute(w http.ResponseWriter, r *http.Request) {
+	s.serve(w, r)
+}
+
+func (s *graphqlServer) serve(w http.ResponseWriter, r *http.Request) {
+	role, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	req, err := readGraphQLRequest(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		writeResponse(w, r, http.StatusOK, &GraphQLResponse{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	validation := graphql.ValidateDocument(&s.schema, doc, nil)
+	if !validation.IsValid {
+		writeResponse(w, r, http.StatusOK, &GraphQLResponse{Errors: validation.Errors})
+		return
+	}
+
+	operation := findOperation(doc, req.OperationName)
+	if operation != nil && operation.Operation == ast.OperationTypeMutation && r.Method != http.MethodPost {
+		HandleError(w, r, errs.BadRequest(errs.CodeBadRequest, "mutations are allowed only in POST requests"))
+		return
+	}
+	if operation != nil {
+		meter := &queryMeter{
+			schema:    &s.schema,
+			fragments: map[string]*ast.FragmentDefinition{},
+			variables: req.Variables,
+			listSize:  s.cfg.ListSize,
+		}
+		for _, definition := range doc.Definitions {
+			if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+				meter.fragments[fragment.Name.Value] = fragment
+			}
+		}
+		root := s.schema.QueryType()
+		if operation.Operation == ast.OperationTypeMutation {
+			root = s.schema.MutationType()
+		}
+		depth, complexity := meter.measure(operation.SelectionSet, root, 1)
+		if s.cfg.MaxDepth > 0 && depth > s.cfg.MaxDepth {
+			writeGraphQLError(w, r, "query_too_deep", "query depth "+strconv.Itoa(depth)+" exceeds "+strconv.Itoa(s.cfg.MaxDepth))
+			return
+		}
+		if s.cfg.MaxComplexity > 0 && complexity > s.cfg.MaxComplexity {
+			writeGraphQLError(w, r, "query_too_complex", "query complexity "+strconv.Itoa(complexity)+" exceeds "+strconv.Itoa(s.cfg.MaxComplexity))
+			return
+		}
+	}
+
+	ctx := withGraphQLContext(r.Context(), &graphqlContext{
+		r:        r,
+		db:       pgdb,
+		role:     role,
+		loaders:  newLoaders(pgdb),
+		listSize: s.cfg.ListSize,
+	})
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	writeResponse(w, r, http.StatusOK, &GraphQLResponse{Data: result.Data, Errors: result.Errors})
+}
+
+func readGraphQLRequest(r *http.Request) (*GraphQLRequest, error) {
+	req := &GraphQLRequest{}
+	switch {
+	case r.Method == http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if variables := r.URL.Query().Get("variables"); variables != "" {
+			err := json.Unmarshal([]byte(variables), &req.Variables)
+			if err != nil {
+				return nil, errs.BadRequest(errs.CodeInvalidParam, "query param variables must be a JSON object")
+			}
+		}
+	case strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql"):
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, errs.Wrap(errs.KindBadRequest, errs.CodeBadRequest, "request body can not be read", err)
+		}
+		req.Query = string(body)
+	default:
+		err := readBody(r, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, errs.BadRequest(errs.CodeBadRequest, "query must not be empty")
+	}
+	return req, nil
+}
+
+// findOperation returns the operation to execute, nil when it is ambiguous
+// and the executor reports it.
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = operation
+		} else if operation.Name != nil && operation.Name.Value == name {
+			return operation
+		}
+	}
+	return found
+}
+
+func writeGraphQLError(w http.ResponseWriter, r *http.Request, code string, message string) {
+	err := gqlerrors.NewFormattedError(message)
+	err.Extensions = map[string]interface{}{"code": code}
+	writeResponse(w, r, http.StatusOK, &GraphQLResponse{Errors: []gqlerrors.FormattedError{err}})
+}
+
+// queryMeter estimates the cost of an operation before it is executed.
+// Every field costs one, fields of list items are multiplied by the limit
+// argument of the list or by listSize when there is none. Introspection
+// fields are not counted.
+type queryMeter struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	listSize  int
+}
+
+// measure returns the deepest level of fields and the cost of the set.
+func (m *queryMeter) measure(set *ast.SelectionSet, parent graphql.Type, level int) (int, int) {
+	if set == nil {
+		return level - 1, 0
+	}
+
+	depth, cost := level, 0
+	for _, selection := range set.Selections {
+		var childDepth, childCost int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			name := selection.Name.Value
+			fields := graphqlFields(parent)
+			if strings.HasPrefix(name, "__") || fields[name] == nil {
+				continue
+			}
+			fieldType, list := unwrapGraphQLType(fields[name].Type)
+			childDepth, childCost = level, 0
+			if selection.SelectionSet != nil {
+				childDepth, childCost = m.measure(selection.SelectionSet, fieldType, level+1)
+			}
+			if list {
+				childCost *= m.size(selection, m.maxLimit(name))
+			}
+			childCost++
+		case *ast.InlineFragment:
+			fragmentType := parent
+			if selection.TypeCondition != nil {
+				fragmentType = m.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			childDepth, childCost = m.measure(selection.SelectionSet, fragmentType, level)
+		case *ast.FragmentSpread:
+			fragment := m.fragments[selection.Name.Value]
+			if fragment == nil {
+				continue
+			}
+			childDepth, childCost = m.measure(fragment.SelectionSet, m.schema.Type(fragment.TypeCondition.Name.Value), level)
+		}
+		depth = max(depth, childDepth)
+		cost += childCost
+	}
+	return depth, cost
+}
+
+// maxLimit is the largest limit argument of the list field, root lists are
+// clamped to listSize.
+func (m *queryMeter) maxLimit(name string) int {
+	if name == "search" {
+		return maxSearchLimit
+	}
+	return m.listSize
+}
+
+// size is the expected length of the list field, larger limits count as
+// maxLimit. Limits the resolver rejects or clamps up, like negative ones,
+// can not lower the cost of other fields, so they count as listSize.
+func (m *queryMeter) size(field *ast.Field, maxLimit int) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			limit, err := strconv.Atoi(value.Value)
+			if err == nil && limit >= 1 {
+				return min(limit, maxLimit)
+			}
+		case *ast.Variable:
+			// Variables are decoded from JSON.
+			limit, ok := m.variables[value.Name.Value].(float64)
+			if ok && limit == math.Trunc(limit) && limit >= 1 {
+				return int(math.Min(limit, float64(maxLimit)))
+			}
+		}
+	}
+	return m.listSize
+}
+
+func graphqlFields(t graphql.Type) graphql.FieldDefinitionMap {
+	switch t := t.(type) {
+	case *graphql.Object:
+		return t.Fields()
+	case *graphql.Interface:
+		return t.Fields()
+	}
+	return nil
+}
+
+// unwrapGraphQLType strips non null and list wrappers, list reports
+// whether there was a list.
+func unwrapGraphQLType(t graphql.Type) (graphql.Type, bool) {
+	list := false
+	for {
+		switch wrapper := t.(type) {
+		case *graphql.NonNull:
+			t = wrapper.OfType
+		case *graphql.List:
+			list = true
+			t = wrapper.OfType
+		default:
+			return t, list
+		}
+	}
+}
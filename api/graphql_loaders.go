@@ -0,0 +1,136 @@
+package api
+
+import (
+	"filmoteka/db"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// loader batches keys requested by sibling resolvers into one query.
+// Resolvers call load and return the thunk, the executor calls thunks
+// level by level after all siblings registered their keys. Execution of
+// a request is sequential, so loaders are not locked.
+type loader[K comparable, V any] struct {
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	results map[K]V
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, results: map[K]V{}}
+}
+
+func (l *loader[K, V]) load(key K) func() (interface{}, error) {
+	if _, ok := l.results[key]; !ok {
+		l.pending = append(l.pending, key)
+	}
+	return func() (interface{}, error) {
+		if _, ok := l.results[key]; !ok && len(l.pending) > 0 {
+			err := l.flush()
+			if err != nil {
+				return nil, graphqlError(err)
+			}
+		}
+		return l.results[key], nil
+	}
+}
+
+func (l *loader[K, V]) flush() error {
+	keys := make([]K, 0, len(l.pending))
+	seen := make(map[K]bool, len(l.pending))
+	for _, key := range l.pending {
+		if _, ok := l.results[key]; !ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	l.pending = l.pending[:0]
+	if len(keys) == 0 {
+		return nil
+	}
+
+	results, err := l.fetch(keys)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		// Missing keys are cached as zero values, nil for deleted rows.
+		l.results[key] = results[key]
+	}
+	return nil
+}
+
+// loaders live for one GraphQL request.
+type loaders struct {
+	films            *loader[int, *db.Film]
+	actors           *loader[int64, *db.Actor]
+	casts            *loader[int, []*db.FilmToActor]
+	roles            *loader[int64, []*db.FilmToActor]
+	filmExternalIDs  *loader[int, []*db.FilmExternalID]
+	actorExternalIDs *loader[int64, []*db.ActorExternalID]
+}
+
+func newLoaders(pgdb *pg.DB) *loaders {
+	return &loaders{
+		films: newLoader(func(ids []int) (map[int]*db.Film, error) {
+			films, err := db.GetFilmsByIDs(pgdb, ids)
+			res := make(map[int]*db.Film, len(films))
+			for _, film := range films {
+				res[film.ID] = film
+			}
+			return res, err
+		}),
+		actors: newLoader(func(ids []int64) (map[int64]*db.Actor, error) {
+			actors, err := db.GetActorsByIDs(pgdb, ids)
+			res := make(map[int64]*db.Actor, len(actors))
+			for _, actor := range actors {
+				res[actor.ID] = actor
+			}
+			return res, err
+		}),
+		casts: newLoader(func(ids []int) (map[int][]*db.FilmToActor, error) {
+			links, err := db.GetCasts(pgdb, ids)
+			res := make(map[int][]*db.FilmToActor, len(ids))
+			for _, id := range ids {
+				res[id] = make([]*db.FilmToActor, 0)
+			}
+			for _, link := range links {
+				res[link.FilmID] = append(res[link.FilmID], link)
+			}
+			return res, err
+		}),
+		roles: newLoader(func(ids []int64) (map[int64][]*db.FilmToActor, error) {
+			links, err := db.GetRoles(pgdb, ids)
+			res := make(map[int64][]*db.FilmToActor, len(ids))
+			for _, id := range ids {
+				res[id] = make([]*db.FilmToActor, 0)
+			}
+			for _, link := range links {
+				res[int64(link.ActorID)] = append(res[int64(link.ActorID)], link)
+			}
+			return res, err
+		}),
+		filmExternalIDs: newLoader(func(ids []int) (map[int][]*db.FilmExternalID, error) {
+			externalIDs, err := db.GetFilmsExternalIDs(pgdb, ids)
+			res := make(map[int][]*db.FilmExternalID, len(ids))
+			for _, id := range ids {
+				res[id] = make([]*db.FilmExternalID, 0)
+			}
+			for _, id := range externalIDs {
+				res[id.FilmID] = append(res[id.FilmID], id)
+			}
+			return res, err
+		}),
+		actorExternalIDs: newLoader(func(ids []int64) (map[int64][]*db.ActorExternalID, error) {
+			externalIDs, err := db.GetActorsExternalIDs(pgdb, ids)
+			res := make(map[int64][]*db.ActorExternalID, len(ids))
+			for _, id := range ids {
+				res[id] = make([]*db.ActorExternalID, 0)
+			}
+			for _, id := range externalIDs {
+				res[id.ActorID] = append(res[id.ActorID], id)
+			}
+			return res, err
+		}),
+	}
+}
@@ -0,0 +1,781 @@
+package api
+
+import (
+	"context"
+	"errors"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+type graphqlKey struct{}
+
+// graphqlContext is a state of one GraphQL request shared by resolvers.
+type graphqlContext struct {
+	r       *http.Request
+	db      *pg.DB
+	role    string
+	loaders *loaders
+	// listSize pages root lists of films and actors.
+	listSize int
+}
+
+func withGraphQLContext(ctx context.Context, gc *graphqlContext) context.Context {
+	return context.WithValue(ctx, graphqlKey{}, gc)
+}
+
+func getGraphQLContext(p graphql.ResolveParams) *graphqlContext {
+	return p.Context.Value(graphqlKey{}).(*graphqlContext)
+}
+
+// resolverError is an error of a field with the code in extensions.
+type resolverError struct {
+	e *errs.Error
+}
+
+func (e *resolverError) Error() string {
+	return e.e.Message
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.e.Code}
+	if len(e.e.Fields) > 0 {
+		ext["errors"] = e.e.Fields
+	}
+	return ext
+}
+
+// graphqlError converts err like HandleError does, causes of internal
+// errors are logged and never sent to the client.
+func graphqlError(err error) error {
+	e := errs.From(err)
+	if e.Kind == errs.KindInternal {
+		slog.Error("graphql resolver failed", "err", err)
+	} else {
+		slog.Debug("graphql resolver rejected", "err", err)
+	}
+	return &resolverError{e: e}
+}
+
+// requireAdmin allows mutations only for admin users like REST does.
+func requireAdmin(p graphql.ResolveParams) error {
+	if getGraphQLContext(p).role != db.Admin {
+		return graphqlError(errs.Forbidden("wrong access level"))
+	}
+	return nil
+}
+
+func idArg(p graphql.ResolveParams, name string) (int64, error) {
+	value, _ := p.Args[name].(string)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, graphqlError(errs.BadRequest(errs.CodeInvalidParam, name+" must be an integer"))
+	}
+	return id, nil
+}
+
+func stringArg(args map[string]interface{}, name string) string {
+	value, _ := args[name].(string)
+	return value
+}
+
+func intArg(args map[string]interface{}, name string) int {
+	value, _ := args[name].(int)
+	return value
+}
+
+// pageArgs clamps limit and offset of root lists, lists without limit or
+// with a larger one have listSize items.
+func pageArgs(p graphql.ResolveParams) *db.Selection {
+	listSize := getGraphQLContext(p).listSize
+	limit, ok := p.Args["limit"].(int)
+	if !ok || limit > listSize {
+		limit = listSize
+	}
+	return &db.Selection{Limit: max(limit, 1), Offset: max(intArg(p.Args, "offset"), 0)}
+}
+
+// castArg reads cast input into actor ids and roles of the film requests.
+func castArg(args map[string]interface{}) ([]int, map[int]string, error) {
+	cast, _ := args["cast"].([]interface{})
+	actors := make([]int, 0, len(cast))
+	roles := make(map[int]string, len(cast))
+	for _, item := range cast {
+		member, _ := item.(map[string]interface{})
+		actorID, err := strconv.Atoi(stringArg(member, "actorId"))
+		if err != nil {
+			return nil, nil, graphqlError(errs.BadRequest(errs.CodeInvalidParam, "actorId must be an integer"))
+		}
+		actors = append(actors, actorID)
+		if role := stringArg(member, "role"); role != "" {
+			roles[actorID] = role
+		}
+	}
+	return actors, roles, nil
+}
+
+// validateArgs checks the request built from arguments like REST bodies.
+func validateArgs(p graphql.ResolveParams, req interface{}) error {
+	err := validationError(getGraphQLContext(p).r, Validate.Struct(req))
+	if err != nil {
+		return graphqlError(err)
+	}
+	return nil
+}
+
+var dateScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Date",
+	Description: "Date in YYYY-MM-DD format.",
+	Serialize: func(value interface{}) interface{} {
+		switch date := value.(type) {
+		case time.Time:
+			return date.Format(db.DateLayout)
+		case *time.Time:
+			if date != nil {
+				return date.Format(db.DateLayout)
+			}
+		}
+		return nil
+	},
+	// Dates are checked by the validator to get the same messages as REST.
+	ParseValue: func(value interface{}) interface{} {
+		date, _ := value.(string)
+		return date
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		if date, ok := value.(*ast.StringValue); ok {
+			return date.Value
+		}
+		return nil
+	},
+})
+
+var externalIDType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ExternalId",
+	Fields: graphql.Fields{
+		"source": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				switch id := p.Source.(type) {
+				case *db.FilmExternalID:
+					return id.Value, nil
+				case *db.ActorExternalID:
+					return id.Value, nil
+				}
+				return nil, nil
+			},
+		},
+	},
+})
+
+var filmType, actorType, roleType *graphql.Object
+
+func init() {
+	filmType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Film",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"date":        &graphql.Field{Type: graphql.NewNonNull(dateScalar)},
+				"rate":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"cast": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(roleType))),
+					Description: "Actors of the film with their roles.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return getGraphQLContext(p).loaders.casts.load(p.Source.(*db.Film).ID), nil
+					},
+				},
+				"externalIds": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(externalIDType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return getGraphQLContext(p).loaders.filmExternalIDs.load(p.Source.(*db.Film).ID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	actorType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Actor",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+				"name":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"sex":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+				"birthday": &graphql.Field{Type: graphql.NewNonNull(dateScalar)},
+				"filmography": &graphql.Field{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(roleType))),
+					Description: "Films of the actor with their roles.",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return getGraphQLContext(p).loaders.roles.load(p.Source.(*db.Actor).ID), nil
+					},
+				},
+				"externalIds": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(externalIDType))),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return getGraphQLContext(p).loaders.actorExternalIDs.load(p.Source.(*db.Actor).ID), nil
+					},
+				},
+			}
+		}),
+	})
+
+	roleType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Role",
+		Description: "Link of an actor and a film.",
+		Fields: graphql.Fields{
+			"role": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"film": &graphql.Field{
+				Type: graphql.NewNonNull(filmType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return getGraphQLContext(p).loaders.films.load(p.Source.(*db.FilmToActor).FilmID), nil
+				},
+			},
+			"actor": &graphql.Field{
+				Type: graphql.NewNonNull(actorType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return getGraphQLContext(p).loaders.actors.load(int64(p.Source.(*db.FilmToActor).ActorID)), nil
+				},
+			},
+		},
+	})
+}
+
+var searchResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SearchResult",
+	Fields: graphql.Fields{
+		"type":    &graphql.Field{Type: graphql.NewNonNull(searchTypeEnum)},
+		"id":      &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"name":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"snippet": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Matched words are wrapped in <b> tags."},
+		"rank":    &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+	},
+})
+
+var pathLinkType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "PathLink",
+	Description: "Actor in a co-star chain and the film shared with the next one.",
+	Fields: graphql.Fields{
+		"actorId":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"actorName": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"filmId": &graphql.Field{
+			Type:        graphql.ID,
+			Description: "Empty for the last actor of the chain.",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if link := p.Source.(*db.PathLink); link.FilmID != 0 {
+					return link.FilmID, nil
+				}
+				return nil, nil
+			},
+		},
+		"filmName": &graphql.Field{
+			Type: graphql.String,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if link := p.Source.(*db.PathLink); link.FilmName != "" {
+					return link.FilmName, nil
+				}
+				return nil, nil
+			},
+		},
+	},
+})
+
+var searchTypeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "SearchType",
+	Values: graphql.EnumValueConfigMap{
+		"FILM":  &graphql.EnumValueConfig{Value: db.SearchFilm},
+		"ACTOR": &graphql.EnumValueConfig{Value: db.SearchActor},
+	},
+})
+
+var filmSortEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "FilmSort",
+	Values: graphql.EnumValueConfigMap{
+		"RATE": &graphql.EnumValueConfig{Value: "rate DESC", Description: "Best rated first, the default."},
+		"NAME": &graphql.EnumValueConfig{Value: "name"},
+		"DATE": &graphql.EnumValueConfig{Value: "date"},
+	},
+})
+
+var filmFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "FilmFilter",
+	Description: "Films with the field containing the value.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"field": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.NewEnum(graphql.EnumConfig{
+			Name: "FilmFilterField",
+			Values: graphql.EnumValueConfigMap{
+				"NAME":        &graphql.EnumValueConfig{Value: "name"},
+				"DESCRIPTION": &graphql.EnumValueConfig{Value: "description"},
+			},
+		}))},
+		"value": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+var castInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "CastInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"actorId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.ID)},
+		"role":    &graphql.InputObjectFieldConfig{Type: graphql.String},
+	},
+})
+
+var filmInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "FilmInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"date":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(dateScalar)},
+		"rate":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		"cast":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(castInput))},
+	},
+})
+
+var filmPatchInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "FilmPatch",
+	Description: "Changed fields of the film, cast is replaced when set.",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":        &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"date":        &graphql.InputObjectFieldConfig{Type: dateScalar},
+		"rate":        &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"cast":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(castInput))},
+	},
+})
+
+var actorInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "ActorInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"sex":   &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"birth": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(dateScalar)},
+	},
+})
+
+var actorPatchInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "ActorPatch",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"sex":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"birth": &graphql.InputObjectFieldConfig{Type: dateScalar},
+	},
+})
+
+// newGraphQLSchema mirrors REST reads in queries and admin writes in mutations.
+func newGraphQLSchema() (graphql.Schema, error) {
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"film": &graphql.Field{
+				Type: filmType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return getGraphQLContext(p).loaders.films.load(int(id)), nil
+				},
+			},
+			"films": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(filmType))),
+				Args: graphql.FieldConfigArgument{
+					"sortBy": {Type: filmSortEnum, DefaultValue: "rate DESC"},
+					"filter": {Type: filmFilterInput},
+					"limit":  {Type: graphql.Int},
+					"offset": {Type: graphql.Int},
+				},
+				Resolve: resolveFilms,
+			},
+			"filmByExternalId": &graphql.Field{
+				Type: filmType,
+				Args: graphql.FieldConfigArgument{
+					"source": {Type: graphql.NewNonNull(graphql.String)},
+					"id":     {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source := p.Args["source"].(string)
+					if !db.IsExternalSource(source) {
+						return nil, graphqlError(errs.BadRequest(errs.CodeInvalidParam, "unknown external source "+source))
+					}
+					film, err := db.GetFilmByExternalID(getGraphQLContext(p).db, source, p.Args["id"].(string), &db.Selection{})
+					if errors.Is(err, pg.ErrNoRows) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, graphqlError(err)
+					}
+					return film, nil
+				},
+			},
+			"actor": &graphql.Field{
+				Type: actorType,
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := idArg(p, "id")
+					if err != nil {
+						return nil, err
+					}
+					return getGraphQLContext(p).loaders.actors.load(id), nil
+				},
+			},
+			"actors": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(actorType))),
+				Args: graphql.FieldConfigArgument{
+					"limit":  {Type: graphql.Int},
+					"offset": {Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					actors, err := db.GetActors(getGraphQLContext(p).db, pageArgs(p))
+					if err != nil {
+						return nil, graphqlError(err)
+					}
+					return actors, nil
+				},
+			},
+			"actorByExternalId": &graphql.Field{
+				Type: actorType,
+				Args: graphql.FieldConfigArgument{
+					"source": {Type: graphql.NewNonNull(graphql.String)},
+					"id":     {Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source := p.Args["source"].(string)
+					if !db.IsExternalSource(source) {
+						return nil, graphqlError(errs.BadRequest(errs.CodeInvalidParam, "unknown external source "+source))
+					}
+					actor, err := db.GetActorByExternalID(getGraphQLContext(p).db, source, p.Args["id"].(string), &db.Selection{})
+					if errors.Is(err, pg.ErrNoRows) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, graphqlError(err)
+					}
+					return actor, nil
+				},
+			},
+			"search": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(searchResultType))),
+				Args: graphql.FieldConfigArgument{
+					"query": {Type: graphql.NewNonNull(graphql.String)},
+					"type":  {Type: searchTypeEnum},
+					"limit": {Type: graphql.Int, DefaultValue: defaultSearchLimit},
+				},
+				Resolve: resolveSearch,
+			},
+			"costarPath": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pathLinkType))),
+				Description: "Shortest chain of co-stars between two actors.",
+				Args: graphql.FieldConfigArgument{
+					"from": {Type: graphql.NewNonNull(graphql.ID)},
+					"to":   {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					fromID, err := idArg(p, "from")
+					if err != nil {
+						return nil, err
+					}
+					toID, err := idArg(p, "to")
+					if err != nil {
+						return nil, err
+					}
+					path, err := db.GetCostarPath(getGraphQLContext(p).db, fromID, toID)
+					if err != nil {
+						return nil, graphqlError(notFound(err, "actor"))
+					}
+					return path, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createFilm": &graphql.Field{
+				Type:    graphql.NewNonNull(filmType),
+				Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(filmInput)}},
+				Resolve: resolveCreateFilm,
+			},
+			"updateFilm": &graphql.Field{
+				Type: graphql.NewNonNull(filmType),
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: graphql.NewNonNull(graphql.ID)},
+					"input": {Type: graphql.NewNonNull(filmPatchInput)},
+				},
+				Resolve: resolveUpdateFilm,
+			},
+			"deleteFilm": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.ID),
+				Description: "Deletes the film and returns its id.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveDelete(p, "film", db.DeleteFilm)
+				},
+			},
+			"createActor": &graphql.Field{
+				Type:    graphql.NewNonNull(actorType),
+				Args:    graphql.FieldConfigArgument{"input": {Type: graphql.NewNonNull(actorInput)}},
+				Resolve: resolveCreateActor,
+			},
+			"updateActor": &graphql.Field{
+				Type: graphql.NewNonNull(actorType),
+				Args: graphql.FieldConfigArgument{
+					"id":    {Type: graphql.NewNonNull(graphql.ID)},
+					"input": {Type: graphql.NewNonNull(actorPatchInput)},
+				},
+				Resolve: resolveUpdateActor,
+			},
+			"deleteActor": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.ID),
+				Description: "Deletes the actor and returns its id.",
+				Args:        graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveDelete(p, "actor", db.DeleteActor)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func resolveFilms(p graphql.ResolveParams) (interface{}, error) {
+	var filter []string
+	if arg, ok := p.Args["filter"].(map[string]interface{}); ok {
+		filter = []string{stringArg(arg, "field"), stringArg(arg, "value")}
+	}
+
+	films, err := db.GetFilms(getGraphQLContext(p).db, p.Args["sortBy"].(string), filter, pageArgs(p))
+	if err != nil {
+		return nil, graphqlError(err)
+	}
+	return films, nil
+}
+
+func resolveSearch(p graphql.ResolveParams) (interface{}, error) {
+	query := p.Args["query"].(string)
+	if query == "" {
+		return nil, graphqlError(errs.BadRequest(errs.CodeInvalidParam, "query must not be empty"))
+	}
+	limit := intArg(p.Args, "limit")
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, graphqlError(errs.BadRequest(errs.CodeInvalidParam, "limit must be between 1 and 100"))
+	}
+
+	results, err := db.Search(getGraphQLContext(p).db, query, db.SearchLanguage(query), stringArg(p.Args, "type"), limit)
+	if err != nil {
+		return nil, graphqlError(err)
+	}
+	return results, nil
+}
+
+func resolveCreateFilm(p graphql.ResolveParams) (interface{}, error) {
+	err := requireAdmin(p)
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+	actors, roles, err := castArg(input)
+	if err != nil {
+		return nil, err
+	}
+	req := &api_models.CreateFilmRequest{
+		Name:        stringArg(input, "name"),
+		Description: stringArg(input, "description"),
+		Date:        stringArg(input, "date"),
+		Rate:        intArg(input, "rate"),
+		Actors:      actors,
+		Roles:       roles,
+	}
+	err = validateArgs(p, req)
+	if err != nil {
+		return nil, err
+	}
+	date, _ := time.Parse(db.DateLayout, req.Date)
+
+	gc := getGraphQLContext(p)
+	var film *db.Film
+	err = gc.db.RunInTransaction(p.Context, func(tx *pg.Tx) error {
+		film, err = db.CreateFilm(tx, &db.Film{
+			Name:        req.Name,
+			Description: req.Description,
+			Date:        date,
+			Rate:        req.Rate,
+		}, req.Actors, req.Roles)
+		return err
+	})
+	if err != nil {
+		return nil, graphqlError(err)
+	}
+	return film, nil
+}
+
+// resolveUpdateFilm changes only given fields, unlike REST which writes
+// all columns of the film.
+func resolveUpdateFilm(p graphql.ResolveParams) (interface{}, error) {
+	err := requireAdmin(p)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+	actors, roles, err := castArg(input)
+	if err != nil {
+		return nil, err
+	}
+	req := &api_models.UpdateFilmRequest{
+		Name:        stringArg(input, "name"),
+		Description: stringArg(input, "description"),
+		Date:        stringArg(input, "date"),
+		Rate:        intArg(input, "rate"),
+	}
+	err = validateArgs(p, req)
+	if err != nil {
+		return nil, err
+	}
+
+	gc := getGraphQLContext(p)
+	film, err := db.GetFilm(gc.db, int(id), &db.Selection{})
+	if err != nil {
+		return nil, graphqlError(notFound(err, "film"))
+	}
+	err = gc.db.RunInTransaction(p.Context, func(tx *pg.Tx) error {
+		if _, ok := input["name"]; ok {
+			film.Name = req.Name
+		}
+		if _, ok := input["description"]; ok {
+			film.Description = req.Description
+		}
+		if _, ok := input["date"]; ok {
+			film.Date, _ = time.Parse(db.DateLayout, req.Date)
+		}
+		if _, ok := input["rate"]; ok {
+			film.Rate = req.Rate
+		}
+		film, err = db.UpdateFilm(tx, film)
+		if err != nil {
+			return notFound(err, "film")
+		}
+		if _, ok := input["cast"]; ok {
+			return db.SetFilmActors(tx, film.ID, actors, roles)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, graphqlError(err)
+	}
+	return film, nil
+}
+
+func resolveCreateActor(p graphql.ResolveParams) (interface{}, error) {
+	err := requireAdmin(p)
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+	req := &api_models.CreateActorRequest{
+		Name:  stringArg(input, "name"),
+		Sex:   stringArg(input, "sex"),
+		Birth: stringArg(input, "birth"),
+	}
+	err = validateArgs(p, req)
+	if err != nil {
+		return nil, err
+	}
+	birth, _ := time.Parse(db.DateLayout, req.Birth)
+
+	gc := getGraphQLContext(p)
+	var actor *db.Actor
+	err = gc.db.RunInTransaction(p.Context, func(tx *pg.Tx) error {
+		actor, err = db.CreateActor(tx, &db.Actor{
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: birth,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, graphqlError(err)
+	}
+	return actor, nil
+}
+
+func resolveUpdateActor(p graphql.ResolveParams) (interface{}, error) {
+	err := requireAdmin(p)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	input := p.Args["input"].(map[string]interface{})
+	req := &api_models.UpdateActorRequest{
+		Name:  stringArg(input, "name"),
+		Sex:   stringArg(input, "sex"),
+		Birth: stringArg(input, "birth"),
+	}
+	err = validateArgs(p, req)
+	if err != nil {
+		return nil, err
+	}
+	var birth time.Time
+	if req.Birth != "" {
+		birth, _ = time.Parse(db.DateLayout, req.Birth)
+	}
+
+	gc := getGraphQLContext(p)
+	var actor *db.Actor
+	err = gc.db.RunInTransaction(p.Context, func(tx *pg.Tx) error {
+		actor, err = db.UpdateActor(tx, &db.Actor{
+			ID:    id,
+			Name:  req.Name,
+			Sex:   req.Sex,
+			Birth: birth,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, graphqlError(notFound(err, "actor"))
+	}
+	return actor, nil
+}
+
+func resolveDelete(p graphql.ResolveParams, resource string, remove func(orm.DB, int64) error) (interface{}, error) {
+	err := requireAdmin(p)
+	if err != nil {
+		return nil, err
+	}
+	id, err := idArg(p, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = getGraphQLContext(p).db.RunInTransaction(p.Context, func(tx *pg.Tx) error {
+		return remove(tx, id)
+	})
+	if err != nil {
+		return nil, graphqlError(notFound(err, resource))
+	}
+	return id, nil
+}
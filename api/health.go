@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"filmoteka/cache"
+	"filmoteka/config"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"filmoteka/health"
+
+	"github.com/go-pg/pg/v10"
+)
+
+const checkDatabase = "database"
+
+// newChecks registers dependencies the service can not serve requests
+// without, the cache is checked only when it is enabled.
+func newChecks(pgdb *pg.DB, store cache.Cache, cfg config.Health) *health.Registry {
+	checks := health.NewRegistry(cfg.CheckTimeout)
+	checks.Register(checkDatabase, pgdb.Ping)
+	checks.Register("migrations", func(ctx context.Context) error {
+		return db.CheckSchema(ctx, pgdb)
+	})
+	if store != nil {
+		checks.Register("cache", store.Ping)
+	}
+	return checks
+}
+
+func getChecks(r *http.Request) (*health.Registry, error) {
+	checks, ok := r.Context().Value("Health").(*health.Registry)
+	if !ok {
+		return nil, errs.Internal(errors.New("could not get health checks from context"))
+	}
+	return checks, nil
+}
+
+// livez answers while the process serves requests, dependencies are not
+// checked so their failures do not get the instance restarted.
+func livez(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, r, http.StatusOK, &health.Report{Status: health.StatusOK, Checks: []*health.Result{}})
+}
+
+// readyz runs all checks and fails once the server is shutting down, so
+// load balancers stop sending requests before connections are closed.
+func readyz(w http.ResponseWriter, r *http.Request) {
+	checks, err := getChecks(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	var report *health.Report
+	select {
+	case <-shutdown(r):
+		report = &health.Report{Status: health.StatusOK, Checks: []*health.Result{}}
+		report.Fail("shutdown", errors.New("server is shutting down"))
+	default:
+		report = checks.Run(r.Context())
+	}
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeResponse(w, r, status, report)
+}
+
+// healthcheck godoc
+// @Summary      Health check
+// @Description  Reports that the service is up and the database answers
+// @Tags         health
+// @Produce      plain
+// @Router       /healthcheck [get]
+// @Success 200 {string} string "OK"
+// @Failure 500 {object}  ErrorResponse
+func healthcheck(w http.ResponseWriter, r *http.Request) {
+	checks, err := getChecks(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	report := checks.Run(r.Context(), checkDatabase)
+	if report.Status != health.StatusOK {
+		HandleError(w, r, errs.Internal(report.Err()))
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
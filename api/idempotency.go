@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"filmoteka/config"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	// idempotentReplayedHeader marks responses replayed from the store.
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+
+	// idempotencyPurgeInterval is how often expired keys are deleted.
+	idempotencyPurgeInterval = time.Hour
+)
+
+// replayedHeaders are response headers stored along with the body.
+var replayedHeaders = []string{"Content-Type", "Location", "Vary"}
+
+// idempotency replays the stored response when a POST is repeated with the
+// same Idempotency-Key by the same user within TTL. Keys reused with another
+// request are rejected, server errors are not stored so they can be retried.
+func idempotency(pgdb *pg.DB, cfg config.Idempotency) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "Idempotency-Key is longer than 255 characters"))
+				return
+			}
+
+			// Bodies are kept in memory, large uploads are not idempotent.
+			body, err := io.ReadAll(io.LimitReader(r.Body, cfg.MaxBodySize+1))
+			if err != nil {
+				HandleError(w, r, errs.Wrap(errs.KindBadRequest, errs.CodeBadRequest, "can not read request body", err))
+				return
+			}
+			if int64(len(body)) > cfg.MaxBodySize {
+				HandleError(w, r, &errs.Error{Kind: errs.KindTooLarge, Code: errs.CodePayloadTooLarge, Message: "request body with Idempotency-Key is larger than " + strconv.FormatInt(cfg.MaxBodySize, 10) + " bytes"})
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Keys of different users never collide.
+			user, _, _ := r.BasicAuth()
+			now := time.Now()
+			rec := &db.IdempotencyRecord{
+				Key:         user + ":" + key,
+				Fingerprint: fingerprint(r, body),
+				CreatedAt:   now,
+				ExpiresAt:   now.Add(cfg.TTL),
+			}
+
+			reserved, err := db.ReserveIdempotencyKey(pgdb, rec)
+			if err != nil {
+				HandleError(w, r, err)
+				return
+			}
+			if !reserved {
+				replayResponse(w, r, pgdb, rec)
+				return
+			}
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			saved := false
+			defer func() {
+				if saved {
+					return
+				}
+				err := db.ReleaseIdempotencyKey(pgdb, rec.Key)
+				if err != nil {
+					slog.Error("can not release idempotency key", "err", err)
+				}
+			}()
+
+			next.ServeHTTP(recorder, r)
+
+			// Failed auth and server errors depend on more than the request.
+			status := recorder.status
+			if status >= http.StatusInternalServerError || status == http.StatusUnauthorized || status == http.StatusForbidden {
+				return
+			}
+			rec.Status = status
+			rec.Header = http.Header{}
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					rec.Header.Set(name, value)
+				}
+			}
+			rec.Body = recorder.body.Bytes()
+			err = db.SaveIdempotencyResponse(pgdb, rec)
+			if err != nil {
+				slog.Error("can not save idempotent response", "err", err)
+				return
+			}
+			saved = true
+		})
+	}
+}
+
+func replayResponse(w http.ResponseWriter, r *http.Request, pgdb *pg.DB, rec *db.IdempotencyRecord) {
+	stored, err := db.GetIdempotencyRecord(pgdb, rec.Key)
+	if err == pg.ErrNoRows {
+		// The first request has just failed and released the key.
+		HandleError(w, r, errs.Conflict("idempotency_key_in_use", "request with this Idempotency-Key is being processed, retry later"))
+		return
+	}
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	if stored.Fingerprint != rec.Fingerprint {
+		HandleError(w, r, errs.Unprocessable("idempotency_key_reused", "Idempotency-Key was already used with another request"))
+		return
+	}
+	if stored.Status == 0 {
+		HandleError(w, r, errs.Conflict("idempotency_key_in_use", "request with this Idempotency-Key is being processed, retry later"))
+		return
+	}
+
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	w.Write(stored.Body)
+}
+
+// fingerprint identifies the request by method, URL and body.
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func purgeIdempotencyKeys(pgdb *pg.DB, now time.Time) {
+	count, err := db.PurgeIdempotencyKeys(pgdb, now)
+	if err != nil {
+		slog.Error("can not purge idempotency keys", "err", err)
+		return
+	}
+	slog.Debug("purged idempotency keys", "count", count)
+}
+
+// responseRecorder passes the response to the client keeping a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(p []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(p)
+	return rr.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
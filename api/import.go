@@ -0,0 +1,198 @@
+package api
+
+import (
+	"errors"
+	api_models "filmoteka/api/models"
+	"filmoteka/errs"
+	"filmoteka/importer"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// importFormats maps body content types to import formats.
+var importFormats = map[string]string{
+	"text/csv":             importer.FormatCSV,
+	"application/csv":      importer.FormatCSV,
+	"application/x-ndjson": importer.FormatNDJSON,
+	"application/jsonl":    importer.FormatNDJSON,
+}
+
+// importCatalogue godoc
+// @Summary      Import films or actors
+// @Description  Availible only for admin user, importing CSV or NDJSON dump in background. Rows are matched by name and date (films) or name and birth (actors), existing ones are updated. Cast column holds actor names, #id or external ids like imdb:nm0000206 separated by ";". Poll the returned job for progress and report.
+// @Tags         import
+// @Accept       text/csv
+// @Accept       application/x-ndjson
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /import [post]
+// @Param type query string true "What is imported" Enums(films, actors)
+// @Param format query string false "Body format, taken from Content-Type by default" Enums(csv, ndjson)
+// @Param dry_run query bool false "Only validate rows and report what would be changed"
+// @Param map query string false "Comma separated renames of source columns" example(title=name,year=date)
+// @Param Dump body string true "CSV with header or JSON object per line"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request, repeated requests get the first response" example(9f4c2a1e-create-film)
+// @Security BasicAuth
+// @Success 202 {object} api_models.ImportJobResponse
+// @Header 202 {string} Location "URL of the job"
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 413 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+// @Failure 422 {object}  ErrorResponse
+func importCatalogue(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	opts, err := importOptions(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	jobs, err := getImportJobs(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	// Body is spooled to disk as the job outlives the request, it is
+	// limited by size rather than by read timeout.
+	liftReadDeadline(w)
+	path, size, err := spoolBody(w, r, jobs.MaxSize)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	job := jobs.Start(opts, path, size)
+
+	res := &api_models.ImportJobResponse{
+		Success: true,
+		Error:   "",
+		Job:     job,
+	}
+	w.Header().Set("Location", "/import/"+job.ID)
+	writeResponse(w, r, http.StatusAccepted, res)
+}
+
+// getImportJob godoc
+// @Summary      Get import job
+// @Description  Availible only for admin user, getting progress and report of the import, finished jobs are kept for an hour
+// @Tags         import
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /import/{jobID} [get]
+// @Param jobID path string true "Job Id"
+// @Security BasicAuth
+// @Success 200 {object} api_models.ImportJobResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+func getImportJob(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	jobs, err := getImportJobs(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	job, ok := jobs.Get(chi.URLParam(r, "jobID"))
+	if !ok {
+		HandleError(w, r, errs.NotFound("import_job_not_found", "import job not found"))
+		return
+	}
+
+	res := &api_models.ImportJobResponse{
+		Success: true,
+		Error:   "",
+		Job:     job,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+func importOptions(r *http.Request) (importer.Options, error) {
+	query := r.URL.Query()
+	opts := importer.Options{
+		Type:   query.Get("type"),
+		Format: query.Get("format"),
+	}
+
+	if opts.Format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		opts.Format = importFormats[mediaType]
+	}
+
+	var err error
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		opts.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return opts, errs.BadRequest(errs.CodeInvalidParam, "dry_run must be true or false")
+		}
+	}
+
+	opts.Mapping, err = importer.ParseMapping(query.Get("map"))
+	if err != nil {
+		return opts, errs.BadRequest(errs.CodeInvalidParam, err.Error())
+	}
+
+	err = opts.Validate()
+	if err != nil {
+		return opts, errs.BadRequest(errs.CodeInvalidParam, err.Error())
+	}
+	return opts, nil
+}
+
+func spoolBody(w http.ResponseWriter, r *http.Request, maxSize int64) (string, int64, error) {
+	body := io.Reader(r.Body)
+	if maxSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, maxSize)
+	}
+
+	file, err := os.CreateTemp("", "filmoteka-import-*")
+	if err != nil {
+		return "", 0, errs.Internal(err)
+	}
+	defer file.Close()
+
+	size, err := io.Copy(file, body)
+	if err != nil {
+		os.Remove(file.Name())
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", 0, errs.Wrap(errs.KindTooLarge, errs.CodePayloadTooLarge, "import body is larger than "+strconv.FormatInt(maxSize, 10)+" bytes", err)
+		}
+		return "", 0, errs.Wrap(errs.KindBadRequest, errs.CodeBadRequest, "can not read import body", err)
+	}
+	if size == 0 {
+		os.Remove(file.Name())
+		return "", 0, errs.BadRequest(errs.CodeBadRequest, "import body is empty")
+	}
+	return file.Name(), size, nil
+}
+
+func getImportJobs(r *http.Request) (*importer.Jobs, error) {
+	jobs, ok := r.Context().Value("Importer").(*importer.Jobs)
+	if !ok {
+		return nil, errs.Internal(errors.New("could not get the Importer from context"))
+	}
+	return jobs, nil
+}
@@ -1,27 +1,161 @@
 package api_models
 
-import db_models "filmoteka/db"
+import (
+	db_models "filmoteka/db"
+	"time"
+)
+
+const ExpandFilms = "films"
+
+// ActorFields are JSON keys of Actor which can be selected with fields param.
+var ActorFields = []string{"id", "name", "sex", "birthday", "films", FieldExternalIDs}
+
+// ActorSummaryFields are JSON keys of ActorSummary nested into films.
+var ActorSummaryFields = []string{"id", "name", "sex", "birthday"}
+
+var actorColumns = map[string]string{
+	"id":       "id",
+	"name":     "name",
+	"sex":      "sex",
+	"birthday": "birth",
+}
+
+var ActorResource = &Resource{
+	Name:          "actors",
+	Fields:        ActorFields,
+	Columns:       actorColumns,
+	Relation:      ExpandFilms,
+	Nested:        FilmSummaryFields,
+	NestedColumns: filmColumns,
+}
+
+// ActorSummary is a short actor shape used inside other entities.
+type ActorSummary struct {
+	ID    int64     `json:"id"`
+	Name  string    `json:"name"`
+	Sex   string    `json:"sex"`
+	Birth time.Time `json:"birthday"`
+
+	fields Fields
+}
+
+func (a *ActorSummary) MarshalJSON() ([]byte, error) {
+	type actor ActorSummary
+	return a.fields.marshal((*actor)(a))
+}
+
+// Actor is a detailed actor shape, films are present only when expanded.
+type Actor struct {
+	ID    int64          `json:"id"`
+	Name  string         `json:"name"`
+	Sex   string         `json:"sex"`
+	Birth time.Time      `json:"birthday"`
+	Films []*FilmSummary `json:"films,omitempty"`
+	// ExternalIDs maps source to the actor id in it.
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+
+	fields Fields
+}
+
+func (a *Actor) MarshalJSON() ([]byte, error) {
+	type actor Actor
+	return a.fields.marshal((*actor)(a))
+}
+
+func NewActorSummary(actor *db_models.Actor, fields Fields) *ActorSummary {
+	return &ActorSummary{
+		ID:     actor.ID,
+		Name:   actor.Name,
+		Sex:    actor.Sex,
+		Birth:  actor.Birth,
+		fields: fields,
+	}
+}
+
+func NewActor(actor *db_models.Actor, view *View) *Actor {
+	res := &Actor{
+		ID:     actor.ID,
+		Name:   actor.Name,
+		Sex:    actor.Sex,
+		Birth:  actor.Birth,
+		fields: view.Fields,
+	}
+	for _, id := range actor.ExternalIDs {
+		if res.ExternalIDs == nil {
+			res.ExternalIDs = make(map[string]string, len(actor.ExternalIDs))
+		}
+		res.ExternalIDs[id.Source] = id.Value
+	}
+	if view.Expand[ExpandFilms] {
+		res.Films = make([]*FilmSummary, 0, len(actor.Films))
+		for i := range actor.Films {
+			res.Films = append(res.Films, NewFilmSummary(&actor.Films[i], view.Nested))
+		}
+	}
+	return res
+}
+
+func NewActors(actors []*db_models.Actor, view *View) []*Actor {
+	res := make([]*Actor, 0, len(actors))
+	for _, actor := range actors {
+		res = append(res, NewActor(actor, view))
+	}
+	return res
+}
 
 type ActorsResponse struct {
-	Success bool               `json:"success"`
-	Error   string             `json:"error,omitempty"`
-	Actors  []*db_models.Actor `json:"actors,omitempty"`
+	Success bool     `json:"success"`
+	Error   string   `json:"error,omitempty"`
+	Actors  []*Actor `json:"actors,omitempty"`
 }
 
 type ActorResponse struct {
-	Success bool             `json:"success"`
-	Error   string           `json:"error,omitempty"`
-	Actor   *db_models.Actor `json:"actor,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Actor   *Actor `json:"actor,omitempty"`
 }
 
 type CreateActorRequest struct {
 	Name  string `json:"name"`
 	Sex   string `json:"sex" validate:"oneof=male female"`
-	Birth string `json:"birth"`
+	Birth string `json:"birth" validate:"date"`
 }
 
 type UpdateActorRequest struct {
 	Name  string `json:"name,omitempty"`
 	Sex   string `json:"sex,omitempty" validate:"omitempty,oneof=male female"`
-	Birth string `json:"birth,omitempty"`
+	Birth string `json:"birth,omitempty" validate:"omitempty,date"`
+}
+
+// UpsertActorRequest replaces all fields of the actor.
+type UpsertActorRequest struct {
+	Name        string            `json:"name" validate:"min=1,max=150"`
+	Sex         string            `json:"sex" validate:"oneof=male female"`
+	Birth       string            `json:"birth" validate:"date"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty" validate:"omitempty,dive,keys,source,endkeys,required,max=64"`
+}
+
+type FilmographyYear struct {
+	Year  int                           `json:"year"`
+	Films []*db_models.FilmographyEntry `json:"films"`
+}
+
+type FilmographyResponse struct {
+	Success bool                          `json:"success"`
+	Error   string                        `json:"error,omitempty"`
+	Films   []*db_models.FilmographyEntry `json:"films,omitempty"`
+	Years   []*FilmographyYear            `json:"years,omitempty"`
+}
+
+type CostarsResponse struct {
+	Success bool                `json:"success"`
+	Error   string              `json:"error,omitempty"`
+	Costars []*db_models.Costar `json:"costars,omitempty"`
+}
+
+type CostarPathResponse struct {
+	Success bool                  `json:"success"`
+	Error   string                `json:"error,omitempty"`
+	Degrees int                   `json:"degrees"`
+	Path    []*db_models.PathLink `json:"path,omitempty"`
 }
@@ -0,0 +1,9 @@
+package api_models
+
+import db_models "filmoteka/db"
+
+type AutocompleteResponse struct {
+	Success     bool                    `json:"success"`
+	Error       string                  `json:"error,omitempty"`
+	Suggestions []*db_models.Suggestion `json:"suggestions,omitempty"`
+}
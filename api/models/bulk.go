@@ -0,0 +1,55 @@
+package api_models
+
+import (
+	"encoding/json"
+	"filmoteka/errs"
+)
+
+const (
+	BulkCreate = "create"
+	BulkUpdate = "update"
+	BulkDelete = "delete"
+
+	// BulkTransaction applies all operations or none of them.
+	BulkTransaction = "transaction"
+	// BulkBestEffort applies every operation separately.
+	BulkBestEffort = "best_effort"
+)
+
+// BulkOperation is one create, update or delete, data holds the same
+// body as single item endpoints accept.
+type BulkOperation struct {
+	Op   string          `json:"op" validate:"oneof=create update delete"`
+	ID   int64           `json:"id,omitempty" validate:"required_unless=Op create"`
+	Data json.RawMessage `json:"data,omitempty" swaggertype:"object" validate:"required_unless=Op delete"`
+}
+
+type BulkRequest struct {
+	Mode       string           `json:"mode,omitempty" validate:"omitempty,oneof=transaction best_effort"`
+	Operations []*BulkOperation `json:"operations" validate:"required,min=1,max=1000,dive"`
+}
+
+type BulkError struct {
+	Code    string             `json:"code"`
+	Message string             `json:"message"`
+	Errors  []*errs.FieldError `json:"errors,omitempty"`
+}
+
+// BulkResult reports outcome of the operation with the same index,
+// status is HTTP status code the single item endpoint would return.
+type BulkResult struct {
+	Index  int        `json:"index"`
+	Op     string     `json:"op"`
+	Status int        `json:"status"`
+	ID     int64      `json:"id,omitempty"`
+	Film   *Film      `json:"film,omitempty"`
+	Actor  *Actor     `json:"actor,omitempty"`
+	Error  *BulkError `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Success bool          `json:"success"`
+	Error   string        `json:"error,omitempty"`
+	Mode    string        `json:"mode"`
+	Results []*BulkResult `json:"results"`
+}
@@ -2,32 +2,167 @@ package api_models
 
 import (
 	db_models "filmoteka/db"
+	"time"
 )
 
+const ExpandActors = "actors"
+
+// FilmFields are JSON keys of Film which can be selected with fields param.
+var FilmFields = []string{"id", "name", "description", "date", "rate", "actors", FieldExternalIDs}
+
+// FilmSummaryFields are JSON keys of FilmSummary nested into actors.
+var FilmSummaryFields = []string{"id", "name", "date", "rate"}
+
+var filmColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"description": "description",
+	"date":        "date",
+	"rate":        "rate",
+}
+
+var FilmResource = &Resource{
+	Name:          "films",
+	Fields:        FilmFields,
+	Columns:       filmColumns,
+	Relation:      ExpandActors,
+	Nested:        ActorSummaryFields,
+	NestedColumns: actorColumns,
+}
+
+// FilmSummary is a short film shape used inside other entities.
+type FilmSummary struct {
+	ID   int       `json:"id"`
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+	Rate int       `json:"rate"`
+
+	fields Fields
+}
+
+func (f *FilmSummary) MarshalJSON() ([]byte, error) {
+	type film FilmSummary
+	return f.fields.marshal((*film)(f))
+}
+
+// Film is a detailed film shape, actors are present only when expanded.
+type Film struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Date        time.Time       `json:"date"`
+	Rate        int             `json:"rate"`
+	Actors      []*ActorSummary `json:"actors,omitempty"`
+	// ExternalIDs maps source to the film id in it.
+	ExternalIDs map[string]string `json:"external_ids,omitempty" example:"imdb:tt0133093"`
+
+	fields Fields
+}
+
+func (f *Film) MarshalJSON() ([]byte, error) {
+	type film Film
+	return f.fields.marshal((*film)(f))
+}
+
+func NewFilmSummary(film *db_models.Film, fields Fields) *FilmSummary {
+	return &FilmSummary{
+		ID:     film.ID,
+		Name:   film.Name,
+		Date:   film.Date,
+		Rate:   film.Rate,
+		fields: fields,
+	}
+}
+
+func NewFilm(film *db_models.Film, view *View) *Film {
+	res := &Film{
+		ID:          film.ID,
+		Name:        film.Name,
+		Description: film.Description,
+		Date:        film.Date,
+		Rate:        film.Rate,
+		fields:      view.Fields,
+	}
+	for _, id := range film.ExternalIDs {
+		if res.ExternalIDs == nil {
+			res.ExternalIDs = make(map[string]string, len(film.ExternalIDs))
+		}
+		res.ExternalIDs[id.Source] = id.Value
+	}
+	if view.Expand[ExpandActors] {
+		res.Actors = make([]*ActorSummary, 0, len(film.Actors))
+		for i := range film.Actors {
+			res.Actors = append(res.Actors, NewActorSummary(&film.Actors[i], view.Nested))
+		}
+	}
+	return res
+}
+
+func NewFilms(films []*db_models.Film, view *View) []*Film {
+	res := make([]*Film, 0, len(films))
+	for _, film := range films {
+		res = append(res, NewFilm(film, view))
+	}
+	return res
+}
+
 type FilmsResponse struct {
-	Success bool              `json:"success"`
-	Error   string            `json:"error,omitempty"`
-	Films   []*db_models.Film `json:"film,omitempty"`
+	Success bool    `json:"success"`
+	Error   string  `json:"error,omitempty"`
+	Films   []*Film `json:"films,omitempty"`
+}
+
+// FilmsResponseV1 is a films list of API v1, its key stays "film" for
+// existing clients.
+type FilmsResponseV1 struct {
+	Success bool    `json:"success"`
+	Error   string  `json:"error,omitempty"`
+	Films   []*Film `json:"film,omitempty"`
 }
 
 type FilmResponse struct {
-	Success bool            `json:"success"`
-	Error   string          `json:"error,omitempty"`
-	Film    *db_models.Film `json:"film,omitempty"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	Film    *Film  `json:"film,omitempty"`
 }
 
 type CreateFilmRequest struct {
-	Name        string `json:"name" validate:"min=1,max=150"`
-	Description string `json:"description" validate:"max=1000"`
-	Date        string `json:"date"`
-	Rate        int    `json:"rate" validate:"gte=0,lte=10"`
-	Actors      []int  `json:"actors"`
+	Name        string         `json:"name" validate:"min=1,max=150"`
+	Description string         `json:"description" validate:"max=1000"`
+	Date        string         `json:"date" validate:"date"`
+	Rate        int            `json:"rate" validate:"gte=0,lte=10"`
+	Actors      []int          `json:"actors"`
+	Roles       map[int]string `json:"roles,omitempty"`
 }
 
 type UpdateFilmRequest struct {
 	Name        string `json:"name,omitempty" validate:"omitempty,min=1,max=150"`
 	Description string `json:"description,omitempty" validate:"omitempty,max=1000"`
-	Date        string `json:"date,omitempty"`
+	Date        string `json:"date,omitempty" validate:"omitempty,date"`
 	Rate        int    `json:"rate,omitempty" validate:"omitempty,gte=0,lte=10"`
 	Actors      []int  `json:"actors,omitempty"`
 }
+
+// ExternalRef points to an entity by its id in an external catalogue.
+type ExternalRef struct {
+	Source string `json:"source" validate:"required,source" example:"imdb"`
+	ID     string `json:"id" validate:"required,max=64" example:"nm0000206"`
+}
+
+// CastMember points to an actor by our or external id.
+type CastMember struct {
+	ActorID  int64        `json:"actor_id,omitempty" validate:"required_without=External"`
+	External *ExternalRef `json:"external,omitempty"`
+	Role     string       `json:"role,omitempty" validate:"max=150"`
+}
+
+// UpsertFilmRequest replaces all fields of the film, cast is left as is
+// when omitted and cleared when empty.
+type UpsertFilmRequest struct {
+	Name        string            `json:"name" validate:"min=1,max=150"`
+	Description string            `json:"description" validate:"max=1000"`
+	Date        string            `json:"date" validate:"date"`
+	Rate        int               `json:"rate" validate:"gte=0,lte=10"`
+	Cast        []*CastMember     `json:"cast" validate:"omitempty,dive"`
+	ExternalIDs map[string]string `json:"external_ids,omitempty" validate:"omitempty,dive,keys,source,endkeys,required,max=64"`
+}
@@ -0,0 +1,9 @@
+package api_models
+
+import "filmoteka/importer"
+
+type ImportJobResponse struct {
+	Success bool          `json:"success"`
+	Error   string        `json:"error,omitempty"`
+	Job     *importer.Job `json:"job,omitempty"`
+}
@@ -0,0 +1,9 @@
+package api_models
+
+import db_models "filmoteka/db"
+
+type SearchResponse struct {
+	Success bool                      `json:"success"`
+	Error   string                    `json:"error,omitempty"`
+	Results []*db_models.SearchResult `json:"results,omitempty"`
+}
@@ -0,0 +1,13 @@
+package api_models
+
+// User is the authenticated user, available since API v2.
+type User struct {
+	Username string `json:"username" example:"admin"`
+	Role     string `json:"role" enums:"admin,client" example:"admin"`
+}
+
+type UserResponse struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+	User    *User  `json:"user,omitempty"`
+}
@@ -0,0 +1,131 @@
+package api_models
+
+import (
+	"encoding/json"
+	db_models "filmoteka/db"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldExternalIDs is a field of films and actors loaded from its own table.
+const FieldExternalIDs = "external_ids"
+
+// Expand lists relations which are nested into response items.
+type Expand map[string]bool
+
+// Fields restricts response items to selected JSON keys, nil keeps all of them.
+type Fields map[string]bool
+
+// Resource describes JSON fields of an entity and its relation
+// which can be requested with include and fields params.
+type Resource struct {
+	Name     string
+	Fields   []string
+	Columns  map[string]string
+	Relation string
+	Nested   []string
+	// NestedColumns maps fields of relation items to table columns.
+	NestedColumns map[string]string
+}
+
+// View is a shape of response items requested by the client.
+type View struct {
+	Expand Expand
+	Fields Fields
+	Nested Fields
+}
+
+// Selection converts view into columns and relations queried from db.
+func (v *View) Selection(resource *Resource) *db_models.Selection {
+	return &db_models.Selection{
+		Columns:         columns(v.Fields, resource.Columns),
+		Relation:        v.Expand[resource.Relation],
+		RelationColumns: columns(v.Nested, resource.NestedColumns),
+		ExternalIDs:     v.Fields == nil || v.Fields[FieldExternalIDs],
+	}
+}
+
+func columns(fields Fields, mapping map[string]string) []string {
+	if fields == nil {
+		return nil
+	}
+	columns := make([]string, 0, len(fields))
+	for field := range fields {
+		if column, ok := mapping[field]; ok {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		columns = append(columns, "id")
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// ParseExpand reads comma separated relations, only allowed ones are accepted.
+func ParseExpand(param string, allowed ...string) (Expand, error) {
+	expand := Expand{}
+	for _, name := range splitParam(param) {
+		if !contains(allowed, name) {
+			return nil, fmt.Errorf("can not expand %q, allowed: %s", name, strings.Join(allowed, ", "))
+		}
+		expand[name] = true
+	}
+	return expand, nil
+}
+
+// ParseFields reads comma separated field names, only allowed ones are accepted.
+func ParseFields(param string, allowed ...string) (Fields, error) {
+	names := splitParam(param)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	fields := Fields{}
+	for _, name := range names {
+		if !contains(allowed, name) {
+			return nil, fmt.Errorf("unknown field %q, allowed: %s", name, strings.Join(allowed, ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+func (f Fields) marshal(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || f == nil {
+		return data, err
+	}
+
+	object := make(map[string]json.RawMessage)
+	err = json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, err
+	}
+	for key := range object {
+		if !f[key] {
+			delete(object, key)
+		}
+	}
+	return json.Marshal(object)
+}
+
+func splitParam(param string) []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package api_models
+
+import db_models "filmoteka/db"
+
+// WebhookRequest subscribes url to events, empty events mean all of them.
+// Secret is generated when omitted, active is true when omitted.
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url,max=2048" example:"https://example.com/hooks/filmoteka"`
+	Events []string `json:"events" validate:"omitempty,dive,event" example:"film.created,actor.*"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Active *bool    `json:"active,omitempty"`
+}
+
+type WebhookResponse struct {
+	Success bool               `json:"success"`
+	Error   string             `json:"error,omitempty"`
+	Webhook *db_models.Webhook `json:"webhook,omitempty"`
+	// Secret is returned only when the webhook is created or the secret changed.
+	Secret string `json:"secret,omitempty"`
+}
+
+type WebhooksResponse struct {
+	Success  bool                 `json:"success"`
+	Error    string               `json:"error,omitempty"`
+	Webhooks []*db_models.Webhook `json:"webhooks"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Success    bool                         `json:"success"`
+	Error      string                       `json:"error,omitempty"`
+	Deliveries []*db_models.WebhookDelivery `json:"deliveries"`
+}
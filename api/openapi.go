@@ -0,0 +1,235 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"filmoteka/config"
+	"filmoteka/docs"
+	"filmoteka/errs"
+	"filmoteka/openapi"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// filmFilterFields can be used in filter param of films lists.
+var filmFilterFields = []string{"name", "description"}
+
+// specFormats check string formats used by the API docs.
+var specFormats = map[string]func(string) bool{
+	"film-filter": func(value string) bool {
+		field, _, found := strings.Cut(value, ".")
+		for _, allowed := range filmFilterFields {
+			if found && field == allowed {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// boundRules of body values are left to handlers, they check them after
+// authorization so users without access are forbidden before that.
+var boundRules = map[string]bool{
+	openapi.RuleMinimum:   true,
+	openapi.RuleMaximum:   true,
+	openapi.RuleMinLength: true,
+	openapi.RuleMaxLength: true,
+}
+
+// loadSpec parses the generated docs served at /swagger/doc.json.
+func loadSpec() *openapi.Spec {
+	spec, err := openapi.Parse([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		panic(err)
+	}
+	for format, check := range specFormats {
+		spec.Formats[format] = check
+	}
+	return spec
+}
+
+// validateRequests rejects requests with params or JSON body not matching
+// the OpenAPI document before they reach handlers, bodies are checked for
+// structure only. Responses are checked when it is enabled in cfg,
+// mismatches are only logged.
+func validateRequests(spec *openapi.Spec, cfg config.OpenAPI) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Versions differ in responses, requests are the same.
+			version, path := requestVersion(r)
+			operation, pathParams := spec.Find(r.Method, path)
+			if operation == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if cfg.ValidateRequests {
+				err := checkRequest(spec, operation, pathParams, r, cfg.MaxBodySize)
+				if err != nil {
+					HandleError(w, r, err)
+					return
+				}
+			}
+			// Only the latest version is documented.
+			if !cfg.ValidateResponses || version != latestVersion {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			body := &limitedBuffer{limit: cfg.MaxBodySize}
+			ww.Tee(body)
+			next.ServeHTTP(ww, r)
+			checkResponse(spec, operation, r, ww, body)
+		})
+	}
+}
+
+// checkRequest validates params and replaces the JSON body with the read one.
+func checkRequest(spec *openapi.Spec, operation *openapi.Operation, pathParams map[string]string, r *http.Request, maxBodySize int64) error {
+	query := r.URL.Query()
+	problems := make([]*openapi.Problem, 0)
+	for _, param := range operation.Parameters {
+		var values []string
+		switch param.In {
+		case "path":
+			values = []string{pathParams[param.Name]}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			values = r.Header.Values(param.Name)
+		default:
+			continue
+		}
+		problems = append(problems, spec.ValidateParam(param, values)...)
+	}
+	if len(problems) > 0 {
+		e := specError(r, problems)
+		e.Kind = errs.KindBadRequest
+		e.Code = errs.CodeInvalidParam
+		return e
+	}
+
+	param := operation.BodyParam()
+	if param == nil || !isJSONBody(spec, param, r) {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return errs.Wrap(errs.KindBadRequest, errs.CodeBadRequest, "can not read request body", err)
+	}
+	if int64(len(body)) > maxBodySize {
+		return &errs.Error{Kind: errs.KindTooLarge, Code: errs.CodePayloadTooLarge, Message: "request body is larger than " + strconv.FormatInt(maxBodySize, 10) + " bytes"}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	var value interface{}
+	err = json.Unmarshal(body, &value)
+	if err != nil {
+		return errs.Wrap(errs.KindBadRequest, errs.CodeInvalidJSON, "request body is not valid JSON", err)
+	}
+	problems = make([]*openapi.Problem, 0)
+	for _, problem := range spec.Validate(param.Body, value, "") {
+		if !boundRules[problem.Rule] {
+			problems = append(problems, problem)
+		}
+	}
+	if len(problems) > 0 {
+		return specError(r, problems)
+	}
+	return nil
+}
+
+// isJSONBody is true for bodies of object and array schemas sent as JSON,
+// requests without Content-Type are decoded as JSON by handlers too.
+func isJSONBody(spec *openapi.Spec, param *openapi.Parameter, r *http.Request) bool {
+	schema := spec.Resolve(param.Body)
+	if schema == nil || (schema.Type != "object" && schema.Type != "array") {
+		return false
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && isJSONMediaType(mediaType)
+}
+
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// specError converts problems into field errors with messages in the
+// language of the request.
+func specError(r *http.Request, problems []*openapi.Problem) *errs.Error {
+	trans := translator(r)
+	fields := make([]*errs.FieldError, 0, len(problems))
+	for _, problem := range problems {
+		message, err := trans.T(specMessageKey(problem.Rule), problem.Path, problem.Param)
+		if err != nil {
+			message = problem.Error()
+		}
+		fields = append(fields, &errs.FieldError{
+			Field:   problem.Path,
+			Rule:    problem.Rule,
+			Param:   problem.Param,
+			Message: message,
+		})
+	}
+	message, _ := trans.T(requestInvalid)
+	return errs.Validation(message, fields...)
+}
+
+// checkResponse logs responses with undocumented status or JSON body not
+// matching the schema of the status.
+func checkResponse(spec *openapi.Spec, operation *openapi.Operation, r *http.Request, ww middleware.WrapResponseWriter, body *limitedBuffer) {
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	log := slog.With("method", r.Method, "path", r.URL.Path, "status", status)
+
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		log.Error("response status is not documented")
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(ww.Header().Get("Content-Type"))
+	if response == nil || response.Schema == nil || !isJSONMediaType(mediaType) || body.truncated {
+		return
+	}
+
+	var value interface{}
+	err := json.Unmarshal(body.Bytes(), &value)
+	if err != nil {
+		log.Error("response body is not valid JSON", "err", err)
+		return
+	}
+	problems := spec.ValidateStrict(response.Schema, value, "")
+	if len(problems) > 0 {
+		log.Error("response body does not match the OpenAPI document", "problems", problems)
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it, long responses
+// like event streams are not validated.
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.truncated || int64(b.Len()+len(p)) > b.limit {
+		b.truncated = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"filmoteka/config"
+	"filmoteka/errs"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	// bucketPurgeInterval is how often buckets of idle clients are dropped.
+	bucketPurgeInterval = time.Minute
+	quotaWindow         = 24 * time.Hour
+)
+
+// rateLimitExempt paths are probed by infrastructure, not by clients.
+var rateLimitExempt = map[string]bool{
+	"/healthcheck": true,
+	"/livez":       true,
+	"/readyz":      true,
+}
+
+// tokenBuckets give each client burst tokens refilled at rate per second,
+// a request takes one.
+type tokenBuckets struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBuckets(rate float64, burst int) *tokenBuckets {
+	return &tokenBuckets{rate: rate, burst: burst, buckets: map[string]*bucket{}}
+}
+
+// take reports whether the client had a token, how many are left, when
+// the next one comes and when the bucket is full again.
+func (b *tokenBuckets) take(client string, now time.Time) (bool, int, time.Duration, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bk, ok := b.buckets[client]
+	if !ok {
+		bk = &bucket{tokens: float64(b.burst), updated: now}
+		b.buckets[client] = bk
+	}
+	bk.tokens = math.Min(float64(b.burst), bk.tokens+now.Sub(bk.updated).Seconds()*b.rate)
+	bk.updated = now
+
+	taken := bk.tokens >= 1
+	if taken {
+		bk.tokens--
+	}
+	retryAfter := time.Duration(0)
+	if !taken {
+		retryAfter = b.refill(1 - bk.tokens)
+	}
+	return taken, int(bk.tokens), retryAfter, b.refill(float64(b.burst) - bk.tokens)
+}
+
+// give returns a token taken from the client, a bucket dropped meanwhile
+// is full already.
+func (b *tokenBuckets) give(client string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bk, ok := b.buckets[client]; ok {
+		bk.tokens = math.Min(float64(b.burst), bk.tokens+1)
+	}
+}
+
+// window is how long an empty bucket takes to be full.
+func (b *tokenBuckets) window() time.Duration {
+	return b.refill(float64(b.burst))
+}
+
+func (b *tokenBuckets) refill(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+// purge drops full buckets, a new bucket of the client is full too.
+func (b *tokenBuckets) purge(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for client, bk := range b.buckets {
+		if bk.tokens+now.Sub(bk.updated).Seconds()*b.rate >= float64(b.burst) {
+			delete(b.buckets, client)
+		}
+	}
+}
+
+// dailyQuotas count requests of API keys during a UTC day.
+type dailyQuotas struct {
+	mu   sync.Mutex
+	day  time.Time
+	used map[string]int64
+}
+
+// use reports whether the key was under its quota, how many requests are
+// left and when the quota is renewed.
+func (q *dailyQuotas) use(key string, quota int64, now time.Time) (bool, int64, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	day := now.UTC().Truncate(quotaWindow)
+	if !day.Equal(q.day) {
+		q.day, q.used = day, map[string]int64{}
+	}
+	reset := day.Add(quotaWindow).Sub(now)
+	if q.used[key] >= quota {
+		return false, 0, reset
+	}
+	q.used[key]++
+	return true, quota - q.used[key], reset
+}
+
+// rateLimiter keeps buckets and quotas of clients, buckets of idle
+// clients are dropped by purge.
+type rateLimiter struct {
+	cfg    config.RateLimit
+	reads  *tokenBuckets
+	writes *tokenBuckets
+	quotas *dailyQuotas
+}
+
+func newRateLimiter(cfg config.RateLimit) *rateLimiter {
+	return &rateLimiter{
+		cfg:    cfg,
+		reads:  newTokenBuckets(cfg.ReadRate, cfg.ReadBurst),
+		writes: newTokenBuckets(cfg.WriteRate, cfg.WriteBurst),
+		quotas: &dailyQuotas{},
+	}
+}
+
+// purge drops buckets of idle clients.
+func (l *rateLimiter) purge(now time.Time) {
+	l.reads.purge(now)
+	l.writes.purge(now)
+}
+
+// limit rejects clients going over the limit of read or write requests,
+// and API keys over their daily quota, with 429. Limits are described by
+// RateLimit headers of the IETF draft.
+func (l *rateLimiter) limit(next http.Handler) http.Handler {
+	if !l.cfg.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, path := requestVersion(r)
+		if rateLimitExempt[path] || strings.HasPrefix(path, "/swagger/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		client, apiKey := rateLimitClient(r, l.cfg)
+		limits := l.writes
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			limits = l.reads
+		}
+		policy := strconv.Itoa(limits.burst) + ";w=" + seconds(limits.window())
+
+		now := time.Now()
+		taken, remaining, retryAfter, reset := limits.take(client, now)
+		if !taken {
+			rejectRateLimited(w, r, limits, remaining, retryAfter, reset, policy)
+			return
+		}
+		// Credentials are looked up only for requests the IP may still
+		// make, a verified user is then charged instead of the IP. Failed
+		// logins stay on the IP, so claimed usernames neither drain the
+		// bucket of their owner nor get a fresh one.
+		if apiKey == "" {
+			r = verifyCredentials(r)
+			if username, ok := verifiedUser(r); ok {
+				limits.give(client)
+				taken, remaining, retryAfter, reset = limits.take("user:"+username, now)
+				if !taken {
+					rejectRateLimited(w, r, limits, remaining, retryAfter, reset, policy)
+					return
+				}
+			}
+		}
+
+		quota := l.cfg.APIKeys[apiKey]
+		if quota <= 0 {
+			setRateLimit(w, limits.burst, remaining, reset, policy)
+			next.ServeHTTP(w, verifyCredentials(r))
+			return
+		}
+		policy += ", " + strconv.FormatInt(quota, 10) + ";w=" + seconds(quotaWindow)
+		used, left, renewal := l.quotas.use(apiKey, quota, now)
+		// Headers describe the limit closest to be exhausted.
+		if !used || left < int64(remaining) {
+			setRateLimit(w, int(quota), int(left), renewal, policy)
+		} else {
+			setRateLimit(w, limits.burst, remaining, reset, policy)
+		}
+		if !used {
+			w.Header().Set("Retry-After", seconds(renewal))
+			HandleError(w, r, errs.Wrap(errs.KindTooManyRequests, errs.CodeQuotaExceeded, "daily quota of the API key is exhausted", nil))
+			return
+		}
+		next.ServeHTTP(w, verifyCredentials(r))
+	})
+}
+
+func rejectRateLimited(w http.ResponseWriter, r *http.Request, limits *tokenBuckets, remaining int, retryAfter, reset time.Duration, policy string) {
+	setRateLimit(w, limits.burst, remaining, reset, policy)
+	w.Header().Set("Retry-After", seconds(retryAfter))
+	HandleError(w, r, errs.Wrap(errs.KindTooManyRequests, errs.CodeRateLimited, "too many requests, retry in "+seconds(retryAfter)+" seconds", nil))
+}
+
+// rateLimitClient identifies the client by an API key from config or by
+// IP. Users are told apart only once their credentials are verified.
+func rateLimitClient(r *http.Request, cfg config.RateLimit) (string, string) {
+	apiKey := r.Header.Get(apiKeyHeader)
+	if _, ok := cfg.APIKeys[apiKey]; ok && apiKey != "" {
+		return "key:" + apiKey, apiKey
+	}
+
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip, ""
+}
+
+// verifiedUser is the username of credentials verified by verifyCredentials.
+func verifiedUser(r *http.Request) (string, bool) {
+	if _, ok := r.Context().Value("Role").(string); !ok {
+		return "", false
+	}
+	username, _, _ := r.BasicAuth()
+	return username, true
+}
+
+// verifyCredentials looks up credentials of an allowed request once, the
+// role or the error is kept for handlers and later calls.
+func verifyCredentials(r *http.Request) *http.Request {
+	if _, _, ok := r.BasicAuth(); !ok {
+		return r
+	}
+	role, err := checkBasicAuth(r)
+	if err != nil {
+		return r.WithContext(context.WithValue(r.Context(), "AuthError", err))
+	}
+	return r.WithContext(context.WithValue(r.Context(), "Role", role))
+}
+
+func setRateLimit(w http.ResponseWriter, limit int, remaining int, reset time.Duration, policy string) {
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("RateLimit-Reset", seconds(reset))
+	w.Header().Set("RateLimit-Policy", policy)
+}
+
+// seconds rounds up, clients waiting less would be rejected again.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
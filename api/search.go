@@ -0,0 +1,97 @@
+package api
+
+import (
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+var searchLanguages = map[string]string{
+	"ru": db.LangRussian,
+	"en": db.LangEnglish,
+}
+
+// search godoc
+// @Summary      Full text search
+// @Description  Availible only for authenticated user, searching films by name and description and actors by name. Results are ranked by relevance and contain highlighted snippets.
+// @Tags         search
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /search [get]
+// @Param q query string true "Search query, supports quotes, or and -minus" example(matrix)
+// @Param type query string false "Result type: film or actor, default both" Enums(film, actor)
+// @Param lang query string false "Query language: ru or en, detected by alphabet by default" Enums(ru, en)
+// @Param limit query int false "Max number of results, default 20, max 100"
+// @Security BasicAuth
+// @Success 200 {object} api_models.SearchResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func search(w http.ResponseWriter, r *http.Request) {
+	_, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param q is required"))
+		return
+	}
+
+	searchType := r.URL.Query().Get("type")
+	if searchType != "" && searchType != db.SearchFilm && searchType != db.SearchActor {
+		HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param type must be film or actor"))
+		return
+	}
+
+	lang := db.SearchLanguage(query)
+	if param := r.URL.Query().Get("lang"); param != "" {
+		var ok bool
+		lang, ok = searchLanguages[param]
+		if !ok {
+			HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param lang must be ru or en"))
+			return
+		}
+	}
+
+	limit := defaultSearchLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		limit, err = strconv.Atoi(param)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param limit must be between 1 and 100"))
+			return
+		}
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	results, err := db.Search(pgdb, query, lang, searchType, limit)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	res := &api_models.SearchResponse{
+		Success: true,
+		Error:   "",
+		Results: results,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
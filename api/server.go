@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// WithShutdown makes requests served with ctx see the shutdown of the
+// server when done is closed, streams end so clients reconnect to another
+// instance. It is meant for BaseContext of http.Server.
+func WithShutdown(ctx context.Context, done <-chan struct{}) context.Context {
+	return context.WithValue(ctx, "Shutdown", done)
+}
+
+// shutdown is closed when the server stops, it is nil for servers which
+// do not announce it.
+func shutdown(r *http.Request) <-chan struct{} {
+	done, _ := r.Context().Value("Shutdown").(<-chan struct{})
+	return done
+}
+
+// liftWriteDeadline lets responses which last until the client leaves or
+// data ends, like event streams and exports, outlive the write timeout.
+func liftWriteDeadline(w http.ResponseWriter) {
+	err := http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Error("can not lift write deadline", "err", err)
+	}
+}
+
+// liftReadDeadline lets large request bodies outlive the read timeout.
+func liftReadDeadline(w http.ResponseWriter) {
+	err := http.NewResponseController(w).SetReadDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.Error("can not lift read deadline", "err", err)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	api_models "filmoteka/api/models"
+	"net/http"
+)
+
+// getCurrentUser godoc
+// @Summary      Get current user
+// @Description  Availible only for authenticated user since API v2, getting username and role of the user
+// @Tags         users
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /users/me [get]
+// @Security BasicAuth
+// @Success 200 {object} api_models.UserResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse "requested with API v1"
+func getCurrentUser(w http.ResponseWriter, r *http.Request) {
+	role, err := checkBasicAuth(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+	username, _, _ := r.BasicAuth()
+
+	res := &api_models.UserResponse{
+		Success: true,
+		User:    &api_models.User{Username: username, Role: role},
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
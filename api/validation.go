@@ -0,0 +1,222 @@
+package api
+
+import (
+	"errors"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"filmoteka/openapi"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ru_translations "github.com/go-playground/validator/v10/translations/ru"
+	"golang.org/x/text/language"
+)
+
+// requestInvalid is a translation key of validation error summary.
+const requestInvalid = "request_invalid"
+
+var Validate *validator.Validate
+
+// translators holds validation messages in english (default) and russian.
+var translators *ut.UniversalTranslator
+
+// custom translations: summary of the error and rules added by the api
+var messages = map[string]map[string]string{
+	"en": {
+		requestInvalid: "request is not valid",
+		"date":         "{0} must be a date in YYYY-MM-DD format",
+		"source":       "{0} must be one of " + strings.Join(db.ExternalSources, ", "),
+		"event":        "{0} must be an event type like film.created, film.* or *",
+		"http_url":     "{0} must be an http or https URL",
+	},
+	"ru": {
+		requestInvalid: "запрос содержит ошибки",
+		"date":         "{0} должно быть датой в формате ГГГГ-ММ-ДД",
+		"source":       "{0} должно быть одним из " + strings.Join(db.ExternalSources, ", "),
+		"event":        "{0} должно быть типом события вида film.created, film.* или *",
+		"http_url":     "{0} должно быть http или https URL",
+	},
+}
+
+// specMessages explain problems of requests not matching the OpenAPI
+// document, they are keyed by problem rules.
+var specMessages = map[string]map[string]string{
+	"en": {
+		openapi.RuleRequired:  "{0} is required",
+		openapi.RuleType:      "{0} must be of type {1}",
+		openapi.RuleEnum:      "{0} must be one of: {1}",
+		openapi.RuleFormat:    "{0} must be in {1} format",
+		openapi.RuleMinimum:   "{0} must be {1} or greater",
+		openapi.RuleMaximum:   "{0} must be {1} or less",
+		openapi.RuleMinLength: "{0} must be at least {1} characters long",
+		openapi.RuleMaxLength: "{0} must be at most {1} characters long",
+	},
+	"ru": {
+		openapi.RuleRequired:  "{0} обязательное поле",
+		openapi.RuleType:      "{0} должно иметь тип {1}",
+		openapi.RuleEnum:      "{0} должно быть одним из: {1}",
+		openapi.RuleFormat:    "{0} должно быть в формате {1}",
+		openapi.RuleMinimum:   "{0} должно быть не меньше {1}",
+		openapi.RuleMaximum:   "{0} должно быть не больше {1}",
+		openapi.RuleMinLength: "{0} должно содержать не меньше {1} символов",
+		openapi.RuleMaxLength: "{0} должно содержать не больше {1} символов",
+	},
+}
+
+// rules are validations added by the api, each has a message in messages.
+// Messages without a rule replace missing translations of built in rules.
+var rules = map[string]validator.Func{
+	"date":   validateDate,
+	"source": validateSource,
+	"event":  validateEvent,
+}
+
+func init() {
+	english := en.New()
+	translators = ut.New(english, english, ru.New())
+
+	Validate = validator.New()
+	Validate.RegisterTagNameFunc(jsonFieldName)
+	for tag, rule := range rules {
+		err := Validate.RegisterValidation(tag, rule)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	registers := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"ru": ru_translations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registers {
+		trans, _ := translators.GetTranslator(locale)
+		err := register(Validate, trans)
+		if err != nil {
+			panic(err)
+		}
+		err = trans.Add(requestInvalid, messages[locale][requestInvalid], false)
+		if err != nil {
+			panic(err)
+		}
+		for rule, message := range specMessages[locale] {
+			err = trans.Add(specMessageKey(rule), message, false)
+			if err != nil {
+				panic(err)
+			}
+		}
+		for tag, message := range messages[locale] {
+			if tag == requestInvalid {
+				continue
+			}
+			err = Validate.RegisterTranslation(tag, trans, registerMessage(tag, message), translateField)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// jsonFieldName reports fields by their JSON names instead of Go ones.
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func validateDate(fl validator.FieldLevel) bool {
+	_, err := time.Parse(db.DateLayout, fl.Field().String())
+	return err == nil
+}
+
+func validateSource(fl validator.FieldLevel) bool {
+	return db.IsExternalSource(fl.Field().String())
+}
+
+func validateEvent(fl validator.FieldLevel) bool {
+	return db.IsEventMask(fl.Field().String())
+}
+
+// specMessageKey keeps problem rules apart from validator tags.
+func specMessageKey(rule string) string {
+	return "spec_" + rule
+}
+
+func registerMessage(tag string, message string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, message, false)
+	}
+}
+
+func translateField(trans ut.Translator, fe validator.FieldError) string {
+	message, err := trans.T(fe.Tag(), fe.Field())
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// translator picks translation by Accept-Language header, english by default.
+func translator(r *http.Request) ut.Translator {
+	return translatorFor(r.Header.Get("Accept-Language"))
+}
+
+func translatorFor(acceptLanguage string) ut.Translator {
+	tags, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	locales := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		locales = append(locales, base.String())
+	}
+	trans, _ := translators.FindTranslator(locales...)
+	return trans
+}
+
+// validationError converts validator errors into per field details
+// with messages in the language of the request.
+func validationError(r *http.Request, err error) error {
+	return ValidationError(r.Header.Get("Accept-Language"), err)
+}
+
+// ValidationError is validationError for transports other than HTTP,
+// languages are given in Accept-Language format.
+func ValidationError(acceptLanguage string, err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	trans := translatorFor(acceptLanguage)
+	fields := make([]*errs.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, &errs.FieldError{
+			Field:   fieldPath(fe),
+			Rule:    fe.Tag(),
+			Param:   fe.Param(),
+			Message: fe.Translate(trans),
+		})
+	}
+	message, _ := trans.T(requestInvalid)
+
+	return errs.Validation(message, fields...)
+}
+
+// fieldPath is a JSON path of the field without the request struct name.
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
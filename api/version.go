@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"filmoteka/config"
+	"filmoteka/errs"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Versions of the API, routes of each are mounted under /v<version>.
+const (
+	apiV1 = 1
+	apiV2 = 2
+	// latestVersion is described by the OpenAPI document.
+	latestVersion = apiV2
+)
+
+// versionMediaType selects a version by Accept header of unprefixed routes,
+// like application/vnd.filmoteka.v2+json.
+const (
+	versionMediaTypePrefix = "application/vnd.filmoteka.v"
+	versionMediaTypeSuffix = "+json"
+)
+
+// apiVersion is the version of the request, v1 when it is not set.
+func apiVersion(r *http.Request) int {
+	version, ok := r.Context().Value("APIVersion").(int)
+	if !ok {
+		return apiV1
+	}
+	return version
+}
+
+// withVersion serves routes mounted under the version prefix.
+func withVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), "APIVersion", version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// sinceVersion hides the route from requests of older versions.
+func sinceVersion(version int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiVersion(r) < version {
+				HandleError(w, r, errs.NotFound(errs.CodeNotFound, "route is available since API v"+strconv.Itoa(version)))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// negotiateVersion serves unprefixed routes with the version of Accept
+// header. Without it they are deprecated aliases of v1.
+func negotiateVersion(cfg config.Versioning) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			varyAccept(w)
+			version, err := acceptedVersion(r)
+			if err != nil {
+				HandleError(w, r, err)
+				return
+			}
+			if version == 0 {
+				version = apiV1
+				setDeprecation(w, r, cfg)
+			}
+			ctx := context.WithValue(r.Context(), "APIVersion", version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// setDeprecation announces removal of the unprefixed route (RFC 9745 and
+// RFC 8594) and links the same route of the latest version.
+func setDeprecation(w http.ResponseWriter, r *http.Request, cfg config.Versioning) {
+	w.Header().Set("Deprecation", "@"+strconv.FormatInt(cfg.DeprecatedAt.Unix(), 10))
+	w.Header().Set("Sunset", cfg.SunsetAt.UTC().Format(http.TimeFormat))
+	w.Header().Add("Link", `</v`+strconv.Itoa(latestVersion)+r.URL.Path+`>; rel="successor-version"`)
+}
+
+// acceptedVersion reads the version of vendor media type in Accept header,
+// it is zero when the header has no such type.
+func acceptedVersion(r *http.Request) (int, error) {
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil || !strings.HasPrefix(mediaType, versionMediaTypePrefix) {
+			continue
+		}
+		number := strings.TrimSuffix(strings.TrimPrefix(mediaType, versionMediaTypePrefix), versionMediaTypeSuffix)
+		version, err := strconv.Atoi(number)
+		if err != nil || !strings.HasSuffix(mediaType, versionMediaTypeSuffix) || version < apiV1 || version > latestVersion {
+			return 0, errs.Wrap(errs.KindNotAcceptable, errs.CodeNotAcceptable, "API version "+mediaType+" is not supported", err)
+		}
+		return version, nil
+	}
+	return 0, nil
+}
+
+// requestVersion is the version the request is going to be served by,
+// with the path inside the version.
+func requestVersion(r *http.Request) (int, string) {
+	for _, version := range []int{apiV1, apiV2} {
+		prefix := "/v" + strconv.Itoa(version)
+		if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+			return version, strings.TrimPrefix(r.URL.Path, prefix)
+		}
+	}
+	version, err := acceptedVersion(r)
+	if err != nil || version == 0 {
+		return apiV1, r.URL.Path
+	}
+	return version, r.URL.Path
+}
+
+// versionRoutes registers the API of the version, zero version is for
+// unprefixed routes which negotiate it. Routes added later are available
+// since the version they appeared in. Reads of films and actors go through
+// the response cache.
+func versionRoutes(version int, events http.HandlerFunc, graphqlServer *graphqlServer, cached func(http.Handler) http.Handler) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Route("/films", func(r chi.Router) {
+			r.Use(cached)
+			r.Get("/", getFilms)
+			r.Post("/", createFilm)
+			r.Post("/bulk", bulkFilms)
+			r.Get("/by-external/{source}/{id}", getFilmByExternal)
+			r.Put("/by-external/{source}/{id}", upsertFilmByExternal)
+			r.Get("/{filmID}", getFilm)
+			r.Put("/{filmID}", updateFilm)
+			r.Delete("/{filmID}", deleteFilm)
+		})
+		r.Route("/actors", func(r chi.Router) {
+			r.Use(cached)
+			r.Get("/", getActors)
+			r.Post("/", createActor)
+			r.Post("/bulk", bulkActors)
+			r.Get("/by-external/{source}/{id}", getActorByExternal)
+			r.Put("/by-external/{source}/{id}", upsertActorByExternal)
+			r.Get("/path", getCostarPath)
+			r.Get("/{actorID}/filmography", getFilmography)
+			r.Get("/{actorID}/costars", getCostars)
+			r.Get("/{actorID}", getActor)
+			r.Put("/{actorID}", updateActor)
+			r.Delete("/{actorID}", deleteActor)
+		})
+		r.Get("/search", search)
+		r.Get("/autocomplete", autocomplete)
+		r.Post("/import", importCatalogue)
+		r.Get("/import/{jobID}", getImportJob)
+		r.Route("/export", func(r chi.Router) {
+			r.Get("/films", exportFilms)
+			r.Get("/actors", exportActors)
+		})
+		r.Get("/events", events)
+		r.Get("/graphql", graphqlServer.query)
+		r.Post("/graphql", graphqlServer.execute)
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", listWebhooks)
+			r.Post("/", createWebhook)
+			r.Get("/{webhookID}", getWebhook)
+			r.Put("/{webhookID}", updateWebhook)
+			r.Delete("/{webhookID}", deleteWebhook)
+			r.Get("/{webhookID}/deliveries", getWebhookDeliveries)
+		})
+		r.Get("/healthcheck", healthcheck)
+
+		if version == 0 || version >= apiV2 {
+			r.With(sinceVersion(apiV2)).Get("/users/me", getCurrentUser)
+		}
+	}
+}
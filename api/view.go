@@ -0,0 +1,73 @@
+package api
+
+import (
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"net/http"
+	"strconv"
+)
+
+// maxPageLimit bounds limit param of lists, lists without it are not paged.
+const maxPageLimit = 1000
+
+// parseView reads include (or expand) and fields query params of the request.
+// Fields of the resource are set with fields or fields[<name>], fields of
+// the relation items with fields[<relation>]. Relation is included by
+// default only for single item responses.
+func parseView(r *http.Request, resource *api_models.Resource, includeByDefault bool) (*api_models.View, error) {
+	query := r.URL.Query()
+	view := &api_models.View{
+		Expand: api_models.Expand{resource.Relation: includeByDefault},
+	}
+
+	for _, param := range []string{"include", "expand"} {
+		if !query.Has(param) {
+			continue
+		}
+		expand, err := api_models.ParseExpand(query.Get(param), resource.Relation)
+		if err != nil {
+			return nil, errs.BadRequest(errs.CodeInvalidParam, err.Error())
+		}
+		view.Expand = expand
+	}
+
+	fields := query.Get("fields[" + resource.Name + "]")
+	if fields == "" {
+		fields = query.Get("fields")
+	}
+	var err error
+	view.Fields, err = api_models.ParseFields(fields, resource.Fields...)
+	if err != nil {
+		return nil, errs.BadRequest(errs.CodeInvalidParam, err.Error())
+	}
+	view.Nested, err = api_models.ParseFields(query.Get("fields["+resource.Relation+"]"), resource.Nested...)
+	if err != nil {
+		return nil, errs.BadRequest(errs.CodeInvalidParam, err.Error())
+	}
+	if view.Fields[resource.Relation] || view.Nested != nil {
+		view.Expand[resource.Relation] = true
+	}
+
+	return view, nil
+}
+
+// parsePage reads limit and offset query params of lists into sel.
+func parsePage(r *http.Request, sel *db.Selection) error {
+	query := r.URL.Query()
+	if param := query.Get("limit"); param != "" {
+		limit, err := strconv.Atoi(param)
+		if err != nil || limit < 1 || limit > maxPageLimit {
+			return errs.BadRequest(errs.CodeInvalidParam, "query param limit must be between 1 and 1000")
+		}
+		sel.Limit = limit
+	}
+	if param := query.Get("offset"); param != "" {
+		offset, err := strconv.Atoi(param)
+		if err != nil || offset < 0 {
+			return errs.BadRequest(errs.CodeInvalidParam, "query param offset must be a non-negative integer")
+		}
+		sel.Offset = offset
+	}
+	return nil
+}
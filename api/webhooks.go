@@ -0,0 +1,350 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// listWebhooks godoc
+// @Summary      List webhooks
+// @Description  Availible only for admin user, listing webhook subscriptions without secrets
+// @Tags         webhooks
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /webhooks [get]
+// @Security BasicAuth
+// @Success 200 {object} api_models.WebhooksResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+func listWebhooks(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhooks, err := db.GetWebhooks(pgdb)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	res := &api_models.WebhooksResponse{
+		Success:  true,
+		Error:    "",
+		Webhooks: webhooks,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// createWebhook godoc
+// @Summary      Create webhook
+// @Description  Availible only for admin user, subscribing url to catalogue events. Each delivery is a POST of the event signed in X-Filmoteka-Signature header as t=<unix time>,v1=<hex HMAC-SHA256 of "<t>.<body>" with the secret>. The secret is returned only in this response. Failed deliveries are retried with exponential backoff.
+// @Tags         webhooks
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /webhooks [post]
+// @Param Webhook body api_models.WebhookRequest true "webhook info"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request, repeated requests get the first response" example(9f4c2a1e-create-webhook)
+// @Security BasicAuth
+// @Success 201 {object} api_models.WebhookResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+// @Failure 422 {object}  ErrorResponse
+func createWebhook(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	req := &api_models.WebhookRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhook := newWebhook(req)
+	if webhook.Secret == "" {
+		webhook.Secret = newWebhookSecret()
+	}
+	webhook, err = db.CreateWebhook(pgdb, webhook)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	res := &api_models.WebhookResponse{
+		Success: true,
+		Error:   "",
+		Webhook: webhook,
+		Secret:  webhook.Secret,
+	}
+	w.Header().Set("Location", "/webhooks/"+strconv.FormatInt(webhook.ID, 10))
+	writeResponse(w, r, http.StatusCreated, res)
+}
+
+// getWebhook godoc
+// @Summary      Get webhook
+// @Description  Availible only for admin user, getting webhook by id without its secret
+// @Tags         webhooks
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /webhooks/{webhookID} [get]
+// @Param webhookID path int true "Webhook id"
+// @Security BasicAuth
+// @Success 200 {object} api_models.WebhookResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func getWebhook(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhookID, err := idParam(r, "webhookID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhook, err := db.GetWebhook(pgdb, webhookID)
+	if err != nil {
+		HandleError(w, r, notFound(err, "webhook"))
+		return
+	}
+
+	res := &api_models.WebhookResponse{
+		Success: true,
+		Error:   "",
+		Webhook: webhook,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// updateWebhook godoc
+// @Summary      Update webhook
+// @Description  Availible only for admin user, replacing url, events and activity of the webhook. The secret is kept when omitted and returned when changed.
+// @Tags         webhooks
+// @Accept       json
+// @Accept       application/xml
+// @Accept       application/msgpack
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /webhooks/{webhookID} [put]
+// @Param webhookID path int true "Webhook id"
+// @Param Webhook body api_models.WebhookRequest true "webhook info"
+// @Security BasicAuth
+// @Success 200 {object} api_models.WebhookResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func updateWebhook(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhookID, err := idParam(r, "webhookID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	req := &api_models.WebhookRequest{}
+	err = decodeBody(r, req)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhook := newWebhook(req)
+	webhook.ID = webhookID
+	webhook, err = db.UpdateWebhook(pgdb, webhook)
+	if err != nil {
+		HandleError(w, r, notFound(err, "webhook"))
+		return
+	}
+
+	res := &api_models.WebhookResponse{
+		Success: true,
+		Error:   "",
+		Webhook: webhook,
+	}
+	if req.Secret != "" {
+		res.Secret = webhook.Secret
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// deleteWebhook godoc
+// @Summary      Delete webhook
+// @Description  Availible only for admin user, deleting webhook with its delivery log, pending deliveries are not sent
+// @Tags         webhooks
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /webhooks/{webhookID} [delete]
+// @Param webhookID path int true "Webhook id"
+// @Security BasicAuth
+// @Success 200 {object} api_models.WebhookResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhookID, err := idParam(r, "webhookID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	err = db.DeleteWebhook(pgdb, webhookID)
+	if err != nil {
+		HandleError(w, r, notFound(err, "webhook"))
+		return
+	}
+
+	res := &api_models.WebhookResponse{
+		Success: true,
+		Error:   "",
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+// getWebhookDeliveries godoc
+// @Summary      Webhook delivery log
+// @Description  Availible only for admin user, listing the latest deliveries of the webhook with attempts, last response status and error
+// @Tags         webhooks
+// @Produce      json
+// @Produce      application/xml
+// @Produce      application/msgpack
+// @Router       /webhooks/{webhookID}/deliveries [get]
+// @Param webhookID path int true "Webhook id"
+// @Param limit query int false "Max number of deliveries, default 50, max 500"
+// @Security BasicAuth
+// @Success 200 {object} api_models.WebhookDeliveriesResponse
+// @Failure 401 {object}  ErrorResponse
+// @Failure 403 {object}  ErrorResponse
+// @Failure 404 {object}  ErrorResponse
+// @Failure 400 {object}  ErrorResponse
+func getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	err := checkAdmin(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	webhookID, err := idParam(r, "webhookID")
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if param := r.URL.Query().Get("limit"); param != "" {
+		limit, err = strconv.Atoi(param)
+		if err != nil || limit < 1 || limit > maxDeliveriesLimit {
+			HandleError(w, r, errs.BadRequest(errs.CodeInvalidParam, "query param limit must be between 1 and 500"))
+			return
+		}
+	}
+
+	pgdb, err := getDB(r)
+	if err != nil {
+		HandleError(w, r, err)
+		return
+	}
+
+	deliveries, err := db.GetWebhookDeliveries(pgdb, webhookID, limit)
+	if err != nil {
+		HandleError(w, r, notFound(err, "webhook"))
+		return
+	}
+
+	res := &api_models.WebhookDeliveriesResponse{
+		Success:    true,
+		Error:      "",
+		Deliveries: deliveries,
+	}
+	writeResponse(w, r, http.StatusOK, res)
+}
+
+func newWebhook(req *api_models.WebhookRequest) *db.Webhook {
+	webhook := &db.Webhook{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+		Active: true,
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	return webhook
+}
+
+func newWebhookSecret() string {
+	secret := make([]byte, 32)
+	rand.Read(secret)
+	return "whsec_" + hex.EncodeToString(secret)
+}
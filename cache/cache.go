@@ -0,0 +1,98 @@
+// Package cache keeps encoded responses between requests. Stores work with
+// bytes and string keys, so one shared by instances, like Redis, can
+// replace the in-process LRU.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache stores values by key for ttl. Errors of a remote store are
+// returned, callers treat them as misses.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Ping reports whether the store is reachable.
+	Ping(ctx context.Context) error
+}
+
+// LRU keeps up to size values in memory, the least recently used one is
+// evicted first.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// order has the most recently used entry at the front.
+	order *list.List
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{
+		size:    size,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+func (c *LRU) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	e := element.Value.(*entry)
+	if time.Now().After(e.expires) {
+		c.remove(element)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return e.value, true, nil
+}
+
+func (c *LRU) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := time.Now().Add(ttl)
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value, e.expires = value, expires
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// Ping never fails as values are kept in process.
+func (c *LRU) Ping(context.Context) error {
+	return nil
+}
+
+// Len is the number of kept values, expired ones included until they are
+// read or evicted.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ActorListOptions are query params of actors lists, zero values are omitted.
+type ActorListOptions struct {
+	// Include lists relations to include, "films" for actors.
+	Include []string
+	// Fields selects returned fields of actors.
+	Fields []string
+	// FilmFields selects returned fields of included films.
+	FilmFields []string
+	// Limit and Offset page the list, all actors are returned without Limit.
+	Limit  int
+	Offset int
+}
+
+func (o *ActorListOptions) values() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	setList(query, "include", o.Include)
+	setList(query, "fields[actors]", o.Fields)
+	setList(query, "fields[films]", o.FilmFields)
+	setPage(query, o.Limit, o.Offset)
+	return query
+}
+
+// ActorsService calls /actors endpoints.
+type ActorsService struct {
+	client *Client
+}
+
+// List returns actors ordered by id.
+func (s *ActorsService) List(ctx context.Context, opts *ActorListOptions) ([]*Actor, error) {
+	res := &actorsResponse{}
+	err := s.client.do(ctx, http.MethodGet, "/actors", opts.values(), nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Actors, nil
+}
+
+// Iter walks all actors page by page, Limit of opts is the page size and
+// Offset is ignored.
+func (s *ActorsService) Iter(ctx context.Context, opts *ActorListOptions) *Iterator[*Actor] {
+	page := ActorListOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	return newIterator(ctx, page.Limit, func(ctx context.Context, offset int, limit int) ([]*Actor, error) {
+		page.Offset = offset
+		page.Limit = limit
+		return s.List(ctx, &page)
+	})
+}
+
+// Get returns the actor with its films.
+func (s *ActorsService) Get(ctx context.Context, id int64) (*Actor, error) {
+	res := &actorResponse{}
+	err := s.client.do(ctx, http.MethodGet, "/actors/"+strconv.FormatInt(id, 10), nil, nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Actor, nil
+}
+
+// Create adds the actor, it requires an admin user.
+func (s *ActorsService) Create(ctx context.Context, req *CreateActorRequest) (*Actor, error) {
+	res := &actorResponse{}
+	err := s.client.do(ctx, http.MethodPost, "/actors", nil, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Actor, nil
+}
+
+// Update changes the set fields of the actor, it requires an admin user.
+func (s *ActorsService) Update(ctx context.Context, id int64, req *UpdateActorRequest) (*Actor, error) {
+	res := &actorResponse{}
+	err := s.client.do(ctx, http.MethodPut, "/actors/"+strconv.FormatInt(id, 10), nil, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Actor, nil
+}
+
+// Delete removes the actor, it requires an admin user.
+func (s *ActorsService) Delete(ctx context.Context, id int64) error {
+	return s.client.do(ctx, http.MethodDelete, "/actors/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
@@ -0,0 +1,216 @@
+// Package client is a typed Go client of the Filmoteka REST API. Its
+// models follow definitions of docs/swagger.json, tests keep them in sync.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiPrefix is the version of the API the models follow.
+const apiPrefix = "/v2"
+
+// Retry defaults, requests are sent at most DefaultMaxAttempts times.
+const (
+	DefaultMaxAttempts = 3
+	DefaultMinBackoff  = 200 * time.Millisecond
+	DefaultMaxBackoff  = 5 * time.Second
+)
+
+// Client calls the API, it is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	username   string
+	password   string
+	userAgent  string
+
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+
+	Films  *FilmsService
+	Actors *ActorsService
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithBasicAuth authenticates every request as the user.
+func WithBasicAuth(username string, password string) Option {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to set timeouts.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetry sets how many times a request is sent and the bounds of the
+// exponential backoff between attempts, one attempt disables retries.
+func WithRetry(maxAttempts int, minBackoff time.Duration, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithUserAgent sets User-Agent header of requests.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New creates a client of the API served at baseURL, e.g. http://localhost:8085.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("client: base url must be absolute")
+	}
+
+	c := &Client{
+		baseURL:     u,
+		httpClient:  http.DefaultClient,
+		userAgent:   "filmoteka-go-client",
+		maxAttempts: DefaultMaxAttempts,
+		minBackoff:  DefaultMinBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxAttempts < 1 {
+		c.maxAttempts = 1
+	}
+	c.Films = &FilmsService{client: c}
+	c.Actors = &ActorsService{client: c}
+	return c, nil
+}
+
+// do sends the request and decodes the response into out when it is not
+// nil. Failed attempts are retried when it is safe: network errors and
+// 429, 502, 503 and 504 responses for any method, as POST requests carry
+// an Idempotency-Key which makes the server replay the first response.
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += apiPrefix + path
+	u.RawQuery = query.Encode()
+
+	var idempotencyKey string
+	if method == http.MethodPost {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", c.userAgent)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+		if c.username != "" {
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		res, err := c.httpClient.Do(req)
+		var retryAfter time.Duration
+		if err == nil {
+			if !retryable(res.StatusCode) || attempt == c.maxAttempts {
+				return decodeResponse(res, out)
+			}
+			retryAfter = parseRetryAfter(res.Header.Get("Retry-After"))
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		} else if ctx.Err() != nil || attempt == c.maxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(max(retryAfter, c.backoff(attempt)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff doubles the pause after every attempt up to maxBackoff.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.minBackoff
+	for i := 1; i < attempt && delay < c.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.maxBackoff)
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter reads delay seconds of Retry-After, dates are ignored.
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func decodeResponse(res *http.Response, out interface{}) error {
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return newError(res)
+	}
+	if out == nil {
+		io.Copy(io.Discard, res.Body)
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	rand.Read(key)
+	return hex.EncodeToString(key)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// FieldError describes why one field of the request is not valid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is a failed response, fields are read from the problem details
+// body (api.ErrorResponse in the spec) when the server sends one.
+type Error struct {
+	StatusCode int           `json:"status"`
+	Type       string        `json:"type"`
+	Title      string        `json:"title"`
+	Detail     string        `json:"detail,omitempty"`
+	Instance   string        `json:"instance,omitempty"`
+	Code       string        `json:"code"`
+	Fields     []*FieldError `json:"errors,omitempty"`
+}
+
+func (e *Error) Error() string {
+	message := e.Detail
+	if message == "" {
+		message = e.Title
+	}
+	if e.Code == "" {
+		return fmt.Sprintf("filmoteka: %d %s", e.StatusCode, message)
+	}
+	return fmt.Sprintf("filmoteka: %d %s: %s", e.StatusCode, e.Code, message)
+}
+
+func newError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	e := &Error{}
+	err := json.Unmarshal(body, e)
+	if err != nil {
+		e.Detail = strings.TrimSpace(string(body))
+	}
+	e.StatusCode = res.StatusCode
+	if e.Title == "" {
+		e.Title = http.StatusText(res.StatusCode)
+	}
+	return e
+}
+
+// StatusCode is the status of a failed response, zero for other errors.
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound reports whether the requested entity does not exist.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether credentials are missing or wrong.
+func IsUnauthorized(err error) bool {
+	return StatusCode(err) == http.StatusUnauthorized
+}
+
+// IsForbidden reports whether the user is not allowed to make the request.
+func IsForbidden(err error) bool {
+	return StatusCode(err) == http.StatusForbidden
+}
+
+// IsConflict reports whether the request conflicts with stored entities.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Sort orders of films lists.
+const (
+	SortByRate = "rate"
+	SortByName = "name"
+	SortByDate = "date"
+)
+
+// FilmFilter keeps films with the field containing the value.
+type FilmFilter struct {
+	Field string
+	Value string
+}
+
+// FilmListOptions are query params of films lists, zero values are omitted.
+type FilmListOptions struct {
+	SortBy string
+	Filter *FilmFilter
+	// Include lists relations to include, "actors" for films.
+	Include []string
+	// Fields selects returned fields of films.
+	Fields []string
+	// ActorFields selects returned fields of included actors.
+	ActorFields []string
+	// Limit and Offset page the list, all films are returned without Limit.
+	Limit  int
+	Offset int
+}
+
+func (o *FilmListOptions) values() url.Values {
+	query := url.Values{}
+	if o == nil {
+		return query
+	}
+	if o.SortBy != "" {
+		query.Set("sortBy", o.SortBy)
+	}
+	if o.Filter != nil {
+		query.Set("filter", o.Filter.Field+"."+o.Filter.Value)
+	}
+	setList(query, "include", o.Include)
+	setList(query, "fields[films]", o.Fields)
+	setList(query, "fields[actors]", o.ActorFields)
+	setPage(query, o.Limit, o.Offset)
+	return query
+}
+
+// FilmsService calls /films endpoints.
+type FilmsService struct {
+	client *Client
+}
+
+// List returns films sorted by rate unless other order is set.
+func (s *FilmsService) List(ctx context.Context, opts *FilmListOptions) ([]*Film, error) {
+	res := &filmsResponse{}
+	err := s.client.do(ctx, http.MethodGet, "/films", opts.values(), nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Films, nil
+}
+
+// Iter walks all films matching opts page by page, Limit of opts is the
+// page size and Offset is ignored.
+func (s *FilmsService) Iter(ctx context.Context, opts *FilmListOptions) *Iterator[*Film] {
+	page := FilmListOptions{}
+	if opts != nil {
+		page = *opts
+	}
+	return newIterator(ctx, page.Limit, func(ctx context.Context, offset int, limit int) ([]*Film, error) {
+		page.Offset = offset
+		page.Limit = limit
+		return s.List(ctx, &page)
+	})
+}
+
+// Get returns the film with its actors.
+func (s *FilmsService) Get(ctx context.Context, id int) (*Film, error) {
+	res := &filmResponse{}
+	err := s.client.do(ctx, http.MethodGet, "/films/"+strconv.Itoa(id), nil, nil, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Film, nil
+}
+
+// Create adds the film, it requires an admin user.
+func (s *FilmsService) Create(ctx context.Context, req *CreateFilmRequest) (*Film, error) {
+	res := &filmResponse{}
+	err := s.client.do(ctx, http.MethodPost, "/films", nil, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Film, nil
+}
+
+// Update replaces fields of the film, it requires an admin user.
+func (s *FilmsService) Update(ctx context.Context, id int, req *UpdateFilmRequest) (*Film, error) {
+	res := &filmResponse{}
+	err := s.client.do(ctx, http.MethodPut, "/films/"+strconv.Itoa(id), nil, req, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.Film, nil
+}
+
+// Delete removes the film, it requires an admin user.
+func (s *FilmsService) Delete(ctx context.Context, id int) error {
+	return s.client.do(ctx, http.MethodDelete, "/films/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+func setList(query url.Values, name string, values []string) {
+	if len(values) > 0 {
+		query.Set(name, strings.Join(values, ","))
+	}
+}
+
+func setPage(query url.Values, limit int, offset int) {
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+}
@@ -0,0 +1,72 @@
+package client
+
+import "context"
+
+// DefaultPageSize is the number of items an Iterator requests at once.
+const DefaultPageSize = 100
+
+// Iterator walks a list page by page, the next page is requested when the
+// current one is consumed:
+//
+//	it := c.Films.Iter(ctx, nil)
+//	for it.Next() {
+//		fmt.Println(it.Value().Name)
+//	}
+//	if err := it.Err(); err != nil {
+//		return err
+//	}
+type Iterator[T any] struct {
+	ctx      context.Context
+	fetch    func(ctx context.Context, offset int, limit int) ([]T, error)
+	pageSize int
+
+	page   []T
+	index  int
+	offset int
+	done   bool
+	err    error
+}
+
+func newIterator[T any](ctx context.Context, pageSize int, fetch func(context.Context, int, int) ([]T, error)) *Iterator[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Iterator[T]{ctx: ctx, fetch: fetch, pageSize: pageSize, index: -1}
+}
+
+// Next advances to the next item, it is false when the list is over or
+// a page request failed.
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.index+1 < len(it.page) {
+		it.index++
+		return true
+	}
+	if it.done {
+		return false
+	}
+
+	page, err := it.fetch(it.ctx, it.offset, it.pageSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.page = page
+	it.index = 0
+	it.offset += len(page)
+	// A short page is the last one.
+	it.done = len(page) < it.pageSize
+	return len(page) > 0
+}
+
+// Value is the current item.
+func (it *Iterator[T]) Value() T {
+	return it.page[it.index]
+}
+
+// Err is the error which stopped the iteration.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
@@ -0,0 +1,97 @@
+package client
+
+import "time"
+
+// DateLayout is a format of dates in requests.
+const DateLayout = "2006-01-02"
+
+// FilmSummary is a short film shape used inside actors.
+type FilmSummary struct {
+	ID   int       `json:"id"`
+	Name string    `json:"name"`
+	Date time.Time `json:"date"`
+	Rate int       `json:"rate"`
+}
+
+// Film is a detailed film, actors are present only when included.
+type Film struct {
+	ID          int             `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Date        time.Time       `json:"date"`
+	Rate        int             `json:"rate"`
+	Actors      []*ActorSummary `json:"actors,omitempty"`
+	// ExternalIDs maps source to the film id in it.
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+}
+
+// ActorSummary is a short actor shape used inside films.
+type ActorSummary struct {
+	ID    int64     `json:"id"`
+	Name  string    `json:"name"`
+	Sex   string    `json:"sex"`
+	Birth time.Time `json:"birthday"`
+}
+
+// Actor is a detailed actor, films are present only when included.
+type Actor struct {
+	ID    int64          `json:"id"`
+	Name  string         `json:"name"`
+	Sex   string         `json:"sex"`
+	Birth time.Time      `json:"birthday"`
+	Films []*FilmSummary `json:"films,omitempty"`
+	// ExternalIDs maps source to the actor id in it.
+	ExternalIDs map[string]string `json:"external_ids,omitempty"`
+}
+
+// CreateFilmRequest is a new film, date is in DateLayout format and roles
+// are keyed by actor ids.
+type CreateFilmRequest struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Date        string         `json:"date"`
+	Rate        int            `json:"rate"`
+	Actors      []int          `json:"actors"`
+	Roles       map[int]string `json:"roles,omitempty"`
+}
+
+// UpdateFilmRequest replaces fields of the film, omitted fields are reset
+// by the server, so send the whole film.
+type UpdateFilmRequest struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Date        string `json:"date,omitempty"`
+	Rate        int    `json:"rate,omitempty"`
+}
+
+// CreateActorRequest is a new actor, birth is in DateLayout format.
+type CreateActorRequest struct {
+	Name  string `json:"name"`
+	Sex   string `json:"sex"`
+	Birth string `json:"birth"`
+}
+
+// UpdateActorRequest changes only the set fields of the actor.
+type UpdateActorRequest struct {
+	Name  string `json:"name,omitempty"`
+	Sex   string `json:"sex,omitempty"`
+	Birth string `json:"birth,omitempty"`
+}
+
+// responses of the API, success and error members are not used as failed
+// responses are problem details.
+type filmsResponse struct {
+	Films []*Film `json:"films"`
+}
+
+type filmResponse struct {
+	Film *Film `json:"film"`
+}
+
+type actorsResponse struct {
+	Actors []*Actor `json:"actors"`
+}
+
+type actorResponse struct {
+	Actor *Actor `json:"actor"`
+}
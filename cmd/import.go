@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"filmoteka/config"
+	"filmoteka/db"
+	"filmoteka/importer"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+)
+
+// progressEvery is how many rows are imported between progress logs.
+const progressEvery = 1000
+
+// runImport handles "filmoteka import [flags] file", the report is
+// printed to stdout as JSON.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: filmoteka import -type films|actors [flags] file|-")
+		flags.PrintDefaults()
+	}
+	importType := flags.String("type", importer.TypeFilms, "what is imported: films or actors")
+	format := flags.String("format", "", "csv or ndjson, guessed from file extension by default")
+	dryRun := flags.Bool("dry-run", false, "only validate rows and report what would be changed")
+	mapping := flags.String("map", "", "comma separated renames of source columns, e.g. title=name,year=date")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("exactly one file is expected, use - for stdin")
+	}
+
+	opts := importer.Options{Type: *importType, Format: *format, DryRun: *dryRun}
+	opts.Mapping, err = importer.ParseMapping(*mapping)
+	if err != nil {
+		return err
+	}
+
+	name := flags.Arg(0)
+	source := io.Reader(os.Stdin)
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		source = file
+		if opts.Format == "" {
+			opts.Format = importer.FormatByName(name)
+		}
+	}
+	err = opts.Validate()
+	if err != nil {
+		return err
+	}
+
+	cfg := config.CnfLoad()
+	pgdb, err := db.StartDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer pgdb.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := importer.Run(ctx, pgdb, source, opts, func(report *importer.Report) {
+		if report.Processed%progressEvery == 0 {
+			slog.Info("importing", "processed", report.Processed, "failed", report.Failed)
+		}
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encodeErr := encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+	if encodeErr != nil {
+		return encodeErr
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d rows failed", report.Failed, report.Processed)
+	}
+	return nil
+}
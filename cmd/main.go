@@ -1,12 +1,10 @@
 package main
 
 import (
-	"filmoteka/api"
 	"filmoteka/config"
-	"filmoteka/db"
 	"filmoteka/logger"
 	"log/slog"
-	"net/http"
+	"os"
 )
 
 //	@title			Filmoteka API
@@ -18,28 +16,31 @@ import (
 //	@contact.url	http://www.swagger.io/support
 //	@contact.email	support@swagger.io
 
-// @host		host:port
-// @BasePath /
+// @BasePath /v2
 
 // @securityDefinitions.basic BasicAuth
 // @scope.admin Grants read and write access to administrative information
 // @in header
 // @name Authorization
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		err := runImport(os.Args[2:])
+		if err != nil {
+			slog.Error("import failed", "err", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg := config.CnfLoad()
 
 	log := logger.SetupLogger(cfg.Env)
 	log = log.With(slog.String("env", cfg.Env))
 
-	pgdb, err := db.StartDB(cfg)
-	if err != nil {
-		log.Error("error starting the database %v", err)
-	}
-
-	router := api.StartAPI(pgdb, cfg)
-
-	err = http.ListenAndServe(cfg.HTTPServer.Address, router)
+	err := runServer(cfg)
 	if err != nil {
-		log.Error("error from router %v\n", err)
+		log.Error("server stopped with error", "err", err)
+		os.Exit(1)
 	}
+	log.Info("server stopped")
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"filmoteka/api"
+	"filmoteka/config"
+	"filmoteka/db"
+	"filmoteka/grpcapi"
+	"filmoteka/webhooks"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// runServer serves HTTP and gRPC APIs until SIGINT or SIGTERM, then drains
+// in-flight requests within the shutdown timeout once readiness failed for
+// the drain delay. Errors of starting the servers stop the others too.
+func runServer(cfg *config.Config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pgdb, err := db.StartDB(cfg)
+	if err != nil {
+		return fmt.Errorf("starting the database: %w", err)
+	}
+	// Deferred calls run after servers are drained.
+	defer pgdb.Close()
+
+	shuttingDown := make(chan struct{})
+	handler := api.StartAPI(pgdb, cfg)
+	server := &http.Server{
+		Addr:              cfg.HTTPServer.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.HTTPServer.Timeout,
+		ReadTimeout:       cfg.HTTPServer.Timeout,
+		WriteTimeout:      cfg.HTTPServer.Timeout,
+		IdleTimeout:       cfg.HTTPServer.IdleTimeout,
+		BaseContext: func(net.Listener) context.Context {
+			return api.WithShutdown(context.Background(), shuttingDown)
+		},
+	}
+	grpcServer := grpcapi.NewServer(pgdb)
+
+	// Background jobs query the database, they stop before it is closed.
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	var jobs sync.WaitGroup
+	jobs.Add(2)
+	go func() {
+		defer jobs.Done()
+		webhooks.NewDispatcher(pgdb, cfg.Webhooks).Run(jobsCtx)
+	}()
+	go func() {
+		defer jobs.Done()
+		handler.Run(jobsCtx)
+	}()
+	stopped := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(stopped)
+	}()
+
+	failed := make(chan error, 2)
+	go func() {
+		slog.Info("Success start HTTP server", "address", cfg.HTTPServer.Address)
+		err := server.ListenAndServe()
+		if !errors.Is(err, http.ErrServerClosed) {
+			failed <- fmt.Errorf("HTTP server: %w", err)
+		}
+	}()
+	go func() {
+		err := grpcapi.ListenAndServe(grpcServer, cfg.GRPCServer)
+		if err != nil {
+			failed <- fmt.Errorf("gRPC server: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down", "timeout", cfg.HTTPServer.ShutdownTimeout)
+		// Readiness fails while connections are still accepted, so load
+		// balancers stop sending requests before the listener is closed.
+		close(shuttingDown)
+		select {
+		case <-time.After(cfg.HTTPServer.DrainDelay):
+		case err = <-failed:
+		}
+	case err = <-failed:
+		close(shuttingDown)
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+	shutdownErr := shutdownServers(drainCtx, server, grpcServer)
+	// Imports run after their requests are answered, the pool is closed
+	// only when they are finished or canceled.
+	shutdownErr = errors.Join(shutdownErr, handler.Shutdown(drainCtx))
+
+	stopJobs()
+	select {
+	case <-stopped:
+	case <-drainCtx.Done():
+		slog.Error("background jobs did not stop in time")
+	}
+	return errors.Join(err, shutdownErr)
+}
+
+// shutdownServers waits for in-flight requests until ctx is done, then
+// closes the remaining connections.
+func shutdownServers(ctx context.Context, server *http.Server, grpcServer *grpc.Server) error {
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		server.Close()
+		err = fmt.Errorf("draining HTTP requests: %w", err)
+	}
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		<-grpcStopped
+		err = errors.Join(err, errors.New("draining gRPC requests: deadline exceeded"))
+	}
+	return err
+}
@@ -9,15 +9,35 @@ import (
 )
 
 type Config struct {
-	Env        string `yaml:"env" env-default:"development"`
-	HTTPServer `yaml:"http_server"`
-	PostgresDB `yaml:"postgres"`
+	Env          string `yaml:"env" env-default:"development"`
+	HTTPServer   `yaml:"http_server"`
+	PostgresDB   `yaml:"postgres"`
+	Autocomplete `yaml:"autocomplete"`
+	Import       `yaml:"import"`
+	Idempotency  `yaml:"idempotency"`
+	Webhooks     `yaml:"webhooks"`
+	Events       `yaml:"events"`
+	GraphQL      `yaml:"graphql"`
+	GRPCServer   `yaml:"grpc_server"`
+	OpenAPI      `yaml:"openapi"`
+	Versioning   `yaml:"versioning"`
+	Cache        `yaml:"cache"`
+	RateLimit    `yaml:"rate_limit"`
+	Health       `yaml:"health"`
 }
 
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"0.0.0.0:8085"`
+	Address string `yaml:"address" env-default:"0.0.0.0:8085"`
+	// Timeout limits reading a request and writing its response, event
+	// streams, exports and import uploads are not limited by it.
 	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// ShutdownTimeout is how long in-flight requests are drained on
+	// SIGINT or SIGTERM before connections are closed.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"15s"`
+	// DrainDelay is how long readiness fails before the server stops
+	// accepting connections, so load balancers take the instance out.
+	DrainDelay time.Duration `yaml:"drain_delay" env-default:"5s"`
 }
 
 type PostgresDB struct {
@@ -27,6 +47,52 @@ type PostgresDB struct {
 	Database string `yaml:"database" env-default:"db"`
 }
 
+type Autocomplete struct {
+	Backend         string        `yaml:"backend" env-default:"postgres"`
+	RefreshInterval time.Duration `yaml:"refresh_interval" env-default:"1m"`
+}
+
+type Import struct {
+	MaxSize int64 `yaml:"max_size" env-default:"104857600"`
+}
+
+type Idempotency struct {
+	// TTL is how long responses are replayed for repeated keys.
+	TTL time.Duration `yaml:"ttl" env-default:"24h"`
+	// MaxBodySize limits bodies read to fingerprint the request, larger
+	// requests with Idempotency-Key are rejected.
+	MaxBodySize int64 `yaml:"max_body_size" env-default:"10485760"`
+}
+
+type Webhooks struct {
+	// Interval is how often the outbox and due retries are polled.
+	Interval    time.Duration `yaml:"interval" env-default:"5s"`
+	BatchSize   int           `yaml:"batch_size" env-default:"100"`
+	MaxAttempts int           `yaml:"max_attempts" env-default:"10"`
+	// Retries wait BackoffBase doubled on each attempt up to BackoffMax.
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"30s"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"6h"`
+	Timeout     time.Duration `yaml:"timeout" env-default:"10s"`
+}
+
+type Events struct {
+	// PollInterval is how often streams look for new events.
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"`
+	// Heartbeat keeps idle streams open through proxies.
+	Heartbeat time.Duration `yaml:"heartbeat" env-default:"15s"`
+	BatchSize int           `yaml:"batch_size" env-default:"100"`
+}
+
+type GraphQL struct {
+	// MaxDepth limits nesting of fields, zero disables the check.
+	MaxDepth int `yaml:"max_depth" env-default:"8"`
+	// MaxComplexity limits the estimated number of resolved fields.
+	MaxComplexity int `yaml:"max_complexity" env-default:"1000"`
+	// ListSize is the length of root lists without limit argument and the
+	// largest limit they accept, other lists are expected to be as long.
+	ListSize int `yaml:"list_size" env-default:"10"`
+}
+
 func CnfLoad() *Config {
 	configPath := os.Getenv("CONFIG_PATH")
 	if configPath == "" {
@@ -35,16 +101,71 @@ func CnfLoad() *Config {
 
 	// Проверяем существование конфиг-файла
 	if _, err := os.Stat(configPath); err != nil {
-		slog.Error("error opening config file: %s", err)
+		slog.Error("error opening config file", "err", err)
 	}
 
 	var cfg Config
 
 	err := cleanenv.ReadConfig(configPath, &cfg)
 	if err != nil {
-		slog.Error("error reading config file: %s", err)
+		slog.Error("error reading config file", "err", err)
 	}
 	slog.Info("Success init config")
 
 	return &cfg
 }
+
+type GRPCServer struct {
+	// Address of the gRPC server, it listens apart from HTTP.
+	Address string `yaml:"address" env-default:"0.0.0.0:9090"`
+}
+
+type OpenAPI struct {
+	// ValidateRequests rejects params and JSON bodies not matching the docs.
+	ValidateRequests bool `yaml:"validate_requests" env-default:"true"`
+	// ValidateResponses logs responses not matching the docs, it is meant
+	// for development as responses are copied.
+	ValidateResponses bool `yaml:"validate_responses" env-default:"false"`
+	// MaxBodySize limits validated request bodies and copied responses.
+	MaxBodySize int64 `yaml:"max_body_size" env-default:"10485760"`
+}
+
+type Versioning struct {
+	// DeprecatedAt is announced by routes without version prefix, they
+	// serve API v1 unless another version is accepted.
+	DeprecatedAt time.Time `yaml:"deprecated_at" env-layout:"2006-01-02" env-default:"2026-11-01"`
+	// SunsetAt is when routes without version prefix are removed.
+	SunsetAt time.Time `yaml:"sunset_at" env-layout:"2006-01-02" env-default:"2027-05-01"`
+}
+
+type Cache struct {
+	// Backend keeps cached responses, memory or none to disable caching.
+	Backend string `yaml:"backend" env-default:"memory"`
+	// Size is the number of responses kept by memory backend.
+	Size int `yaml:"size" env-default:"1000"`
+	// TTL bounds how long a response is served if its change was missed.
+	TTL time.Duration `yaml:"ttl" env-default:"5m"`
+	// MaxAge is sent to clients in Cache-Control, zero makes them revalidate.
+	MaxAge time.Duration `yaml:"max_age" env-default:"0s"`
+	// MaxEntrySize skips caching of larger responses.
+	MaxEntrySize int64 `yaml:"max_entry_size" env-default:"1048576"`
+}
+
+type RateLimit struct {
+	Enabled bool `yaml:"enabled" env-default:"true"`
+	// Read limits GET requests of a client per second, Burst of them may
+	// come at once.
+	ReadRate  float64 `yaml:"read_rate" env-default:"20"`
+	ReadBurst int     `yaml:"read_burst" env-default:"40"`
+	// Write limits other methods.
+	WriteRate  float64 `yaml:"write_rate" env-default:"2"`
+	WriteBurst int     `yaml:"write_burst" env-default:"10"`
+	// APIKeys maps keys sent in X-API-Key to their daily quotas of
+	// requests, zero quota is unlimited. Other keys are ignored.
+	APIKeys map[string]int64 `yaml:"api_keys"`
+}
+
+type Health struct {
+	// CheckTimeout limits each dependency check of readiness probes.
+	CheckTimeout time.Duration `yaml:"check_timeout" env-default:"2s"`
+}
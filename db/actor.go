@@ -1,9 +1,11 @@
 package db
 
 import (
+	"filmoteka/errs"
 	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 type Actor struct {
@@ -12,18 +14,52 @@ type Actor struct {
 	Sex   string    `json:"sex" validate:"oneof=male female"`
 	Birth time.Time `json:"birthday"`
 	Films []Film    `json:"films" pg:"many2many:film_to_actors"`
+
+	ExternalIDs []*ActorExternalID `json:"external_ids,omitempty" pg:"rel:has-many"`
+}
+
+// ActorRow is a flat actor for exports, films are names of its films.
+type ActorRow struct {
+	ID    int64
+	Name  string
+	Sex   string
+	Birth time.Time
+	Films []string `pg:",array"`
 }
 
-func GetActors(db *pg.DB) ([]*Actor, error) {
+func GetActors(db *pg.DB, sel *Selection) ([]*Actor, error) {
 	actors := make([]*Actor, 0)
 
-	err := db.Model(&actors).Relation("Films").
+	err := sel.apply(db.Model(&actors), "Films").
+		Order("actor.id ASC").
 		Select()
 
 	return actors, err
 }
 
-func CreateActor(db *pg.DB, req *Actor) (*Actor, error) {
+// EachActor streams all actors, fn is called for every row as it is read
+// from the database.
+func EachActor(db *pg.DB, fn func(*ActorRow) error) error {
+	return db.Model((*Actor)(nil)).
+		Column("actor.id", "actor.name", "actor.sex", "actor.birth").
+		ColumnExpr(`ARRAY(SELECT f.name FROM film_to_actors AS fa
+			JOIN films AS f ON f.id = fa.film_id
+			WHERE fa.actor_id = actor.id ORDER BY f.date, f.name) AS films`).
+		Order("actor.id ASC").
+		ForEach(fn)
+}
+
+func GetActor(db *pg.DB, actorID int64, sel *Selection) (*Actor, error) {
+	actor := &Actor{}
+
+	err := sel.apply(db.Model(actor), "Films").
+		Where("actor.id = ?", actorID).
+		Select()
+
+	return actor, err
+}
+
+func CreateActor(db orm.DB, req *Actor) (*Actor, error) {
 	_, err := db.Model(req).Insert()
 	if err != nil {
 		return nil, err
@@ -35,11 +71,14 @@ func CreateActor(db *pg.DB, req *Actor) (*Actor, error) {
 		Relation("Films").
 		Where("actor.id = ?", req.ID).
 		Select()
+	if err != nil {
+		return nil, err
+	}
 
-	return actor, err
+	return actor, recordActorEvent(db, EventActorCreated, actor)
 }
 
-func UpdateActor(db *pg.DB, req *Actor) (*Actor, error) {
+func UpdateActor(db orm.DB, req *Actor) (*Actor, error) {
 
 	_, err := db.Model(req).
 		Where("actor.id = ?", req.ID).
@@ -54,11 +93,14 @@ func UpdateActor(db *pg.DB, req *Actor) (*Actor, error) {
 		Relation("Films").
 		Where("actor.id = ?", req.ID).
 		Select()
+	if err != nil {
+		return nil, err
+	}
 
-	return actor, err
+	return actor, recordActorEvent(db, EventActorUpdated, actor)
 }
 
-func DeleteActor(db *pg.DB, actorID int64) error {
+func DeleteActor(db orm.DB, actorID int64) error {
 	actor := &Actor{}
 	film2actor := &FilmToActor{}
 
@@ -71,7 +113,268 @@ func DeleteActor(db *pg.DB, actorID int64) error {
 	}
 
 	_, err = db.Model(actor).WherePK().Delete()
+	if err != nil {
+		return err
+	}
 	_, err = db.Model(film2actor).Where("actor_id = ?", actor.ID).Delete()
+	if err != nil {
+		return err
+	}
+	_, err = db.Model((*ActorExternalID)(nil)).Where("actor_id = ?", actor.ID).Delete()
+	if err != nil {
+		return err
+	}
+
+	err = recordActorEvent(db, EventActorDeleted, actor)
+	if err != nil {
+		return err
+	}
+	for _, film := range actor.Films {
+		err = recordCastEvent(db, film.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindActor looks actor up by its natural key, name is compared case insensitive.
+func FindActor(db orm.DB, name string, birth time.Time) (*Actor, error) {
+	actor := &Actor{}
+
+	err := db.Model(actor).
+		Where("lower(actor.name) = lower(?)", name).
+		Where("actor.birth = ?", birth).
+		Order("actor.id ASC").
+		Limit(1).
+		Select()
+
+	return actor, err
+}
+
+// FindActorsByName returns all actors with the name, compared case insensitive.
+func FindActorsByName(db orm.DB, name string) ([]*Actor, error) {
+	actors := make([]*Actor, 0)
+
+	err := db.Model(&actors).
+		Where("lower(actor.name) = lower(?)", name).
+		Order("actor.id ASC").
+		Select()
+
+	return actors, err
+}
+
+func ActorExists(db orm.DB, actorID int64) (bool, error) {
+	return db.Model((*Actor)(nil)).Where("actor.id = ?", actorID).Exists()
+}
+
+// MaxPathDepth limits the co-star chain search, "six degrees" by default.
+const MaxPathDepth = 6
+
+var ErrNoPath = errs.NotFound("no_costar_path", "no co-star path between actors")
+
+type FilmographyEntry struct {
+	FilmID int       `json:"film_id"`
+	Name   string    `json:"name"`
+	Date   time.Time `json:"date"`
+	Rate   int       `json:"rate"`
+	Role   string    `json:"role"`
+}
+
+type Costar struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Sex         string    `json:"sex"`
+	Birth       time.Time `json:"birthday"`
+	SharedFilms int       `json:"shared_films"`
+}
+
+// PathLink is one actor in a co-star chain, film is shared with the next actor.
+type PathLink struct {
+	ActorID   int64  `json:"actor_id"`
+	ActorName string `json:"actor_name"`
+	FilmID    int    `json:"film_id,omitempty"`
+	FilmName  string `json:"film_name,omitempty"`
+}
+
+type costarEdge struct {
+	FromID int64
+	ToID   int64
+	FilmID int
+}
+
+func GetFilmography(db *pg.DB, actorID int64) ([]*FilmographyEntry, error) {
+	entries := make([]*FilmographyEntry, 0)
+
+	err := db.Model(&Actor{ID: actorID}).WherePK().Select()
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model((*Film)(nil)).
+		ColumnExpr("film.id AS film_id, film.name, film.date, film.rate, fa.role").
+		Join("JOIN film_to_actors AS fa ON fa.film_id = film.id").
+		Where("fa.actor_id = ?", actorID).
+		Order("film.date ASC", "film.name ASC").
+		Select(&entries)
+
+	return entries, err
+}
+
+func GetCostars(db *pg.DB, actorID int64) ([]*Costar, error) {
+	costars := make([]*Costar, 0)
+
+	err := db.Model(&Actor{ID: actorID}).WherePK().Select()
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Model((*Actor)(nil)).
+		ColumnExpr("actor.id, actor.name, actor.sex, actor.birth, count(DISTINCT own.film_id) AS shared_films").
+		Join("JOIN film_to_actors AS other ON other.actor_id = actor.id").
+		Join("JOIN film_to_actors AS own ON own.film_id = other.film_id").
+		Where("own.actor_id = ?", actorID).
+		Where("actor.id <> ?", actorID).
+		Group("actor.id").
+		Order("shared_films DESC", "actor.name ASC").
+		Select(&costars)
+
+	return costars, err
+}
+
+// GetCostarPath finds the shortest chain of actors linked by shared films
+// using breadth-first search over film_to_actors.
+func GetCostarPath(db *pg.DB, fromID int64, toID int64) ([]*PathLink, error) {
+	actors := make([]*Actor, 0)
+	err := db.Model(&actors).
+		Where("actor.id IN (?)", pg.In([]int64{fromID, toID})).
+		Select()
+	if err != nil {
+		return nil, err
+	}
+	if (fromID == toID && len(actors) != 1) || (fromID != toID && len(actors) != 2) {
+		return nil, pg.ErrNoRows
+	}
+
+	// Every visited actor points to the actor and film it was reached from.
+	prev := map[int64]costarEdge{fromID: {}}
+	frontier := []int64{fromID}
+	found := fromID == toID
+
+	for depth := 0; depth < MaxPathDepth && !found && len(frontier) > 0; depth++ {
+		edges := make([]*costarEdge, 0)
+		err = db.Model((*FilmToActor)(nil)).
+			ColumnExpr("film_to_actor.actor_id AS from_id, other.actor_id AS to_id, min(film_to_actor.film_id) AS film_id").
+			Join("JOIN film_to_actors AS other ON other.film_id = film_to_actor.film_id AND other.actor_id <> film_to_actor.actor_id").
+			Where("film_to_actor.actor_id IN (?)", pg.In(frontier)).
+			Group("film_to_actor.actor_id", "other.actor_id").
+			Order("from_id", "to_id").
+			Select(&edges)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]int64, 0)
+		for _, edge := range edges {
+			if _, ok := prev[edge.ToID]; ok {
+				continue
+			}
+			prev[edge.ToID] = *edge
+			next = append(next, edge.ToID)
+			if edge.ToID == toID {
+				found = true
+				break
+			}
+		}
+		frontier = next
+	}
+	if !found {
+		return nil, ErrNoPath
+	}
+
+	chain := []costarEdge{{ToID: toID}}
+	for id := toID; id != fromID; {
+		edge := prev[id]
+		chain = append([]costarEdge{{ToID: edge.FromID, FilmID: edge.FilmID}}, chain...)
+		id = edge.FromID
+	}
+
+	return resolvePath(db, chain)
+}
+
+func resolvePath(db *pg.DB, chain []costarEdge) ([]*PathLink, error) {
+	actorIDs := make([]int64, 0, len(chain))
+	filmIDs := []int{0}
+	for _, link := range chain {
+		actorIDs = append(actorIDs, link.ToID)
+		if link.FilmID != 0 {
+			filmIDs = append(filmIDs, link.FilmID)
+		}
+	}
+
+	actors := make([]*Actor, 0)
+	err := db.Model(&actors).Where("actor.id IN (?)", pg.In(actorIDs)).Select()
+	if err != nil {
+		return nil, err
+	}
+	films := make([]*Film, 0)
+	err = db.Model(&films).Where("film.id IN (?)", pg.In(filmIDs)).Select()
+	if err != nil {
+		return nil, err
+	}
+
+	actorNames := make(map[int64]string, len(actors))
+	for _, actor := range actors {
+		actorNames[actor.ID] = actor.Name
+	}
+	filmNames := make(map[int]string, len(films))
+	for _, film := range films {
+		filmNames[film.ID] = film.Name
+	}
+
+	path := make([]*PathLink, 0, len(chain))
+	for _, link := range chain {
+		path = append(path, &PathLink{
+			ActorID:   link.ToID,
+			ActorName: actorNames[link.ToID],
+			FilmID:    link.FilmID,
+			FilmName:  filmNames[link.FilmID],
+		})
+	}
+
+	return path, nil
+}
+
+// GetActorsByIDs loads actors without relations, it is used to batch lookups.
+func GetActorsByIDs(db *pg.DB, actorIDs []int64) ([]*Actor, error) {
+	actors := make([]*Actor, 0, len(actorIDs))
+
+	err := db.Model(&actors).
+		Where("actor.id IN (?)", pg.In(actorIDs)).
+		Select()
+
+	return actors, err
+}
+
+// GetRoles returns film links of the actors ordered by film.
+func GetRoles(db *pg.DB, actorIDs []int64) ([]*FilmToActor, error) {
+	links := make([]*FilmToActor, 0)
+
+	err := db.Model(&links).
+		Where("actor_id IN (?)", pg.In(actorIDs)).
+		Order("actor_id ASC", "film_id ASC").
+		Select()
+
+	return links, err
+}
+
+func GetActorsExternalIDs(db *pg.DB, actorIDs []int64) ([]*ActorExternalID, error) {
+	ids := make([]*ActorExternalID, 0)
+
+	err := db.Model(&ids).
+		Where("actor_id IN (?)", pg.In(actorIDs)).
+		Order("actor_id ASC", "source ASC").
+		Select()
 
-	return err
+	return ids, err
 }
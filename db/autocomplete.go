@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// MinSimilarity is the trigram similarity threshold, same as pg_trgm default.
+const MinSimilarity = 0.3
+
+const suggestQuery = `
+SELECT type, id, name, score FROM (
+	SELECT 'film' AS type, film.id, film.name,
+		lower(film.name) LIKE ?1 AS prefix, similarity(lower(film.name), ?0) AS score
+	FROM films AS film
+	WHERE ?2 IN ('', 'film') AND (lower(film.name) LIKE ?1 OR lower(film.name) % ?0)
+	UNION ALL
+	SELECT 'actor' AS type, actor.id, actor.name,
+		lower(actor.name) LIKE ?1 AS prefix, similarity(lower(actor.name), ?0) AS score
+	FROM actors AS actor
+	WHERE ?2 IN ('', 'actor') AND (lower(actor.name) LIKE ?1 OR lower(actor.name) % ?0)
+) AS suggestions
+ORDER BY prefix DESC, score DESC, name ASC
+LIMIT ?3`
+
+type Suggestion struct {
+	Type  string  `json:"type"`
+	ID    int64   `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// Suggester returns names starting with or similar to the query,
+// suggestType limits results to one entity type, empty means both.
+type Suggester interface {
+	Suggest(ctx context.Context, query string, suggestType string, limit int) ([]*Suggestion, error)
+}
+
+// trigramUpdates are applied only for PGSuggester, pg_trgm may be missing
+// or not allowed to be created. Trigram indexes serve both prefix and typo
+// tolerant autocomplete.
+var trigramUpdates = []string{
+	"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+	"CREATE INDEX IF NOT EXISTS films_name_trgm_idx ON films USING GIN (lower(name) gin_trgm_ops)",
+	"CREATE INDEX IF NOT EXISTS actors_name_trgm_idx ON actors USING GIN (lower(name) gin_trgm_ops)",
+}
+
+// CreateTrigramIndexes prepares the database for PGSuggester.
+func CreateTrigramIndexes(db *pg.DB) error {
+	for _, query := range trigramUpdates {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PGSuggester uses prefix search and pg_trgm similarity over indexed names.
+type PGSuggester struct {
+	db *pg.DB
+}
+
+func NewPGSuggester(db *pg.DB) *PGSuggester {
+	return &PGSuggester{db: db}
+}
+
+func (s *PGSuggester) Suggest(ctx context.Context, query string, suggestType string, limit int) ([]*Suggestion, error) {
+	suggestions := make([]*Suggestion, 0)
+	query = strings.ToLower(query)
+	prefix := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query) + "%"
+
+	_, err := s.db.WithContext(ctx).Query(&suggestions, suggestQuery, query, prefix, suggestType, limit)
+
+	return suggestions, err
+}
+
+// LoadSuggestions lists all film and actor names for in-memory suggesters.
+func LoadSuggestions(db *pg.DB) ([]*Suggestion, error) {
+	suggestions := make([]*Suggestion, 0)
+
+	_, err := db.Query(&suggestions, `
+		SELECT 'film' AS type, id, name FROM films
+		UNION ALL
+		SELECT 'actor' AS type, id, name FROM actors`)
+
+	return suggestions, err
+}
+
+// MemorySuggester keeps names in memory and computes trigram similarity
+// the same way pg_trgm does, names are reloaded after refresh interval.
+type MemorySuggester struct {
+	load     func() ([]*Suggestion, error)
+	refresh  time.Duration
+	mu       sync.RWMutex
+	entries  []memoryEntry
+	loadedAt time.Time
+}
+
+type memoryEntry struct {
+	suggestion *Suggestion
+	lower      string
+	trigrams   map[string]struct{}
+}
+
+func NewMemorySuggester(load func() ([]*Suggestion, error), refresh time.Duration) *MemorySuggester {
+	return &MemorySuggester{load: load, refresh: refresh}
+}
+
+func (s *MemorySuggester) Suggest(ctx context.Context, query string, suggestType string, limit int) ([]*Suggestion, error) {
+	entries, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	queryTrigrams := trigrams(query)
+
+	type match struct {
+		suggestion Suggestion
+		prefix     bool
+	}
+	matches := make([]match, 0)
+	for _, entry := range entries {
+		if suggestType != "" && entry.suggestion.Type != suggestType {
+			continue
+		}
+		score := similarity(queryTrigrams, entry.trigrams)
+		prefix := strings.HasPrefix(entry.lower, query)
+		if !prefix && score < MinSimilarity {
+			continue
+		}
+		suggestion := *entry.suggestion
+		suggestion.Score = score
+		matches = append(matches, match{suggestion: suggestion, prefix: prefix})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].prefix != matches[j].prefix {
+			return matches[i].prefix
+		}
+		if matches[i].suggestion.Score != matches[j].suggestion.Score {
+			return matches[i].suggestion.Score > matches[j].suggestion.Score
+		}
+		return matches[i].suggestion.Name < matches[j].suggestion.Name
+	})
+
+	suggestions := make([]*Suggestion, 0, limit)
+	for i := 0; i < len(matches) && i < limit; i++ {
+		suggestions = append(suggestions, &matches[i].suggestion)
+	}
+	return suggestions, nil
+}
+
+func (s *MemorySuggester) current() ([]memoryEntry, error) {
+	s.mu.RLock()
+	if s.entries != nil && time.Since(s.loadedAt) < s.refresh {
+		defer s.mu.RUnlock()
+		return s.entries, nil
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries != nil && time.Since(s.loadedAt) < s.refresh {
+		return s.entries, nil
+	}
+
+	suggestions, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]memoryEntry, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		lower := strings.ToLower(suggestion.Name)
+		entries = append(entries, memoryEntry{
+			suggestion: suggestion,
+			lower:      lower,
+			trigrams:   trigrams(lower),
+		})
+	}
+	s.entries = entries
+	s.loadedAt = time.Now()
+
+	return s.entries, nil
+}
+
+// trigrams splits text into words and pads each with two spaces in front
+// and one behind before taking every three letters, like pg_trgm.
+func trigrams(text string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+func similarity(a map[string]struct{}, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
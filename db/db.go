@@ -1,14 +1,118 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"filmoteka/config"
+	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/go-pg/pg/v10/orm"
 )
 
+// schemaUpdates are applied after tables creation to bring existing
+// databases up to date with the models.
+var schemaUpdates = []string{
+	"ALTER TABLE film_to_actors ADD COLUMN IF NOT EXISTS role text",
+	// Full text search vectors keep both russian and english stems.
+	`ALTER TABLE films ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+		setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'B')) STORED`,
+	`ALTER TABLE actors ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		to_tsvector('simple', coalesce(name, '')) ||
+		to_tsvector('russian', coalesce(name, '')) ||
+		to_tsvector('english', coalesce(name, ''))) STORED`,
+	"CREATE INDEX IF NOT EXISTS films_search_idx ON films USING GIN (search_vector)",
+	"CREATE INDEX IF NOT EXISTS actors_search_idx ON actors USING GIN (search_vector)",
+	// One value per source for every entity and one entity per value.
+	"CREATE UNIQUE INDEX IF NOT EXISTS film_external_ids_value_idx ON film_external_ids (source, value)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS film_external_ids_source_idx ON film_external_ids (film_id, source)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS actor_external_ids_value_idx ON actor_external_ids (source, value)",
+	"CREATE UNIQUE INDEX IF NOT EXISTS actor_external_ids_source_idx ON actor_external_ids (actor_id, source)",
+	// Outbox dispatcher and retries look for pending rows only.
+	"CREATE INDEX IF NOT EXISTS events_pending_idx ON events (id) WHERE NOT dispatched",
+	// Event ids are taken from the catalogue version, it starts after
+	// events recorded before it existed.
+	"INSERT INTO catalogue_versions (id, version) SELECT 1, COALESCE(MAX(id), 0) FROM events ON CONFLICT (id) DO NOTHING",
+	"CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending'",
+	"CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, id)",
+}
+
+// SchemaVersion is a single row with the number of schemaUpdates applied
+// to the database.
+type SchemaVersion struct {
+	ID        int
+	Version   int       `pg:",use_zero"`
+	AppliedAt time.Time `pg:"default:now()"`
+}
+
+// schemaVersionID is the id of the only row of schema versions.
+const schemaVersionID = 1
+
+// models are tables created on start.
+var models = []interface{}{
+	(*User)(nil),
+	(*Film)(nil),
+	(*Actor)(nil),
+	(*FilmToActor)(nil),
+	(*FilmExternalID)(nil),
+	(*ActorExternalID)(nil),
+	(*IdempotencyRecord)(nil),
+	(*Event)(nil),
+	(*CatalogueVersion)(nil),
+	(*Webhook)(nil),
+	(*WebhookDelivery)(nil),
+	(*SchemaVersion)(nil),
+}
+
+// Selection limits queried columns and relations, columns and relation
+// columns are all selected when empty.
+type Selection struct {
+	Columns         []string
+	Relation        bool
+	RelationColumns []string
+	ExternalIDs     bool
+	// Limit and Offset page lists, all rows are selected when Limit is zero.
+	Limit  int
+	Offset int
+}
+
+// AllWithRelation selects all columns of the model and its relation.
+var AllWithRelation = &Selection{Relation: true}
+
+func (s *Selection) apply(q *orm.Query, relation string) *orm.Query {
+	if len(s.Columns) > 0 {
+		// Primary key is needed to join relation.
+		q = q.Column("id")
+		for _, column := range s.Columns {
+			if column != "id" {
+				q = q.Column(column)
+			}
+		}
+	}
+	if s.Limit > 0 {
+		q = q.Limit(s.Limit).Offset(s.Offset)
+	}
+	if s.ExternalIDs {
+		q = q.Relation("ExternalIDs")
+	}
+	if !s.Relation {
+		return q
+	}
+	if len(s.RelationColumns) == 0 {
+		return q.Relation(relation)
+	}
+	for _, column := range s.RelationColumns {
+		q = q.Relation(relation + "." + column)
+	}
+	return q
+}
+
 func init() {
 	// Register many to many model so ORM can better recognize m2m relation.
 	// This should be done before dependant models are used.
@@ -30,12 +134,16 @@ func StartDB(cnf *config.Config) (*pg.DB, error) {
 
 	db := pg.Connect(opts)
 	err = createManyToManyTables(db, cnf.Env)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 	if cnf.Env == "test" {
 		initUsers(db)
 	}
 
 	slog.Info("Success start PostgrasDB")
-	return db, err
+	return db, nil
 }
 
 func initUsers(db *pg.DB) {
@@ -57,12 +165,6 @@ func initUsers(db *pg.DB) {
 }
 
 func createManyToManyTables(db *pg.DB, env string) error {
-	models := []interface{}{
-		(*User)(nil),
-		(*Film)(nil),
-		(*Actor)(nil),
-		(*FilmToActor)(nil),
-	}
 	temp_val := false
 	if env == "test" {
 		temp_val = true
@@ -76,5 +178,49 @@ func createManyToManyTables(db *pg.DB, env string) error {
 			return err
 		}
 	}
+	for _, query := range schemaUpdates {
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Instances of an older version do not lower the recorded one.
+	_, err := db.Model(&SchemaVersion{ID: schemaVersionID, Version: len(schemaUpdates), AppliedAt: time.Now()}).
+		OnConflict("(id) DO UPDATE").
+		Set("version = GREATEST(schema_version.version, EXCLUDED.version), applied_at = EXCLUDED.applied_at").
+		Insert()
+	return err
+}
+
+// CheckSchema returns an error naming tables of the models missing in the
+// database, like when it was replaced after start, or when not all
+// schemaUpdates were applied to it.
+func CheckSchema(ctx context.Context, db *pg.DB) error {
+	tables := make([]string, 0, len(models))
+	for _, model := range models {
+		tables = append(tables, string(db.Model(model).TableModel().Table().SQLName))
+	}
+	var missing []string
+	_, err := db.WithContext(ctx).Query(pg.Scan(pg.Array(&missing)),
+		"SELECT coalesce(array_agg(t), '{}') FROM unnest(?::text[]) t WHERE to_regclass(t) IS NULL", pg.Array(tables))
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables %s", strings.Join(missing, ", "))
+	}
+
+	version := &SchemaVersion{ID: schemaVersionID}
+	err = db.WithContext(ctx).Model(version).WherePK().Select()
+	if errors.Is(err, pg.ErrNoRows) {
+		return errors.New("schema updates were not applied")
+	}
+	if err != nil {
+		return err
+	}
+	if version.Version < len(schemaUpdates) {
+		return fmt.Errorf("schema version %d, want %d", version.Version, len(schemaUpdates))
+	}
 	return nil
 }
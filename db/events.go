@@ -0,0 +1,198 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// Entities of events, used to filter them.
+const (
+	EntityFilm  = "film"
+	EntityActor = "actor"
+	EntityCast  = "cast"
+)
+
+var Entities = []string{EntityFilm, EntityActor, EntityCast}
+
+const (
+	EventFilmCreated  = "film.created"
+	EventFilmUpdated  = "film.updated"
+	EventFilmDeleted  = "film.deleted"
+	EventActorCreated = "actor.created"
+	EventActorUpdated = "actor.updated"
+	EventActorDeleted = "actor.deleted"
+	EventCastChanged  = "cast.changed"
+)
+
+var EventTypes = []string{
+	EventFilmCreated, EventFilmUpdated, EventFilmDeleted,
+	EventActorCreated, EventActorUpdated, EventActorDeleted,
+	EventCastChanged,
+}
+
+// IsEventMask reports whether s is an event type, "<entity>.*" or "*".
+func IsEventMask(s string) bool {
+	if s == "*" || slices.Contains(EventTypes, s) {
+		return true
+	}
+	entity, found := strings.CutSuffix(s, ".*")
+	return found && slices.Contains(Entities, entity)
+}
+
+// Event is a catalogue change written in the same transaction as the
+// change itself, so it is an outbox for webhooks and a log for streams.
+// Ids grow in the order events are committed, see recordEvent.
+type Event struct {
+	ID       int64           `json:"id"`
+	Type     string          `json:"type" pg:",notnull"`
+	Entity   string          `json:"entity" pg:",notnull"`
+	EntityID int64           `json:"entity_id" pg:",use_zero"`
+	Payload  json.RawMessage `json:"payload" pg:"type:jsonb"`
+	// Dispatched is set when webhook deliveries of the event are created.
+	Dispatched bool      `json:"-" pg:",use_zero"`
+	CreatedAt  time.Time `json:"created_at" pg:"default:now()"`
+}
+
+// CatalogueVersion is a single row counting catalogue writes, its version
+// is the id of the latest event.
+type CatalogueVersion struct {
+	ID      int
+	Version int64 `pg:",use_zero"`
+}
+
+// catalogueVersionID is the id of the only row of catalogue versions.
+const catalogueVersionID = 1
+
+// FilmEvent is a payload of film events, only id is set for deleted films.
+type FilmEvent struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Date        *time.Time `json:"date,omitempty"`
+	Rate        *int       `json:"rate,omitempty"`
+}
+
+// ActorEvent is a payload of actor events, only id is set for deleted actors.
+type ActorEvent struct {
+	ID    int64      `json:"id"`
+	Name  string     `json:"name,omitempty"`
+	Sex   string     `json:"sex,omitempty"`
+	Birth *time.Time `json:"birthday,omitempty"`
+}
+
+// CastEvent is a payload of cast.changed with the whole current cast.
+type CastEvent struct {
+	FilmID int           `json:"film_id"`
+	Actors []*CastMember `json:"actors"`
+}
+
+// GetEvents returns events after the id in order, empty entities mean all.
+func GetEvents(db *pg.DB, afterID int64, entities []string, limit int) ([]*Event, error) {
+	events := make([]*Event, 0)
+
+	q := db.Model(&events).
+		Where("event.id > ?", afterID).
+		Order("event.id ASC").
+		Limit(limit)
+	if len(entities) > 0 {
+		q = q.Where("event.entity IN (?)", pg.In(entities))
+	}
+	err := q.Select()
+
+	return events, err
+}
+
+// LastEventID is the id of the latest event, zero when there are none.
+func LastEventID(db *pg.DB) (int64, error) {
+	var id int64
+	_, err := db.QueryOne(pg.Scan(&id), "SELECT COALESCE(MAX(id), 0) FROM events")
+
+	return id, err
+}
+
+// GetCatalogueVersion returns the version of the last committed catalogue
+// write, a write in progress keeps the previous one until it commits.
+func GetCatalogueVersion(db *pg.DB) (int64, error) {
+	version := &CatalogueVersion{ID: catalogueVersionID}
+	err := db.Model(version).WherePK().Select()
+
+	return version.Version, err
+}
+
+func recordEvent(db orm.DB, eventType string, entity string, entityID int64, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// The catalogue version row stays locked until the write commits, so
+	// a concurrent write takes the next id only after this one is visible
+	// and streams never skip an event committed later with a lower id.
+	version := &CatalogueVersion{ID: catalogueVersionID}
+	res, err := db.Model(version).
+		Set("version = version + 1").
+		WherePK().
+		Returning("version").
+		Update()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return errors.New("catalogue version is missing")
+	}
+
+	_, err = db.Model(&Event{
+		ID:       version.Version,
+		Type:     eventType,
+		Entity:   entity,
+		EntityID: entityID,
+		Payload:  data,
+	}).Insert()
+
+	return err
+}
+
+func recordFilmEvent(db orm.DB, eventType string, film *Film) error {
+	payload := &FilmEvent{ID: film.ID}
+	if eventType != EventFilmDeleted {
+		payload.Name = film.Name
+		payload.Description = film.Description
+		payload.Date = &film.Date
+		payload.Rate = &film.Rate
+	}
+	return recordEvent(db, eventType, EntityFilm, int64(film.ID), payload)
+}
+
+func recordActorEvent(db orm.DB, eventType string, actor *Actor) error {
+	payload := &ActorEvent{ID: actor.ID}
+	if eventType != EventActorDeleted {
+		payload.Name = actor.Name
+		payload.Sex = actor.Sex
+		payload.Birth = &actor.Birth
+	}
+	return recordEvent(db, eventType, EntityActor, actor.ID, payload)
+}
+
+// recordCastEvent reads the current cast of the film into the event.
+func recordCastEvent(db orm.DB, filmID int) error {
+	links := make([]*FilmToActor, 0)
+	err := db.Model(&links).
+		Where("film_id = ?", filmID).
+		Order("actor_id ASC").
+		Select()
+	if err != nil {
+		return err
+	}
+
+	payload := &CastEvent{FilmID: filmID, Actors: make([]*CastMember, 0, len(links))}
+	for _, link := range links {
+		payload.Actors = append(payload.Actors, &CastMember{ActorID: int64(link.ActorID), Role: link.Role})
+	}
+	return recordEvent(db, EventCastChanged, EntityCast, int64(filmID), payload)
+}
@@ -0,0 +1,248 @@
+package db
+
+import (
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+// Sources of external identifiers, values are unique per source.
+const (
+	SourceIMDb      = "imdb"
+	SourceKinopoisk = "kinopoisk"
+	SourceTMDB      = "tmdb"
+)
+
+var ExternalSources = []string{SourceIMDb, SourceKinopoisk, SourceTMDB}
+
+func IsExternalSource(source string) bool {
+	for _, s := range ExternalSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+type FilmExternalID struct {
+	FilmID int    `json:"-" pg:",notnull"`
+	Source string `json:"source" pg:",notnull"`
+	Value  string `json:"value" pg:",notnull"`
+}
+
+type ActorExternalID struct {
+	ActorID int64  `json:"-" pg:",notnull"`
+	Source  string `json:"source" pg:",notnull"`
+	Value   string `json:"value" pg:",notnull"`
+}
+
+// CastMember is an actor of the film with the role, used to reconcile cast.
+type CastMember struct {
+	ActorID int64  `json:"actor_id"`
+	Role    string `json:"role"`
+}
+
+func GetFilmByExternalID(db *pg.DB, source string, value string, sel *Selection) (*Film, error) {
+	film := &Film{}
+
+	err := sel.apply(db.Model(film), "Actors").
+		Where("film.id = (SELECT film_id FROM film_external_ids WHERE source = ? AND value = ?)", source, value).
+		Select()
+
+	return film, err
+}
+
+func GetActorByExternalID(db *pg.DB, source string, value string, sel *Selection) (*Actor, error) {
+	actor := &Actor{}
+
+	err := sel.apply(db.Model(actor), "Films").
+		Where("actor.id = (SELECT actor_id FROM actor_external_ids WHERE source = ? AND value = ?)", source, value).
+		Select()
+
+	return actor, err
+}
+
+// FindActorByExternalID returns only id and name of the actor.
+func FindActorByExternalID(db orm.DB, source string, value string) (*Actor, error) {
+	actor := &Actor{}
+
+	err := db.Model(actor).
+		Column("actor.id", "actor.name").
+		Join("JOIN actor_external_ids AS ext ON ext.actor_id = actor.id").
+		Where("ext.source = ? AND ext.value = ?", source, value).
+		Select()
+
+	return actor, err
+}
+
+// UpsertFilmByExternalID creates the film or replaces fields of the one
+// with the external id. Other external ids are attached to the film, cast
+// is reconciled unless it is nil. Created is true for a new film.
+func UpsertFilmByExternalID(db orm.DB, source string, value string, req *Film, cast []*CastMember, externalIDs map[string]string) (*Film, bool, error) {
+	link := &FilmExternalID{}
+	err := db.Model(link).
+		Where("source = ? AND value = ?", source, value).
+		For("UPDATE").
+		Select()
+	created := err == pg.ErrNoRows
+	if err != nil && !created {
+		return nil, false, err
+	}
+
+	if created {
+		_, err = db.Model(req).Insert()
+	} else {
+		req.ID = link.FilmID
+		_, err = db.Model(req).WherePK().Update()
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids := map[string]string{}
+	for s, v := range externalIDs {
+		ids[s] = v
+	}
+	ids[source] = value
+	for s, v := range ids {
+		_, err = db.Model(&FilmExternalID{FilmID: req.ID, Source: s, Value: v}).
+			OnConflict("(film_id, source) DO UPDATE").
+			Set("value = EXCLUDED.value").
+			Insert()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if cast != nil {
+		err = ReconcileFilmActors(db, req.ID, cast)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	film := &Film{}
+	err = db.Model(film).
+		Relation("Actors").
+		Relation("ExternalIDs").
+		Where("film.id = ?", req.ID).
+		Select()
+	if err != nil {
+		return nil, false, err
+	}
+
+	eventType := EventFilmUpdated
+	if created {
+		eventType = EventFilmCreated
+	}
+	return film, created, recordFilmEvent(db, eventType, film)
+}
+
+// UpsertActorByExternalID creates the actor or replaces fields of the one
+// with the external id, other external ids are attached to the actor.
+func UpsertActorByExternalID(db orm.DB, source string, value string, req *Actor, externalIDs map[string]string) (*Actor, bool, error) {
+	link := &ActorExternalID{}
+	err := db.Model(link).
+		Where("source = ? AND value = ?", source, value).
+		For("UPDATE").
+		Select()
+	created := err == pg.ErrNoRows
+	if err != nil && !created {
+		return nil, false, err
+	}
+
+	if created {
+		_, err = db.Model(req).Insert()
+	} else {
+		req.ID = link.ActorID
+		_, err = db.Model(req).WherePK().Update()
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	ids := map[string]string{}
+	for s, v := range externalIDs {
+		ids[s] = v
+	}
+	ids[source] = value
+	for s, v := range ids {
+		_, err = db.Model(&ActorExternalID{ActorID: req.ID, Source: s, Value: v}).
+			OnConflict("(actor_id, source) DO UPDATE").
+			Set("value = EXCLUDED.value").
+			Insert()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	actor := &Actor{}
+	err = db.Model(actor).
+		Relation("Films").
+		Relation("ExternalIDs").
+		Where("actor.id = ?", req.ID).
+		Select()
+	if err != nil {
+		return nil, false, err
+	}
+
+	eventType := EventActorUpdated
+	if created {
+		eventType = EventActorCreated
+	}
+	return actor, created, recordActorEvent(db, eventType, actor)
+}
+
+// ReconcileFilmActors changes cast of the film to the given one touching
+// only links which are added, removed or got another role, cast.changed
+// is recorded when anything is touched.
+func ReconcileFilmActors(db orm.DB, filmID int, cast []*CastMember) error {
+	current := make([]*FilmToActor, 0)
+	err := db.Model(&current).Where("film_id = ?", filmID).Select()
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[int]string, len(cast))
+	for _, member := range cast {
+		wanted[int(member.ActorID)] = member.Role
+	}
+
+	changed := false
+	for _, link := range current {
+		role, ok := wanted[link.ActorID]
+		changed = changed || !ok || role != link.Role
+		switch {
+		case !ok:
+			_, err = db.Model((*FilmToActor)(nil)).
+				Where("film_id = ? AND actor_id = ?", filmID, link.ActorID).
+				Delete()
+		case role != link.Role:
+			_, err = db.Model((*FilmToActor)(nil)).
+				Set("role = ?", role).
+				Where("film_id = ? AND actor_id = ?", filmID, link.ActorID).
+				Update()
+		}
+		if err != nil {
+			return err
+		}
+		delete(wanted, link.ActorID)
+	}
+
+	for _, member := range cast {
+		role, ok := wanted[int(member.ActorID)]
+		if !ok {
+			continue
+		}
+		_, err = db.Model(&FilmToActor{FilmID: filmID, ActorID: int(member.ActorID), Role: role}).Insert()
+		if err != nil {
+			return err
+		}
+		delete(wanted, int(member.ActorID))
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return recordCastEvent(db, filmID)
+}
@@ -4,8 +4,13 @@ import (
 	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
+// DateLayout is a format of film dates and actor birthdays in requests,
+// imports and exports.
+const DateLayout = "2006-01-02"
+
 type Film struct {
 	ID          int       `json:"id"`
 	Name        string    `json:"name" validate:"min=1,max=150"`
@@ -13,51 +18,93 @@ type Film struct {
 	Date        time.Time `json:"date"`
 	Rate        int       `json:"rate" validate:"gte=0,lte=10"`
 	Actors      []Actor   `json:"actors" pg:"many2many:film_to_actors"`
+
+	ExternalIDs []*FilmExternalID `json:"external_ids,omitempty" pg:"rel:has-many"`
 }
 
 type FilmToActor struct {
 	FilmID  int
 	ActorID int
+	Role    string
 }
 
-func GetFilms(db *pg.DB, sortBy string, filter []string) ([]*Film, error) {
+// FilmRow is a flat film for exports, actors are names of its cast.
+type FilmRow struct {
+	ID          int
+	Name        string
+	Description string
+	Date        time.Time
+	Rate        int
+	Actors      []string `pg:",array"`
+}
+
+func GetFilms(db *pg.DB, sortBy string, filter []string, sel *Selection) ([]*Film, error) {
 	films := make([]*Film, 0)
-	var err error
-	if cap(filter) > 0 {
-		err = db.Model(&films).Relation("Actors").Where("? like '%' || ? || '%'", pg.Ident(filter[0]), filter[1]).Order(sortBy).
-			Select()
-		err = db.Model(&films).Relation("Actors").Where("? like '%' || ? || '%'", pg.Ident(filter[0]), filter[1]).Order(sortBy).
-			Select()
-	} else {
-		err = db.Model(&films).Relation("Actors").Order(sortBy).
-			Select()
-	}
+
+	err := filterFilms(sel.apply(db.Model(&films), "Actors"), sortBy, filter).
+		Select()
 
 	return films, err
 }
 
-func CreateFilm(db *pg.DB, req *Film, req_actors []int) (*Film, error) {
-	_, err := db.Model(req).Insert()
+// EachFilm streams films filtered and sorted like GetFilms, fn is called
+// for every row as it is read from the database.
+func EachFilm(db *pg.DB, sortBy string, filter []string, fn func(*FilmRow) error) error {
+	q := db.Model((*Film)(nil)).
+		Column("film.id", "film.name", "film.description", "film.date", "film.rate").
+		ColumnExpr(`ARRAY(SELECT a.name FROM film_to_actors AS fa
+			JOIN actors AS a ON a.id = fa.actor_id
+			WHERE fa.film_id = film.id ORDER BY a.name) AS actors`)
+
+	return filterFilms(q, sortBy, filter).
+		ForEach(fn)
+}
 
-	for _, actor_id := range req_actors {
-		req := FilmToActor{req.ID, actor_id}
-		_, err = db.Model(&req).Insert()
+func filterFilms(q *orm.Query, sortBy string, filter []string) *orm.Query {
+	if cap(filter) > 0 {
+		q = q.Where("? like '%' || ? || '%'", pg.Ident(filter[0]), filter[1])
 	}
+	// Id breaks ties so pages of equally sorted films do not overlap.
+	return q.Order(sortBy).Order("film.id ASC")
+}
+
+func GetFilm(db *pg.DB, filmID int, sel *Selection) (*Film, error) {
+	film := &Film{}
+
+	err := sel.apply(db.Model(film), "Actors").
+		Where("film.id = ?", filmID).
+		Select()
 
+	return film, err
+}
+
+func CreateFilm(db orm.DB, req *Film, req_actors []int, roles map[int]string) (*Film, error) {
+	_, err := db.Model(req).Insert()
 	if err != nil {
 		return nil, err
 	}
 
+	for _, actor_id := range req_actors {
+		req := FilmToActor{FilmID: req.ID, ActorID: actor_id, Role: roles[actor_id]}
+		_, err = db.Model(&req).Insert()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	film := &Film{}
 	err = db.Model(film).
 		Relation("Actors").
 		Where("film.id = ?", req.ID).
 		Select()
+	if err != nil {
+		return nil, err
+	}
 
-	return film, err
+	return film, recordFilmEvent(db, EventFilmCreated, film)
 }
 
-func UpdateFilm(db *pg.DB, req *Film) (*Film, error) {
+func UpdateFilm(db orm.DB, req *Film) (*Film, error) {
 	_, err := db.Model(req).
 		WherePK().
 		Update()
@@ -71,11 +118,14 @@ func UpdateFilm(db *pg.DB, req *Film) (*Film, error) {
 		Relation("Actors").
 		Where("film.id = ?", req.ID).
 		Select()
+	if err != nil {
+		return nil, err
+	}
 
-	return film, err
+	return film, recordFilmEvent(db, EventFilmUpdated, film)
 }
 
-func DeleteFilm(db *pg.DB, filmID int64) error {
+func DeleteFilm(db orm.DB, filmID int64) error {
 	film := &Film{}
 	film2actor := &FilmToActor{}
 
@@ -88,7 +138,82 @@ func DeleteFilm(db *pg.DB, filmID int64) error {
 	}
 
 	_, err = db.Model(film).WherePK().Delete()
+	if err != nil {
+		return err
+	}
 	_, err = db.Model(film2actor).Where("film_id = ?", film.ID).Delete()
+	if err != nil {
+		return err
+	}
+	_, err = db.Model((*FilmExternalID)(nil)).Where("film_id = ?", film.ID).Delete()
+	if err != nil {
+		return err
+	}
+
+	return recordFilmEvent(db, EventFilmDeleted, film)
+}
+
+// FindFilm looks film up by its natural key, name is compared case insensitive.
+func FindFilm(db orm.DB, name string, date time.Time) (*Film, error) {
+	film := &Film{}
+
+	err := db.Model(film).
+		Where("lower(film.name) = lower(?)", name).
+		Where("film.date = ?", date).
+		Order("film.id ASC").
+		Limit(1).
+		Select()
+
+	return film, err
+}
+
+// SetFilmActors replaces cast of the film with given actors and roles.
+func SetFilmActors(db orm.DB, filmID int, actors []int, roles map[int]string) error {
+	_, err := db.Model((*FilmToActor)(nil)).Where("film_id = ?", filmID).Delete()
+	if err != nil {
+		return err
+	}
+
+	for _, actorID := range actors {
+		link := &FilmToActor{FilmID: filmID, ActorID: actorID, Role: roles[actorID]}
+		_, err = db.Model(link).Insert()
+		if err != nil {
+			return err
+		}
+	}
+	return recordCastEvent(db, filmID)
+}
+
+// GetFilmsByIDs loads films without relations, it is used to batch lookups.
+func GetFilmsByIDs(db *pg.DB, filmIDs []int) ([]*Film, error) {
+	films := make([]*Film, 0, len(filmIDs))
+
+	err := db.Model(&films).
+		Where("film.id IN (?)", pg.In(filmIDs)).
+		Select()
+
+	return films, err
+}
+
+// GetCasts returns cast links of the films ordered by actor.
+func GetCasts(db *pg.DB, filmIDs []int) ([]*FilmToActor, error) {
+	links := make([]*FilmToActor, 0)
+
+	err := db.Model(&links).
+		Where("film_id IN (?)", pg.In(filmIDs)).
+		Order("film_id ASC", "actor_id ASC").
+		Select()
+
+	return links, err
+}
+
+func GetFilmsExternalIDs(db *pg.DB, filmIDs []int) ([]*FilmExternalID, error) {
+	ids := make([]*FilmExternalID, 0)
+
+	err := db.Model(&ids).
+		Where("film_id IN (?)", pg.In(filmIDs)).
+		Order("film_id ASC", "source ASC").
+		Select()
 
-	return err
+	return ids, err
 }
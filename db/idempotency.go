@@ -0,0 +1,71 @@
+package db
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// IdempotencyRecord keeps response of a request made with Idempotency-Key,
+// status is zero while the first request is still running.
+type IdempotencyRecord struct {
+	tableName struct{} `pg:"idempotency_keys"`
+
+	Key         string `pg:",pk"`
+	Fingerprint string `pg:",notnull"`
+	Status      int    `pg:",use_zero"`
+	Header      http.Header
+	Body        []byte
+	CreatedAt   time.Time `pg:",notnull"`
+	ExpiresAt   time.Time `pg:",notnull"`
+}
+
+// ReserveIdempotencyKey stores the record unless the key is already taken
+// by a record which has not expired yet, false means the key is taken.
+func ReserveIdempotencyKey(db *pg.DB, rec *IdempotencyRecord) (bool, error) {
+	res, err := db.Model(rec).
+		OnConflict("(key) DO UPDATE").
+		Set("fingerprint = EXCLUDED.fingerprint, status = 0, header = NULL, body = NULL").
+		Set("created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at").
+		Where("idempotency_record.expires_at < EXCLUDED.created_at").
+		Insert()
+	if err != nil {
+		return false, err
+	}
+	return res.RowsAffected() > 0, nil
+}
+
+func GetIdempotencyRecord(db *pg.DB, key string) (*IdempotencyRecord, error) {
+	rec := &IdempotencyRecord{Key: key}
+
+	err := db.Model(rec).WherePK().Select()
+
+	return rec, err
+}
+
+// SaveIdempotencyResponse stores status, header and body of the record.
+func SaveIdempotencyResponse(db *pg.DB, rec *IdempotencyRecord) error {
+	_, err := db.Model(rec).
+		Column("status", "header", "body").
+		WherePK().
+		Update()
+
+	return err
+}
+
+// ReleaseIdempotencyKey frees the key so the request can be retried.
+func ReleaseIdempotencyKey(db *pg.DB, key string) error {
+	_, err := db.Model((*IdempotencyRecord)(nil)).Where("key = ?", key).Delete()
+
+	return err
+}
+
+// PurgeIdempotencyKeys deletes records expired before now.
+func PurgeIdempotencyKeys(db *pg.DB, now time.Time) (int, error) {
+	res, err := db.Model((*IdempotencyRecord)(nil)).Where("expires_at < ?", now).Delete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"unicode"
+
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	SearchFilm  = "film"
+	SearchActor = "actor"
+
+	LangRussian = "russian"
+	LangEnglish = "english"
+)
+
+// headlineOptions marks matched words in snippets returned by ts_headline.
+const headlineOptions = "StartSel=<b>, StopSel=</b>, MaxWords=35, MinWords=15, MaxFragments=2"
+
+const searchQuery = `
+WITH query AS (SELECT websearch_to_tsquery(?0::regconfig, ?1) AS q)
+SELECT type, id, name, snippet, rank FROM (
+	SELECT 'film' AS type, film.id, film.name,
+		ts_headline(?0::regconfig, concat_ws(' ', film.name, film.description), query.q, ?2) AS snippet,
+		ts_rank(film.search_vector, query.q) AS rank
+	FROM films AS film, query
+	WHERE ?3 IN ('', 'film') AND film.search_vector @@ query.q
+	UNION ALL
+	SELECT 'actor' AS type, actor.id, actor.name,
+		ts_headline(?0::regconfig, actor.name, query.q, ?2) AS snippet,
+		ts_rank(actor.search_vector, query.q) AS rank
+	FROM actors AS actor, query
+	WHERE ?3 IN ('', 'actor') AND actor.search_vector @@ query.q
+) AS results
+ORDER BY rank DESC, name ASC
+LIMIT ?4`
+
+type SearchResult struct {
+	Type    string  `json:"type"`
+	ID      int64   `json:"id"`
+	Name    string  `json:"name"`
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// SearchLanguage picks text search configuration by the query alphabet.
+func SearchLanguage(query string) string {
+	for _, r := range query {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return LangRussian
+		}
+	}
+	return LangEnglish
+}
+
+// Search looks for films by name and description and for actors by name,
+// searchType limits results to one entity type, empty means both.
+func Search(db *pg.DB, query string, lang string, searchType string, limit int) ([]*SearchResult, error) {
+	results := make([]*SearchResult, 0)
+
+	_, err := db.Query(&results, searchQuery, lang, query, headlineOptions, searchType, limit)
+
+	return results, err
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"errors"
+	"filmoteka/errs"
 	"log/slog"
 
 	"github.com/go-pg/pg/v10"
@@ -12,10 +13,14 @@ const (
 	Client = "client"
 )
 
+// ErrWrongCredentials is returned for unknown users and wrong passwords
+// alike, so clients can not find out which usernames exist.
+var ErrWrongCredentials = errs.Unauthorized("wrong username or password")
+
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
-	Role     string `json:"date" validate:"oneof admin client"`
+	Role     string `json:"role" validate:"oneof=admin client"`
 }
 
 func GetUser(db *pg.DB, username string, password string) (string, error) {
@@ -24,16 +29,17 @@ func GetUser(db *pg.DB, username string, password string) (string, error) {
 	err := db.Model(user).Where("username = ?", username).
 		Select()
 
-	if err != nil {
+	if errors.Is(err, pg.ErrNoRows) {
 		slog.Error("no user with such username")
-		err = errors.New("no user with such username")
+		return "", ErrWrongCredentials
+	}
+	if err != nil {
 		return "", err
 	}
 
 	if user.Password != password {
 		slog.Error("wrong password for use")
-		err = errors.New("wrong password for use")
-		return "", err
+		return "", ErrWrongCredentials
 	}
 
 	switch user.Role {
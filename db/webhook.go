@@ -0,0 +1,271 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+type Webhook struct {
+	ID  int64  `json:"id"`
+	URL string `json:"url" pg:",notnull"`
+	// Secret signs payloads, it is shown only once on creation.
+	Secret string `json:"-" pg:",notnull"`
+	// Events are event types, "film.*" like masks or "*", empty means all.
+	Events    []string  `json:"events" pg:",array"`
+	Active    bool      `json:"active" pg:",use_zero"`
+	CreatedAt time.Time `json:"created_at" pg:"default:now()"`
+}
+
+// Matches reports whether the webhook is subscribed to the event type.
+func (w *Webhook) Matches(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	entity, _, _ := strings.Cut(eventType, ".")
+	for _, mask := range w.Events {
+		if mask == "*" || mask == eventType || mask == entity+".*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one webhook, it is a log of
+// attempts as well as a queue of retries.
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id" pg:",notnull"`
+	EventID        int64      `json:"event_id" pg:",notnull"`
+	EventType      string     `json:"event_type" pg:",notnull"`
+	Status         string     `json:"status" pg:",notnull"`
+	Attempts       int        `json:"attempts" pg:",use_zero"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus int        `json:"response_status,omitempty" pg:",use_zero"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" pg:"default:now()"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+
+	Event   *Event   `json:"-" pg:"rel:has-one"`
+	Webhook *Webhook `json:"-" pg:"rel:has-one"`
+}
+
+func GetWebhooks(db *pg.DB) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0)
+
+	err := db.Model(&webhooks).
+		Order("webhook.id ASC").
+		Select()
+
+	return webhooks, err
+}
+
+func GetWebhook(db *pg.DB, webhookID int64) (*Webhook, error) {
+	webhook := &Webhook{ID: webhookID}
+
+	err := db.Model(webhook).WherePK().Select()
+
+	return webhook, err
+}
+
+func CreateWebhook(db *pg.DB, req *Webhook) (*Webhook, error) {
+	_, err := db.Model(req).
+		Returning("*").
+		Insert()
+
+	return req, err
+}
+
+// UpdateWebhook replaces url, events and activity, the secret is changed
+// only when it is set.
+func UpdateWebhook(db *pg.DB, req *Webhook) (*Webhook, error) {
+	columns := []string{"url", "events", "active"}
+	if req.Secret != "" {
+		columns = append(columns, "secret")
+	}
+
+	res, err := db.Model(req).
+		Column(columns...).
+		WherePK().
+		Update()
+	if err != nil {
+		return nil, err
+	}
+	if res.RowsAffected() == 0 {
+		return nil, pg.ErrNoRows
+	}
+
+	return GetWebhook(db, req.ID)
+}
+
+func DeleteWebhook(db *pg.DB, webhookID int64) error {
+	return db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		res, err := tx.Model((*Webhook)(nil)).Where("id = ?", webhookID).Delete()
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return pg.ErrNoRows
+		}
+		_, err = tx.Model((*WebhookDelivery)(nil)).Where("webhook_id = ?", webhookID).Delete()
+		return err
+	})
+}
+
+// GetWebhookDeliveries returns the latest deliveries of the webhook first.
+func GetWebhookDeliveries(db *pg.DB, webhookID int64, limit int) ([]*WebhookDelivery, error) {
+	_, err := GetWebhook(db, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*WebhookDelivery, 0)
+	err = db.Model(&deliveries).
+		Where("webhook_delivery.webhook_id = ?", webhookID).
+		Order("webhook_delivery.id DESC").
+		Limit(limit).
+		Select()
+
+	return deliveries, err
+}
+
+// DispatchEvents creates deliveries of not dispatched events for active
+// webhooks subscribed to them, events older than a webhook are skipped.
+// It returns the number of dispatched events.
+func DispatchEvents(db *pg.DB, limit int) (int, error) {
+	count := 0
+	err := db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		events := make([]*Event, 0)
+		err := tx.Model(&events).
+			Where("event.dispatched = FALSE").
+			Order("event.id ASC").
+			Limit(limit).
+			For("UPDATE SKIP LOCKED").
+			Select()
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		webhooks := make([]*Webhook, 0)
+		err = tx.Model(&webhooks).Where("webhook.active").Select()
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+			for _, webhook := range webhooks {
+				if event.CreatedAt.Before(webhook.CreatedAt) || !webhook.Matches(event.Type) {
+					continue
+				}
+				_, err = tx.Model(&WebhookDelivery{
+					WebhookID:     webhook.ID,
+					EventID:       event.ID,
+					EventType:     event.Type,
+					Status:        DeliveryPending,
+					NextAttemptAt: event.CreatedAt,
+				}).Insert()
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		_, err = tx.Model((*Event)(nil)).
+			Set("dispatched = TRUE").
+			Where("id IN (?)", pg.In(ids)).
+			Update()
+		count = len(events)
+		return err
+	})
+
+	return count, err
+}
+
+// ClaimDeliveries takes pending deliveries which are due and postpones
+// them by lease, so other dispatchers do not send them meanwhile.
+func ClaimDeliveries(db *pg.DB, now time.Time, lease time.Duration, limit int) ([]*WebhookDelivery, error) {
+	deliveries := make([]*WebhookDelivery, 0)
+
+	err := db.RunInTransaction(db.Context(), func(tx *pg.Tx) error {
+		err := tx.Model(&deliveries).
+			Where("webhook_delivery.status = ?", DeliveryPending).
+			Where("webhook_delivery.next_attempt_at <= ?", now).
+			Order("webhook_delivery.next_attempt_at ASC").
+			Limit(limit).
+			For("UPDATE SKIP LOCKED").
+			Select()
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.ID)
+		}
+		_, err = tx.Model((*WebhookDelivery)(nil)).
+			Set("next_attempt_at = ?", now.Add(lease)).
+			Where("id IN (?)", pg.In(ids)).
+			Update()
+		return err
+	})
+	if err != nil || len(deliveries) == 0 {
+		return deliveries, err
+	}
+
+	err = loadDeliveryRelations(db, deliveries)
+	return deliveries, err
+}
+
+func loadDeliveryRelations(db orm.DB, deliveries []*WebhookDelivery) error {
+	eventIDs := make([]int64, 0, len(deliveries))
+	webhookIDs := make([]int64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		eventIDs = append(eventIDs, delivery.EventID)
+		webhookIDs = append(webhookIDs, delivery.WebhookID)
+	}
+
+	events := make([]*Event, 0)
+	err := db.Model(&events).Where("event.id IN (?)", pg.In(eventIDs)).Select()
+	if err != nil {
+		return err
+	}
+	webhooks := make([]*Webhook, 0)
+	err = db.Model(&webhooks).Where("webhook.id IN (?)", pg.In(webhookIDs)).Select()
+	if err != nil {
+		return err
+	}
+
+	eventByID := make(map[int64]*Event, len(events))
+	for _, event := range events {
+		eventByID[event.ID] = event
+	}
+	webhookByID := make(map[int64]*Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		webhookByID[webhook.ID] = webhook
+	}
+	for _, delivery := range deliveries {
+		delivery.Event = eventByID[delivery.EventID]
+		delivery.Webhook = webhookByID[delivery.WebhookID]
+	}
+	return nil
+}
+
+// SaveDeliveryAttempt stores the result of the last attempt.
+func SaveDeliveryAttempt(db *pg.DB, delivery *WebhookDelivery) error {
+	_, err := db.Model(delivery).
+		Column("status", "attempts", "next_attempt_at", "response_status", "error", "delivered_at").
+		WherePK().
+		Update()
+
+	return err
+}
@@ -29,15 +29,57 @@ const docTemplate = `{
                 ],
                 "description": "Availible only for authenticated user, getting actors list from db",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "actors"
                 ],
                 "summary": "List actors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "films",
+                        "description": "Included relations, films, also accepted as expand",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of actors to return, also accepted as fields",
+                        "name": "fields[actors]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,rate",
+                        "description": "Comma separated fields of included films",
+                        "name": "fields[films]",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page size from 1 to 1000, all actors when omitted",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Number of actors to skip, used with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -59,30 +101,41 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "post": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for admin user, updating actor using id from request params and return actor",
+                "description": "Availible only for admin user, creating actor using data from request body and return new actor",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "actors"
                 ],
-                "summary": "Update actor",
+                "summary": "Create actor",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Actors Id",
-                        "name": "actorID",
-                        "in": "query",
-                        "required": true
+                        "example": "films",
+                        "description": "Nested relations, films",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of actors to return",
+                        "name": "fields",
+                        "in": "query"
                     },
                     {
                         "description": "actor info",
@@ -90,8 +143,15 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/db.Actor"
+                            "$ref": "#/definitions/api_models.CreateActorRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "example": "9f4c2a1e-create-film",
+                        "description": "Unique key to safely retry the request, repeated requests get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -112,42 +172,79 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
-            },
+            }
+        },
+        "/actors/bulk": {
             "post": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for admin user, creating actor using data from request body and return new actor",
+                "description": "Availible only for admin user, applying operations in one transaction (default) or each one separately in best_effort mode. Data of operations is the same as body of POST /actors and PUT /actors/{actorID}.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "actors"
                 ],
-                "summary": "Create actor",
+                "summary": "Bulk create, update and delete actors",
                 "parameters": [
                     {
-                        "description": "actor info",
-                        "name": "Actor",
+                        "description": "operations",
+                        "name": "Operations",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/db.Actor"
+                            "$ref": "#/definitions/api_models.BulkRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "example": "9f4c2a1e-create-film",
+                        "description": "Unique key to safely retry the request, repeated requests get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "all operations succeeded",
                         "schema": {
-                            "$ref": "#/definitions/api_models.ActorResponse"
+                            "$ref": "#/definitions/api_models.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "some operations failed",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.BulkResponse"
                         }
                     },
                     "400": {
@@ -161,38 +258,93 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/actors/by-external/{source}/{id}": {
+            "get": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for admin user, deleting actor using id from request params",
+                "description": "Availible only for authenticated user, getting actor by its id in IMDb, Kinopoisk or TMDB",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "actors"
                 ],
-                "summary": "Delete actor",
+                "summary": "Get actor by external id",
                 "parameters": [
                     {
+                        "enum": [
+                            "imdb",
+                            "kinopoisk",
+                            "tmdb"
+                        ],
                         "type": "string",
-                        "description": "Actors Id",
-                        "name": "actorID",
-                        "in": "query",
+                        "description": "External catalogue",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "nm0000206",
+                        "description": "Actor id in the catalogue",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "films",
+                        "description": "Included relations, films",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,external_ids",
+                        "description": "Comma separated fields of actor to return",
+                        "name": "fields[actors]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of included films",
+                        "name": "fields[films]",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ActorResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -205,49 +357,98 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/films": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for authenticated user, getting films list, they can be sorted by fields, default is rate. Also you can use filters in field.value template.",
+                "description": "Availible only for admin user, creating actor with the external id or replacing all fields of the existing one. Safe to repeat.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "films"
+                    "actors"
                 ],
-                "summary": "Get films list",
+                "summary": "Create or replace actor by external id",
                 "parameters": [
                     {
+                        "enum": [
+                            "imdb",
+                            "kinopoisk",
+                            "tmdb"
+                        ],
                         "type": "string",
-                        "example": "name",
-                        "description": "Sort by field, default rate",
-                        "name": "sortBy",
+                        "description": "External catalogue",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "nm0000206",
+                        "description": "Actor id in the catalogue",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "films",
+                        "description": "Included relations, films",
+                        "name": "include",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "example": "name.Name1",
-                        "description": "Filter by field (field.value), can be user all except actors",
-                        "name": "filter",
+                        "example": "id,name,external_ids",
+                        "description": "Comma separated fields of actor to return",
+                        "name": "fields[actors]",
                         "in": "query"
+                    },
+                    {
+                        "description": "actor info",
+                        "name": "Actor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UpsertActorRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "actor is updated",
                         "schema": {
-                            "$ref": "#/definitions/api_models.FilmsResponse"
+                            "$ref": "#/definitions/api_models.ActorResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "actor is created",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ActorResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the actor"
+                            }
                         }
                     },
                     "400": {
@@ -261,40 +462,56 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/actors/path": {
+            "get": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for admin user, updating film using data from request body and return new film",
+                "description": "Availible only for authenticated user, getting the shortest chain of actors linked by shared films (\"six degrees\")",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "films"
+                    "actors"
                 ],
-                "summary": "Update film",
+                "summary": "Get co-star path between actors",
                 "parameters": [
                     {
-                        "description": "film info",
-                        "name": "Film",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/db.Film"
-                        }
+                        "type": "integer",
+                        "description": "Actor Id the chain starts from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Film Id",
-                        "name": "filmID",
+                        "type": "integer",
+                        "description": "Actor Id the chain ends with",
+                        "name": "to",
                         "in": "query",
                         "required": true
                     }
@@ -303,7 +520,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api_models.FilmResponse"
+                            "$ref": "#/definitions/api_models.CostarPathResponse"
                         }
                     },
                     "400": {
@@ -317,49 +534,73 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/actors/{actorID}": {
+            "get": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for admin user, creating film using data from request body and return new film",
+                "description": "Availible only for authenticated user, getting actor by id with films",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "films"
+                    "actors"
                 ],
-                "summary": "Create film",
+                "summary": "Get actor",
                 "parameters": [
                     {
-                        "description": "film info",
-                        "name": "Film",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/db.Film"
-                        }
+                        "type": "integer",
+                        "description": "Actors Id",
+                        "name": "actorID",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Film Id",
-                        "name": "filmID",
-                        "in": "query",
-                        "required": true
+                        "example": "films",
+                        "description": "Included relations, films",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of actor to return",
+                        "name": "fields[actors]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,rate",
+                        "description": "Comma separated fields of included films",
+                        "name": "fields[films]",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api_models.FilmResponse"
+                            "$ref": "#/definitions/api_models.ActorResponse"
                         }
                     },
                     "400": {
@@ -373,38 +614,74 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
                     }
                 }
             },
-            "delete": {
+            "put": {
                 "security": [
                     {
                         "BasicAuth": []
                     }
                 ],
-                "description": "Availible only for admin user, deleting film by id from params",
+                "description": "Availible only for admin user, updating actor using id from request params and return actor",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "films"
+                    "actors"
                 ],
-                "summary": "Delete film",
+                "summary": "Update actor",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Film Id",
-                        "name": "filmID",
-                        "in": "query",
+                        "type": "integer",
+                        "description": "Actors Id",
+                        "name": "actorID",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "films",
+                        "description": "Nested relations, films",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of actors to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "description": "actor info, omitted fields are left as is",
+                        "name": "Actor",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UpdateActorRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ActorResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -417,46 +694,2658 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, deleting actor using id from request params",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "summary": "Delete actor",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Actors Id",
+                        "name": "actorID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/actors/{actorID}/costars": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, getting actors who played in the same films, ranked by number of shared films",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "summary": "Get actor co-stars",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Actors Id",
+                        "name": "actorID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.CostarsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/actors/{actorID}/filmography": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, getting actor films sorted by date with roles, also grouped by year",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "actors"
+                ],
+                "summary": "Get actor filmography",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Actors Id",
+                        "name": "actorID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmographyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/autocomplete": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, suggesting film and actor names starting with or similar to the query, tolerates typos",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Autocomplete names",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "Кеану Ривс",
+                        "description": "Beginning or misspelled name",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "film",
+                            "actor"
+                        ],
+                        "type": "string",
+                        "description": "Suggestion type: film or actor, default both",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of suggestions, default 10, max 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.AutocompleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, streaming film, actor and cast events as Server-Sent Events. Each message has the event id, the event type (film.created, actor.deleted, cast.changed...) and the event as data. Reconnecting with Last-Event-ID header or last_event_id param resumes after that event, otherwise only new events are sent.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream of catalogue changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "film,cast",
+                        "description": "Comma separated entities to stream, film, actor or cast, all by default",
+                        "name": "entity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Id of the last received event, for clients which can not set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/db.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/actors": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, streaming all actors as a file. Films column holds names of actor films separated by \";\".",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export actors",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "File format, default csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/films": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, streaming all films as a file, sorted and filtered like films list. Actors column holds names of the cast separated by \";\", the file can be imported back.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export films",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "File format, default csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rate",
+                            "name",
+                            "date"
+                        ],
+                        "type": "string",
+                        "example": "name",
+                        "description": "Sort by field, default rate",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "film-filter",
+                        "example": "name.Name1",
+                        "description": "Filter by field (field.value), field is name or description",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/films": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, getting films list, they can be sorted by fields, default is rate. Also you can use filters in field.value template.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Get films list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "actors",
+                        "description": "Included relations, actors, also accepted as expand",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,rate",
+                        "description": "Comma separated fields of films to return, also accepted as fields",
+                        "name": "fields[films]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of included actors",
+                        "name": "fields[actors]",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "rate",
+                            "name",
+                            "date"
+                        ],
+                        "type": "string",
+                        "example": "name",
+                        "description": "Sort by field, default rate",
+                        "name": "sortBy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "film-filter",
+                        "example": "name.Name1",
+                        "description": "Filter by field (field.value), field is name or description",
+                        "name": "filter",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "Page size from 1 to 1000, all films when omitted",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "Number of films to skip, used with limit",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "v1 lists films under film key",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, creating film using data from request body and return new film",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Create film",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "actors",
+                        "description": "Nested relations, actors",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of films to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "description": "film info, roles are keyed by actor ids",
+                        "name": "Film",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.CreateFilmRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "9f4c2a1e-create-film",
+                        "description": "Unique key to safely retry the request, repeated requests get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/films/bulk": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, applying operations in one transaction (default) or each one separately in best_effort mode. Data of operations is the same as body of POST /films and PUT /films/{filmID}.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Bulk create, update and delete films",
+                "parameters": [
+                    {
+                        "description": "operations",
+                        "name": "Operations",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.BulkRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "9f4c2a1e-create-film",
+                        "description": "Unique key to safely retry the request, repeated requests get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "all operations succeeded",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.BulkResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "some operations failed",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/films/by-external/{source}/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, getting film by its id in IMDb, Kinopoisk or TMDB",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Get film by external id",
+                "parameters": [
+                    {
+                        "enum": [
+                            "imdb",
+                            "kinopoisk",
+                            "tmdb"
+                        ],
+                        "type": "string",
+                        "description": "External catalogue",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "tt0133093",
+                        "description": "Film id in the catalogue",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "actors",
+                        "description": "Included relations, actors",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,external_ids",
+                        "description": "Comma separated fields of film to return",
+                        "name": "fields[films]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of included actors",
+                        "name": "fields[actors]",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, creating film with the external id or replacing all fields of the existing one. Cast is reconciled with the given one, actors are referenced by our or external id, omitted cast is left as is. Safe to repeat.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Create or replace film by external id",
+                "parameters": [
+                    {
+                        "enum": [
+                            "imdb",
+                            "kinopoisk",
+                            "tmdb"
+                        ],
+                        "type": "string",
+                        "description": "External catalogue",
+                        "name": "source",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "tt0133093",
+                        "description": "Film id in the catalogue",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "actors",
+                        "description": "Included relations, actors",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,external_ids",
+                        "description": "Comma separated fields of film to return",
+                        "name": "fields[films]",
+                        "in": "query"
+                    },
+                    {
+                        "description": "film info",
+                        "name": "Film",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UpsertFilmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "film is updated",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "film is created",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the film"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/films/{filmID}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, getting film by id with its actors",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Get film",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Film Id",
+                        "name": "filmID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "actors",
+                        "description": "Included relations, actors",
+                        "name": "include",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,rate",
+                        "description": "Comma separated fields of film to return",
+                        "name": "fields[films]",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of included actors",
+                        "name": "fields[actors]",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, updating film using data from request body and return new film. All fields are written, omitted ones are reset, actors are left as is.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Update film",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Film Id",
+                        "name": "filmID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "actors",
+                        "description": "Nested relations, actors",
+                        "name": "expand",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name",
+                        "description": "Comma separated fields of films to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "description": "film info",
+                        "name": "Film",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UpdateFilmRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, deleting film by id from params",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "films"
+                ],
+                "summary": "Delete film",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Film Id",
+                        "name": "filmID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, executing GraphQL queries like POST /graphql does, mutations are rejected.",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL queries over GET",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "{ films { name } }",
+                        "description": "GraphQL document",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Operation to execute when the document has many",
+                        "name": "operationName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "{\"id\":\"1\"}",
+                        "description": "JSON object of variables",
+                        "name": "variables",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, executing GraphQL queries over films, actors and their roles, mutations are availible only for admin user. Queries deeper or more complex than the configured limits are rejected before execution. Relations of sibling objects are loaded in one query per level.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL endpoint",
+                "parameters": [
+                    {
+                        "description": "GraphQL operation",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthcheck": {
+            "get": {
+                "description": "Reports that the service is up and the database answers",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/import": {
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, importing CSV or NDJSON dump in background. Rows are matched by name and date (films) or name and birth (actors), existing ones are updated. Cast column holds actor names, #id or external ids like imdb:nm0000206 separated by \";\". Poll the returned job for progress and report.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Import films or actors",
+                "parameters": [
+                    {
+                        "enum": [
+                            "films",
+                            "actors"
+                        ],
+                        "type": "string",
+                        "description": "What is imported",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Body format, taken from Content-Type by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate rows and report what would be changed",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "title=name,year=date",
+                        "description": "Comma separated renames of source columns",
+                        "name": "map",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV with header or JSON object per line",
+                        "name": "Dump",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "9f4c2a1e-create-film",
+                        "description": "Unique key to safely retry the request, repeated requests get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ImportJobResponse"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the job"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/{jobID}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, getting progress and report of the import, finished jobs are kept for an hour",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Get import job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job Id",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ImportJobResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user, searching films by name and description and actors by name. Results are ranked by relevance and contain highlighted snippets.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "search"
+                ],
+                "summary": "Full text search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "matrix",
+                        "description": "Search query, supports quotes, or and -minus",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "film",
+                            "actor"
+                        ],
+                        "type": "string",
+                        "description": "Result type: film or actor, default both",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ru",
+                            "en"
+                        ],
+                        "type": "string",
+                        "description": "Query language: ru or en, detected by alphabet by default",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of results, default 20, max 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.SearchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for authenticated user since API v2, getting username and role of the user",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UserResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "requested with API v1",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, listing webhook subscriptions without secrets",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhooksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, subscribing url to catalogue events. Each delivery is a POST of the event signed in X-Filmoteka-Signature header as t=\u003cunix time\u003e,v1=\u003chex HMAC-SHA256 of \"\u003ct\u003e.\u003cbody\u003e\" with the secret\u003e. The secret is returned only in this response. Failed deliveries are retried with exponential backoff.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "description": "webhook info",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "example": "9f4c2a1e-create-webhook",
+                        "description": "Unique key to safely retry the request, repeated requests get the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookID}": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, getting webhook by id without its secret",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, replacing url, events and activity of the webhook. The secret is kept when omitted and returned when changed.",
+                "consumes": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "webhook info",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, deleting webhook with its delivery log, pending deliveries are not sent",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "BasicAuth": []
+                    }
+                ],
+                "description": "Availible only for admin user, listing the latest deliveries of the webhook with attempts, last response status and error",
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Webhook delivery log",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Webhook id",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of deliveries, default 50, max 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.WebhookDeliveriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "film_not_found"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "film not found"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "film not found"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/errs.FieldError"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/films/45"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 404
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Not Found"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "/problems/film_not_found"
+                }
+            }
+        },
+        "api.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ film(id: 1) { name cast { role actor { name } } } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "api.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gqlerrors.FormattedError"
+                    }
+                }
+            }
+        },
+        "api_models.Actor": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
+                "external_ids": {
+                    "description": "ExternalIDs maps source to the actor id in it.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FilmSummary"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ActorResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "$ref": "#/definitions/api_models.Actor"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.ActorSummary": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ActorsResponse": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.Actor"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.AutocompleteResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/filmoteka_db.Suggestion"
+                    }
+                }
+            }
+        },
+        "api_models.BulkError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/errs.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.BulkOperation": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                }
+            }
+        },
+        "api_models.BulkRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "transaction",
+                        "best_effort"
+                    ]
+                },
+                "operations": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/api_models.BulkOperation"
+                    }
+                }
+            }
+        },
+        "api_models.BulkResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.BulkResult"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.BulkResult": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "$ref": "#/definitions/api_models.Actor"
+                },
+                "error": {
+                    "$ref": "#/definitions/api_models.BulkError"
+                },
+                "film": {
+                    "$ref": "#/definitions/api_models.Film"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.CastMember": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "external": {
+                    "$ref": "#/definitions/api_models.ExternalRef"
+                },
+                "role": {
+                    "type": "string",
+                    "maxLength": 150
+                }
+            }
+        },
+        "api_models.CostarPathResponse": {
+            "type": "object",
+            "properties": {
+                "degrees": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/filmoteka_db.PathLink"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.CostarsResponse": {
+            "type": "object",
+            "properties": {
+                "costars": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/filmoteka_db.Costar"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.CreateActorRequest": {
+            "type": "object",
+            "properties": {
+                "birth": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                }
+            }
+        },
+        "api_models.CreateFilmRequest": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 150,
+                    "minLength": 1
+                },
+                "rate": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                },
+                "roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api_models.ExternalRef": {
+            "type": "object",
+            "required": [
+                "id",
+                "source"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "nm0000206"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "imdb"
+                }
+            }
+        },
+        "api_models.Film": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.ActorSummary"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "external_ids": {
+                    "description": "ExternalIDs maps source to the film id in it.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "imdb": "tt0133093"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.FilmResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "film": {
+                    "$ref": "#/definitions/api_models.Film"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.FilmSummary": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.FilmographyResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/filmoteka_db.FilmographyEntry"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "years": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FilmographyYear"
+                    }
+                }
+            }
+        },
+        "api_models.FilmographyYear": {
+            "type": "object",
+            "properties": {
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/filmoteka_db.FilmographyEntry"
+                    }
+                },
+                "year": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.FilmsResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.Film"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.ImportJobResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "job": {
+                    "$ref": "#/definitions/importer.Job"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.SearchResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/filmoteka_db.SearchResult"
+                    }
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api_models.UpdateActorRequest": {
+            "type": "object",
+            "properties": {
+                "birth": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                }
+            }
+        },
+        "api_models.UpdateFilmRequest": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 150,
+                    "minLength": 1
+                },
+                "rate": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                }
+            }
+        },
+        "api_models.UpsertActorRequest": {
+            "type": "object",
+            "required": [
+                "external_ids"
+            ],
+            "properties": {
+                "birth": {
+                    "type": "string"
+                },
+                "external_ids": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 150,
+                    "minLength": 1
+                },
+                "sex": {
+                    "type": "string",
+                    "enum": [
+                        "male",
+                        "female"
+                    ]
+                }
+            }
+        },
+        "api_models.UpsertFilmRequest": {
+            "type": "object",
+            "required": [
+                "external_ids"
+            ],
+            "properties": {
+                "cast": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.CastMember"
+                    }
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 1000
+                },
+                "external_ids": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 150,
+                    "minLength": 1
+                },
+                "rate": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 0
+                }
+            }
+        },
+        "api_models.User": {
             "type": "object",
             "properties": {
-                "error": {
+                "role": {
                     "type": "string",
-                    "example": "Error msg"
+                    "enum": [
+                        "admin",
+                        "client"
+                    ],
+                    "example": "admin"
                 },
-                "success": {
-                    "type": "boolean",
-                    "example": false
+                "username": {
+                    "type": "string",
+                    "example": "admin"
                 }
             }
         },
-        "api_models.ActorResponse": {
+        "api_models.UserResponse": {
             "type": "object",
             "properties": {
-                "actor": {
-                    "$ref": "#/definitions/filmoteka_db.Actor"
-                },
                 "error": {
                     "type": "string"
                 },
                 "success": {
                     "type": "boolean"
+                },
+                "user": {
+                    "$ref": "#/definitions/api_models.User"
                 }
             }
         },
-        "api_models.ActorsResponse": {
+        "api_models.WebhookDeliveriesResponse": {
             "type": "object",
             "properties": {
-                "actors": {
+                "deliveries": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/filmoteka_db.Actor"
+                        "$ref": "#/definitions/filmoteka_db.WebhookDelivery"
                     }
                 },
                 "error": {
@@ -467,49 +3356,121 @@ const docTemplate = `{
                 }
             }
         },
-        "api_models.FilmResponse": {
+        "api_models.WebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "film.created",
+                        "actor.*"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://example.com/hooks/filmoteka"
+                }
+            }
+        },
+        "api_models.WebhookResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
                 },
-                "film": {
-                    "$ref": "#/definitions/filmoteka_db.Film"
+                "secret": {
+                    "description": "Secret is returned only when the webhook is created or the secret changed.",
+                    "type": "string"
                 },
                 "success": {
                     "type": "boolean"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/filmoteka_db.Webhook"
                 }
             }
         },
-        "api_models.FilmsResponse": {
+        "api_models.WebhooksResponse": {
             "type": "object",
             "properties": {
                 "error": {
                     "type": "string"
                 },
-                "film": {
+                "success": {
+                    "type": "boolean"
+                },
+                "webhooks": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/filmoteka_db.Film"
+                        "$ref": "#/definitions/filmoteka_db.Webhook"
                     }
-                },
-                "success": {
-                    "type": "boolean"
                 }
             }
         },
-        "db.Actor": {
+        "db.Event": {
             "type": "object",
             "properties": {
-                "birthday": {
+                "created_at": {
                     "type": "string"
                 },
-                "films": {
+                "entity": {
+                    "type": "string"
+                },
+                "entity_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.Film"
+                        "type": "integer"
                     }
                 },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "errs.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "param": {
+                    "type": "string"
+                },
+                "rule": {
+                    "type": "string"
+                }
+            }
+        },
+        "filmoteka_db.Costar": {
+            "type": "object",
+            "properties": {
+                "birthday": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -517,100 +3478,283 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "sex": {
-                    "type": "string",
-                    "enum": [
-                        "male",
-                        "female"
-                    ]
+                    "type": "string"
+                },
+                "shared_films": {
+                    "type": "integer"
                 }
             }
         },
-        "db.Film": {
+        "filmoteka_db.FilmographyEntry": {
             "type": "object",
             "properties": {
-                "actors": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/db.Actor"
-                    }
-                },
                 "date": {
                     "type": "string"
                 },
-                "description": {
-                    "type": "string",
-                    "maxLength": 1000
+                "film_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "integer"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "filmoteka_db.PathLink": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "actor_name": {
+                    "type": "string"
                 },
+                "film_id": {
+                    "type": "integer"
+                },
+                "film_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "filmoteka_db.SearchResult": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "integer"
                 },
                 "name": {
-                    "type": "string",
-                    "maxLength": 150,
-                    "minLength": 1
+                    "type": "string"
                 },
-                "rate": {
-                    "type": "integer",
-                    "maximum": 10,
-                    "minimum": 0
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "filmoteka_db.Actor": {
+        "filmoteka_db.Suggestion": {
             "type": "object",
             "properties": {
-                "birthday": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "films": {
+                "score": {
+                    "type": "number"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "filmoteka_db.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events are event types, \"film.*\" like masks or \"*\", empty means all.",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.Film"
+                        "type": "string"
                     }
                 },
                 "id": {
                     "type": "integer"
                 },
-                "name": {
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "filmoteka_db.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "sex": {
-                    "type": "string",
-                    "enum": [
-                        "male",
-                        "female"
-                    ]
+                "delivered_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer"
+                },
+                "event_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhook_id": {
+                    "type": "integer"
                 }
             }
         },
-        "filmoteka_db.Film": {
+        "gqlerrors.FormattedError": {
             "type": "object",
             "properties": {
-                "actors": {
+                "extensions": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "locations": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/db.Actor"
+                        "$ref": "#/definitions/location.SourceLocation"
                     }
                 },
-                "date": {
+                "message": {
                     "type": "string"
                 },
-                "description": {
-                    "type": "string",
-                    "maxLength": 1000
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "importer.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
                 },
                 "id": {
+                    "type": "string"
+                },
+                "options": {
+                    "$ref": "#/definitions/importer.Options"
+                },
+                "progress": {
+                    "type": "number"
+                },
+                "read": {
                     "type": "integer"
                 },
-                "name": {
+                "report": {
+                    "$ref": "#/definitions/importer.Report"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "status": {
                     "type": "string",
-                    "maxLength": 150,
-                    "minLength": 1
+                    "enum": [
+                        "queued",
+                        "running",
+                        "done",
+                        "failed"
+                    ]
+                }
+            }
+        },
+        "importer.Options": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "description": "DryRun validates rows and resolves cast without writing anything.",
+                    "type": "boolean"
                 },
-                "rate": {
-                    "type": "integer",
-                    "maximum": 10,
-                    "minimum": 0
+                "format": {
+                    "type": "string"
+                },
+                "mapping": {
+                    "description": "Mapping renames source columns to fields of films or actors.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "importer.Report": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.RowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "importer.RowError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Row is a line number of the source, header is line 1 in CSV.",
+                    "type": "integer"
+                }
+            }
+        },
+        "location.SourceLocation": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
                 }
             }
         }
@@ -625,8 +3769,8 @@ const docTemplate = `{
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
-	Host:             "localhost:8084",
-	BasePath:         "/",
+	Host:             "",
+	BasePath:         "/v2",
 	Schemes:          []string{},
 	Title:            "Filmoteka API",
 	Description:      "This is a sample Filmoteka server.",
@@ -0,0 +1,140 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Kind is a class of domain errors, transports map it to their status codes.
+type Kind string
+
+const (
+	KindBadRequest   Kind = "bad_request"
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindTooLarge     Kind = "too_large"
+	// KindNotAcceptable is a response format or version the API does not have.
+	KindNotAcceptable Kind = "not_acceptable"
+	// KindUnprocessable is a well formed request which contradicts earlier ones.
+	KindUnprocessable Kind = "unprocessable"
+	// KindTooManyRequests is a client over its rate limit or quota.
+	KindTooManyRequests Kind = "too_many_requests"
+	KindInternal        Kind = "internal"
+)
+
+// Stable machine readable codes shared by many handlers.
+const (
+	CodeBadRequest        = "bad_request"
+	CodeInvalidJSON       = "invalid_json"
+	CodeInvalidBody       = "invalid_body"
+	CodeInvalidParam      = "invalid_param"
+	CodeValidationFailed  = "validation_failed"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeAlreadyExists     = "already_exists"
+	CodeReferenceNotFound = "reference_not_found"
+	CodePayloadTooLarge   = "payload_too_large"
+	CodeNotAcceptable     = "not_acceptable"
+	CodeRateLimited       = "rate_limited"
+	CodeQuotaExceeded     = "quota_exceeded"
+	CodeInternal          = "internal"
+)
+
+// FieldError describes why one field of the request is not valid.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []*FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func BadRequest(code string, message string) *Error {
+	return &Error{Kind: KindBadRequest, Code: code, Message: message}
+}
+
+func Validation(message string, fields ...*FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: CodeValidationFailed, Message: message, Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: CodeUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Code: CodeForbidden, Message: message}
+}
+
+func NotFound(code string, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code string, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Unprocessable(code string, message string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message}
+}
+
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// Wrap keeps err as the cause of a new error of the kind.
+func Wrap(kind Kind, code string, message string, err error) *Error {
+	return &Error{Kind: kind, Code: code, Message: message, Err: err}
+}
+
+// From converts any error into Error. Missing rows become NotFound,
+// integrity violations become Conflict, other errors are Internal.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	if errors.Is(err, pg.ErrNoRows) {
+		return Wrap(KindNotFound, CodeNotFound, "resource not found", err)
+	}
+
+	var pgErr pg.Error
+	if errors.As(err, &pgErr) {
+		switch code := pgErr.Field('C'); {
+		case code == "23505":
+			return Wrap(KindConflict, CodeAlreadyExists, "resource already exists", err)
+		case code == "23503":
+			return Wrap(KindConflict, CodeReferenceNotFound, "referenced resource does not exist", err)
+		case pgErr.IntegrityViolation():
+			return Wrap(KindConflict, CodeValidationFailed, "integrity constraint violated", err)
+		case len(code) == 5 && (code[:2] == "22" || code[:2] == "42"):
+			// Data exceptions and syntax errors come from request params.
+			return Wrap(KindBadRequest, CodeInvalidParam, "request params can not be applied", err)
+		}
+	}
+
+	return Internal(err)
+}
@@ -0,0 +1,47 @@
+package exporter
+
+import (
+	"filmoteka/db"
+	"io"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Column names match fields accepted by import, so exports can be loaded back.
+var (
+	FilmColumns  = []string{"id", "name", "description", "date", "rate", "actors"}
+	ActorColumns = []string{"id", "name", "sex", "birth", "films"}
+)
+
+// Films writes films filtered and sorted like GET /films.
+func Films(pgdb *pg.DB, w io.Writer, format string, sortBy string, filter []string) error {
+	writer, err := NewWriter(w, format, FilmColumns)
+	if err != nil {
+		return err
+	}
+
+	err = db.EachFilm(pgdb, sortBy, filter, func(film *db.FilmRow) error {
+		return writer.Write(film.ID, film.Name, film.Description, film.Date, film.Rate, film.Actors)
+	})
+	if err != nil {
+		writer.Discard()
+		return err
+	}
+	return writer.Close()
+}
+
+func Actors(pgdb *pg.DB, w io.Writer, format string) error {
+	writer, err := NewWriter(w, format, ActorColumns)
+	if err != nil {
+		return err
+	}
+
+	err = db.EachActor(pgdb, func(actor *db.ActorRow) error {
+		return writer.Write(actor.ID, actor.Name, actor.Sex, actor.Birth, actor.Films)
+	})
+	if err != nil {
+		writer.Discard()
+		return err
+	}
+	return writer.Close()
+}
@@ -0,0 +1,214 @@
+// Package exporter writes films and actors row by row in formats
+// analysts open in spreadsheets.
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"filmoteka/db"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+	FormatXLSX   = "xlsx"
+
+	// listSeparator joins lists in CSV cells, import splits cast by it.
+	listSeparator = ";"
+	sheetName     = "Sheet1"
+)
+
+var contentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+	FormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ContentType of the format, empty when the format is unknown.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// Writer writes rows with values of string, int, int64, time.Time or
+// []string types in order of columns.
+type Writer interface {
+	Write(values ...interface{}) error
+	// Close flushes buffered rows, nothing is written for XLSX before it.
+	Close() error
+	// Discard releases the writer without flushing after a failure.
+	Discard()
+}
+
+func NewWriter(w io.Writer, format string, columns []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, columns)
+	}
+	return nil, fmt.Errorf("unknown format %q, use csv, ndjson or xlsx", format)
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	err := writer.Write(columns)
+	if err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer, record: make([]string, len(columns))}, nil
+}
+
+func (c *csvWriter) Write(values ...interface{}) error {
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			c.record[i] = v
+		case int:
+			c.record[i] = strconv.Itoa(v)
+		case int64:
+			c.record[i] = strconv.FormatInt(v, 10)
+		case time.Time:
+			c.record[i] = v.Format(db.DateLayout)
+		case []string:
+			c.record[i] = strings.Join(v, listSeparator)
+		default:
+			c.record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.writer.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Discard() {}
+
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []string
+	buf     bytes.Buffer
+}
+
+// Write keeps keys in order of columns, which json maps can not do.
+func (n *ndjsonWriter) Write(values ...interface{}) error {
+	n.buf.Reset()
+	n.buf.WriteByte('{')
+	for i, value := range values {
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(db.DateLayout)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			n.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(n.columns[i])
+		n.buf.Write(key)
+		n.buf.WriteByte(':')
+		n.buf.Write(data)
+	}
+	n.buf.WriteString("}\n")
+	_, err := n.w.Write(n.buf.Bytes())
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+func (n *ndjsonWriter) Discard() {}
+
+// xlsxWriter keeps rows in a temporary file of excelize stream writer,
+// the workbook can only be written as a whole when rows are done.
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+	cells  []interface{}
+	date   int
+}
+
+func newXLSXWriter(w io.Writer, columns []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	stream, err := file.NewStreamWriter(sheetName)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	dateFormat := "yyyy-mm-dd"
+	date, err := file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	x := &xlsxWriter{w: w, file: file, stream: stream, cells: make([]interface{}, len(columns)), date: date}
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	err = x.setRow(header)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) Write(values ...interface{}) error {
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			x.cells[i] = excelize.Cell{StyleID: x.date, Value: v}
+		case []string:
+			x.cells[i] = strings.Join(v, listSeparator)
+		default:
+			x.cells[i] = v
+		}
+	}
+	return x.setRow(x.cells)
+}
+
+func (x *xlsxWriter) setRow(cells []interface{}) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	err := x.stream.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = x.file.WriteTo(x.w)
+	return err
+}
+
+func (x *xlsxWriter) Discard() {
+	x.file.Close()
+}
@@ -0,0 +1,238 @@
+package grpcapi
+
+import (
+	"context"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	filmotekav1 "filmoteka/proto/filmoteka/v1"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+var sexes = map[string]filmotekav1.Sex{
+	"male":   filmotekav1.Sex_SEX_MALE,
+	"female": filmotekav1.Sex_SEX_FEMALE,
+}
+
+type actorServer struct {
+	filmotekav1.UnimplementedActorServiceServer
+
+	db *pg.DB
+}
+
+func (s *actorServer) GetActor(ctx context.Context, req *filmotekav1.GetActorRequest) (*filmotekav1.Actor, error) {
+	actor, err := db.GetActor(s.db, req.GetId(), &db.Selection{})
+	if err != nil {
+		return nil, notFound(err, "actor")
+	}
+	return s.message(actor)
+}
+
+// ListActors reads actors with a cursor and sends them in batches like
+// ListFilms does.
+func (s *actorServer) ListActors(req *filmotekav1.ListActorsRequest, stream filmotekav1.ActorService_ListActorsServer) error {
+	batch := make([]*db.Actor, 0, streamBatchSize)
+	send := func() error {
+		messages, err := s.messages(batch)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			err = stream.Send(message)
+			if err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := db.EachActor(s.db, func(row *db.ActorRow) error {
+		batch = append(batch, &db.Actor{
+			ID:    row.ID,
+			Name:  row.Name,
+			Sex:   row.Sex,
+			Birth: row.Birth,
+		})
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+		return send()
+	})
+	if err != nil {
+		return err
+	}
+	return send()
+}
+
+func (s *actorServer) CreateActor(ctx context.Context, req *filmotekav1.CreateActorRequest) (*filmotekav1.Actor, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	create := &api_models.CreateActorRequest{
+		Name:  req.GetName(),
+		Sex:   sexName(req.GetSex()),
+		Birth: req.GetBirthday(),
+	}
+	err = validate(ctx, create)
+	if err != nil {
+		return nil, err
+	}
+	birth, _ := time.Parse(db.DateLayout, create.Birth)
+
+	var actor *db.Actor
+	err = s.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		actor, err = db.CreateActor(tx, &db.Actor{
+			Name:  create.Name,
+			Sex:   create.Sex,
+			Birth: birth,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.message(actor)
+}
+
+// UpdateActor changes only present fields, the result is validated as a
+// whole actor.
+func (s *actorServer) UpdateActor(ctx context.Context, req *filmotekav1.UpdateActorRequest) (*filmotekav1.Actor, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := db.GetActor(s.db, req.GetId(), &db.Selection{})
+	if err != nil {
+		return nil, notFound(err, "actor")
+	}
+	update := &api_models.CreateActorRequest{
+		Name:  actor.Name,
+		Sex:   actor.Sex,
+		Birth: actor.Birth.Format(db.DateLayout),
+	}
+	if req.Name != nil {
+		update.Name = req.GetName()
+	}
+	if req.Sex != nil {
+		update.Sex = sexName(req.GetSex())
+	}
+	if req.Birthday != nil {
+		update.Birth = req.GetBirthday()
+	}
+	err = validate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	actor.Name = update.Name
+	actor.Sex = update.Sex
+	actor.Birth, _ = time.Parse(db.DateLayout, update.Birth)
+	err = s.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		actor, err = db.UpdateActor(tx, actor)
+		return err
+	})
+	if err != nil {
+		return nil, notFound(err, "actor")
+	}
+	return s.message(actor)
+}
+
+func (s *actorServer) DeleteActor(ctx context.Context, req *filmotekav1.DeleteActorRequest) (*filmotekav1.DeleteActorResponse, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		return db.DeleteActor(tx, req.GetId())
+	})
+	if err != nil {
+		return nil, notFound(err, "actor")
+	}
+	return &filmotekav1.DeleteActorResponse{}, nil
+}
+
+func (s *actorServer) message(actor *db.Actor) (*filmotekav1.Actor, error) {
+	messages, err := s.messages([]*db.Actor{actor})
+	if err != nil {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// messages converts actors with their films and external ids loaded by
+// one query per relation.
+func (s *actorServer) messages(actors []*db.Actor) ([]*filmotekav1.Actor, error) {
+	if len(actors) == 0 {
+		return nil, nil
+	}
+	actorIDs := make([]int64, 0, len(actors))
+	for _, actor := range actors {
+		actorIDs = append(actorIDs, actor.ID)
+	}
+
+	roles, err := db.GetRoles(s.db, actorIDs)
+	if err != nil {
+		return nil, err
+	}
+	filmIDs := make([]int, 0, len(roles))
+	for _, link := range roles {
+		filmIDs = append(filmIDs, link.FilmID)
+	}
+	filmNames := make(map[int]string, len(filmIDs))
+	if len(filmIDs) > 0 {
+		films, err := db.GetFilmsByIDs(s.db, filmIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, film := range films {
+			filmNames[film.ID] = film.Name
+		}
+	}
+	externalIDs, err := db.GetActorsExternalIDs(s.db, actorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*filmotekav1.Actor, len(actors))
+	messages := make([]*filmotekav1.Actor, 0, len(actors))
+	for _, actor := range actors {
+		message := &filmotekav1.Actor{
+			Id:          actor.ID,
+			Name:        actor.Name,
+			Sex:         sexes[actor.Sex],
+			Birthday:    actor.Birth.Format(db.DateLayout),
+			Films:       []*filmotekav1.ActorFilm{},
+			ExternalIds: map[string]string{},
+		}
+		byID[actor.ID] = message
+		messages = append(messages, message)
+	}
+	for _, link := range roles {
+		message := byID[int64(link.ActorID)]
+		message.Films = append(message.Films, &filmotekav1.ActorFilm{
+			FilmId: int64(link.FilmID),
+			Name:   filmNames[link.FilmID],
+			Role:   link.Role,
+		})
+	}
+	for _, id := range externalIDs {
+		byID[id.ActorID].ExternalIds[id.Source] = id.Value
+	}
+	return messages, nil
+}
+
+// sexName is the stored value of the sex, unspecified fails validation.
+func sexName(sex filmotekav1.Sex) string {
+	for name, value := range sexes {
+		if value == sex {
+			return name
+		}
+	}
+	return ""
+}
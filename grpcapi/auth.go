@@ -0,0 +1,132 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/base64"
+	"filmoteka/db"
+	"filmoteka/errs"
+	"strings"
+
+	"github.com/go-pg/pg/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// publicServices are served without authentication.
+var publicServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.",
+}
+
+type userKey struct{}
+
+// user is the authenticated caller of the request.
+type user struct {
+	name string
+	role string
+}
+
+func unaryAuth(pgdb *pg.DB) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if isPublic(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, pgdb)
+		if err != nil {
+			return nil, statusError(ctx, info.FullMethod, err)
+		}
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			return nil, statusError(ctx, info.FullMethod, err)
+		}
+		return res, nil
+	}
+}
+
+func streamAuth(pgdb *pg.DB) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublic(info.FullMethod) {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), pgdb)
+		if err != nil {
+			return statusError(ctx, info.FullMethod, err)
+		}
+
+		err = handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+		if err != nil {
+			return statusError(ctx, info.FullMethod, err)
+		}
+		return nil
+	}
+}
+
+// authenticatedStream carries the caller in the context of the stream.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func isPublic(method string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate checks Basic credentials of authorization metadata, it is
+// the same header REST clients send.
+func authenticate(ctx context.Context, pgdb *pg.DB) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ctx, errs.Unauthorized("failed to get username and password")
+	}
+	username, password, ok := parseBasicAuth(values[0])
+	if !ok {
+		return ctx, errs.Unauthorized("failed to get username and password")
+	}
+
+	role, err := db.GetUser(pgdb, username, password)
+	if err != nil {
+		return ctx, errs.From(err)
+	}
+	return context.WithValue(ctx, userKey{}, &user{name: username, role: role}), nil
+}
+
+// parseBasicAuth decodes "Basic base64(username:password)" like
+// http.Request.BasicAuth does.
+func parseBasicAuth(auth string) (string, string, bool) {
+	const prefix = "Basic "
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func currentUser(ctx context.Context) *user {
+	u, _ := ctx.Value(userKey{}).(*user)
+	if u == nil {
+		return &user{}
+	}
+	return u
+}
+
+// requireAdmin allows only admin users, other authenticated users are forbidden.
+func requireAdmin(ctx context.Context) error {
+	if currentUser(ctx).role != db.Admin {
+		return errs.Forbidden("wrong access level")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"filmoteka/api"
+	"filmoteka/errs"
+	"log/slog"
+
+	"github.com/go-pg/pg/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is a domain of ErrorInfo details, reasons are the codes
+// REST API reports in problem types.
+const errorDomain = "filmoteka"
+
+var codeByKind = map[errs.Kind]codes.Code{
+	errs.KindBadRequest:      codes.InvalidArgument,
+	errs.KindValidation:      codes.InvalidArgument,
+	errs.KindUnauthorized:    codes.Unauthenticated,
+	errs.KindForbidden:       codes.PermissionDenied,
+	errs.KindNotFound:        codes.NotFound,
+	errs.KindConflict:        codes.AlreadyExists,
+	errs.KindTooLarge:        codes.ResourceExhausted,
+	errs.KindNotAcceptable:   codes.InvalidArgument,
+	errs.KindUnprocessable:   codes.FailedPrecondition,
+	errs.KindTooManyRequests: codes.ResourceExhausted,
+	errs.KindInternal:        codes.Internal,
+}
+
+// statusError converts domain errors into statuses with ErrorInfo and, for
+// validation errors, BadRequest details.
+func statusError(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	e := errs.From(err)
+	code, ok := codeByKind[e.Kind]
+	if !ok {
+		code = codes.Internal
+	}
+	if e.Kind == errs.KindConflict && e.Code == errs.CodeReferenceNotFound {
+		code = codes.FailedPrecondition
+	}
+
+	if code == codes.Internal {
+		slog.Error("request failed", "err", err, "method", method)
+	} else {
+		slog.Debug("request rejected", "err", err, "method", method)
+	}
+
+	message := e.Message
+	if code == codes.Internal {
+		message = "internal server error"
+	}
+	st := status.New(code, message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: e.Code, Domain: errorDomain}}
+	if len(e.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(e.Fields))
+		for _, field := range e.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// validate checks the request like REST bodies, messages are in the
+// language of accept-language metadata.
+func validate(ctx context.Context, req interface{}) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var acceptLanguage string
+	if values := md.Get("accept-language"); len(values) > 0 {
+		acceptLanguage = values[0]
+	}
+	return api.ValidationError(acceptLanguage, api.Validate.Struct(req))
+}
+
+func notFound(err error, resource string) error {
+	if errors.Is(err, pg.ErrNoRows) {
+		return errs.Wrap(errs.KindNotFound, resource+"_not_found", resource+" not found", err)
+	}
+	return err
+}
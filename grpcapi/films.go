@@ -0,0 +1,283 @@
+package grpcapi
+
+import (
+	"context"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/errs"
+	filmotekav1 "filmoteka/proto/filmoteka/v1"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+var filmSorts = map[filmotekav1.FilmSort]string{
+	filmotekav1.FilmSort_FILM_SORT_UNSPECIFIED: "rate DESC",
+	filmotekav1.FilmSort_FILM_SORT_NAME:        "name",
+	filmotekav1.FilmSort_FILM_SORT_DATE:        "date",
+}
+
+var filmFilterFields = map[filmotekav1.FilmFilterField]string{
+	filmotekav1.FilmFilterField_FILM_FILTER_FIELD_NAME:        "name",
+	filmotekav1.FilmFilterField_FILM_FILTER_FIELD_DESCRIPTION: "description",
+}
+
+type filmServer struct {
+	filmotekav1.UnimplementedFilmServiceServer
+
+	db *pg.DB
+}
+
+func (s *filmServer) GetFilm(ctx context.Context, req *filmotekav1.GetFilmRequest) (*filmotekav1.Film, error) {
+	film, err := db.GetFilm(s.db, int(req.GetId()), &db.Selection{})
+	if err != nil {
+		return nil, notFound(err, "film")
+	}
+	return s.message(film)
+}
+
+// ListFilms reads films with a cursor and sends them in batches, relations
+// are loaded once per batch.
+func (s *filmServer) ListFilms(req *filmotekav1.ListFilmsRequest, stream filmotekav1.FilmService_ListFilmsServer) error {
+	sortBy, ok := filmSorts[req.GetSortBy()]
+	if !ok {
+		return errs.BadRequest(errs.CodeInvalidParam, "unknown sort_by")
+	}
+	var filter []string
+	if req.GetFilter() != nil {
+		field, ok := filmFilterFields[req.GetFilter().GetField()]
+		if !ok {
+			return errs.BadRequest(errs.CodeInvalidParam, "unknown filter field")
+		}
+		filter = []string{field, req.GetFilter().GetValue()}
+	}
+
+	batch := make([]*db.Film, 0, streamBatchSize)
+	send := func() error {
+		messages, err := s.messages(batch)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			err = stream.Send(message)
+			if err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := db.EachFilm(s.db, sortBy, filter, func(row *db.FilmRow) error {
+		batch = append(batch, &db.Film{
+			ID:          row.ID,
+			Name:        row.Name,
+			Description: row.Description,
+			Date:        row.Date,
+			Rate:        row.Rate,
+		})
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+		return send()
+	})
+	if err != nil {
+		return err
+	}
+	return send()
+}
+
+func (s *filmServer) CreateFilm(ctx context.Context, req *filmotekav1.CreateFilmRequest) (*filmotekav1.Film, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	actors, roles := castInput(req.GetCast())
+	create := &api_models.CreateFilmRequest{
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Date:        req.GetDate(),
+		Rate:        int(req.GetRate()),
+		Actors:      actors,
+		Roles:       roles,
+	}
+	err = validate(ctx, create)
+	if err != nil {
+		return nil, err
+	}
+	date, _ := time.Parse(db.DateLayout, create.Date)
+
+	var film *db.Film
+	err = s.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		film, err = db.CreateFilm(tx, &db.Film{
+			Name:        create.Name,
+			Description: create.Description,
+			Date:        date,
+			Rate:        create.Rate,
+		}, create.Actors, create.Roles)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.message(film)
+}
+
+// UpdateFilm changes only present fields, the result is validated as a
+// whole film so present fields can not be emptied.
+func (s *filmServer) UpdateFilm(ctx context.Context, req *filmotekav1.UpdateFilmRequest) (*filmotekav1.Film, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	film, err := db.GetFilm(s.db, int(req.GetId()), &db.Selection{})
+	if err != nil {
+		return nil, notFound(err, "film")
+	}
+	update := &api_models.CreateFilmRequest{
+		Name:        film.Name,
+		Description: film.Description,
+		Date:        film.Date.Format(db.DateLayout),
+		Rate:        film.Rate,
+	}
+	if req.Name != nil {
+		update.Name = req.GetName()
+	}
+	if req.Description != nil {
+		update.Description = req.GetDescription()
+	}
+	if req.Date != nil {
+		update.Date = req.GetDate()
+	}
+	if req.Rate != nil {
+		update.Rate = int(req.GetRate())
+	}
+	if req.GetReplaceCast() {
+		update.Actors, update.Roles = castInput(req.GetCast())
+	}
+	err = validate(ctx, update)
+	if err != nil {
+		return nil, err
+	}
+
+	film.Name = update.Name
+	film.Description = update.Description
+	film.Date, _ = time.Parse(db.DateLayout, update.Date)
+	film.Rate = update.Rate
+	err = s.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		film, err = db.UpdateFilm(tx, film)
+		if err != nil {
+			return notFound(err, "film")
+		}
+		if req.GetReplaceCast() {
+			return db.SetFilmActors(tx, film.ID, update.Actors, update.Roles)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.message(film)
+}
+
+func (s *filmServer) DeleteFilm(ctx context.Context, req *filmotekav1.DeleteFilmRequest) (*filmotekav1.DeleteFilmResponse, error) {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		return db.DeleteFilm(tx, req.GetId())
+	})
+	if err != nil {
+		return nil, notFound(err, "film")
+	}
+	return &filmotekav1.DeleteFilmResponse{}, nil
+}
+
+func (s *filmServer) message(film *db.Film) (*filmotekav1.Film, error) {
+	messages, err := s.messages([]*db.Film{film})
+	if err != nil {
+		return nil, err
+	}
+	return messages[0], nil
+}
+
+// messages converts films with their cast and external ids loaded by
+// one query per relation.
+func (s *filmServer) messages(films []*db.Film) ([]*filmotekav1.Film, error) {
+	if len(films) == 0 {
+		return nil, nil
+	}
+	filmIDs := make([]int, 0, len(films))
+	for _, film := range films {
+		filmIDs = append(filmIDs, film.ID)
+	}
+
+	casts, err := db.GetCasts(s.db, filmIDs)
+	if err != nil {
+		return nil, err
+	}
+	actorIDs := make([]int64, 0, len(casts))
+	for _, link := range casts {
+		actorIDs = append(actorIDs, int64(link.ActorID))
+	}
+	actorNames := make(map[int64]string, len(actorIDs))
+	if len(actorIDs) > 0 {
+		actors, err := db.GetActorsByIDs(s.db, actorIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, actor := range actors {
+			actorNames[actor.ID] = actor.Name
+		}
+	}
+	externalIDs, err := db.GetFilmsExternalIDs(s.db, filmIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*filmotekav1.Film, len(films))
+	messages := make([]*filmotekav1.Film, 0, len(films))
+	for _, film := range films {
+		message := &filmotekav1.Film{
+			Id:          int64(film.ID),
+			Name:        film.Name,
+			Description: film.Description,
+			Date:        film.Date.Format(db.DateLayout),
+			Rate:        int32(film.Rate),
+			Cast:        []*filmotekav1.CastMember{},
+			ExternalIds: map[string]string{},
+		}
+		byID[film.ID] = message
+		messages = append(messages, message)
+	}
+	for _, link := range casts {
+		message := byID[link.FilmID]
+		message.Cast = append(message.Cast, &filmotekav1.CastMember{
+			ActorId:   int64(link.ActorID),
+			ActorName: actorNames[int64(link.ActorID)],
+			Role:      link.Role,
+		})
+	}
+	for _, id := range externalIDs {
+		byID[id.FilmID].ExternalIds[id.Source] = id.Value
+	}
+	return messages, nil
+}
+
+// castInput splits cast members into actor ids and their roles like REST
+// bodies carry them.
+func castInput(cast []*filmotekav1.CastInput) ([]int, map[int]string) {
+	actors := make([]int, 0, len(cast))
+	roles := make(map[int]string, len(cast))
+	for _, member := range cast {
+		actors = append(actors, int(member.GetActorId()))
+		if member.GetRole() != "" {
+			roles[int(member.GetActorId())] = member.GetRole()
+		}
+	}
+	return actors, roles
+}
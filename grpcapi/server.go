@@ -0,0 +1,56 @@
+// Package grpcapi serves the catalogue over gRPC with the same storage,
+// users and validation as the REST API.
+package grpcapi
+
+import (
+	"filmoteka/config"
+	filmotekav1 "filmoteka/proto/filmoteka/v1"
+	"log/slog"
+	"net"
+
+	"github.com/go-pg/pg/v10"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// streamBatchSize is how many streamed rows share relation queries.
+const streamBatchSize = 100
+
+// NewServer registers catalogue, health and reflection services, callers
+// of catalogue services must authenticate like in REST API.
+func NewServer(pgdb *pg.DB) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryAuth(pgdb)),
+		grpc.ChainStreamInterceptor(streamAuth(pgdb)),
+	)
+
+	filmotekav1.RegisterFilmServiceServer(srv, &filmServer{db: pgdb})
+	filmotekav1.RegisterActorServiceServer(srv, &actorServer{db: pgdb})
+	filmotekav1.RegisterUserServiceServer(srv, &userServer{})
+
+	healthServer := health.NewServer()
+	for _, service := range []string{
+		filmotekav1.FilmService_ServiceDesc.ServiceName,
+		filmotekav1.ActorService_ServiceDesc.ServiceName,
+		filmotekav1.UserService_ServiceDesc.ServiceName,
+	} {
+		healthServer.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	healthpb.RegisterHealthServer(srv, healthServer)
+	reflection.Register(srv)
+
+	return srv
+}
+
+// ListenAndServe serves srv on the configured address until it is stopped.
+func ListenAndServe(srv *grpc.Server, cfg config.GRPCServer) error {
+	lis, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Success start gRPC server", "address", cfg.Address)
+	return srv.Serve(lis)
+}
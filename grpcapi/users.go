@@ -0,0 +1,21 @@
+package grpcapi
+
+import (
+	"context"
+	"filmoteka/db"
+	filmotekav1 "filmoteka/proto/filmoteka/v1"
+)
+
+var roles = map[string]filmotekav1.Role{
+	db.Admin:  filmotekav1.Role_ROLE_ADMIN,
+	db.Client: filmotekav1.Role_ROLE_CLIENT,
+}
+
+type userServer struct {
+	filmotekav1.UnimplementedUserServiceServer
+}
+
+func (s *userServer) GetCurrentUser(ctx context.Context, req *filmotekav1.GetCurrentUserRequest) (*filmotekav1.User, error) {
+	u := currentUser(ctx)
+	return &filmotekav1.User{Username: u.name, Role: roles[u.role]}, nil
+}
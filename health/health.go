@@ -0,0 +1,127 @@
+// Package health runs checks of service dependencies for readiness probes.
+package health
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Statuses of checks and reports.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check returns an error when the dependency can not serve requests, it
+// must return when ctx is done.
+type Check func(ctx context.Context) error
+
+// Registry keeps named checks and runs each with the timeout.
+type Registry struct {
+	mu      sync.RWMutex
+	timeout time.Duration
+	names   []string
+	checks  map[string]Check
+}
+
+// Result of a check, latency is in milliseconds.
+type Result struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`
+	Latency float64 `json:"latency_ms"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// Report is ok when all its checks are.
+type Report struct {
+	Status string    `json:"status"`
+	Checks []*Result `json:"checks"`
+}
+
+// NewRegistry runs each check with the timeout, zero disables it.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{timeout: timeout, checks: map[string]Check{}}
+}
+
+// Register adds the check, a check with the same name is replaced.
+func (reg *Registry) Register(name string, check Check) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if _, ok := reg.checks[name]; !ok {
+		reg.names = append(reg.names, name)
+	}
+	reg.checks[name] = check
+}
+
+// Run runs the named checks at once, all of them when no names are given.
+// Results are in the order checks were registered.
+func (reg *Registry) Run(ctx context.Context, names ...string) *Report {
+	reg.mu.RLock()
+	results := make([]*Result, 0, len(reg.names))
+	checks := make([]Check, 0, len(reg.names))
+	for _, name := range reg.names {
+		if len(names) > 0 && !slices.Contains(names, name) {
+			continue
+		}
+		results = append(results, &Result{Name: name})
+		checks = append(checks, reg.checks[name])
+	}
+	reg.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(result *Result, check Check) {
+			defer wg.Done()
+			reg.run(ctx, result, check)
+		}(results[i], checks[i])
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (reg *Registry) run(ctx context.Context, result *Result, check Check) {
+	if reg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, reg.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := check(ctx)
+	result.Latency = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		result.Status, result.Error = StatusFail, err.Error()
+		return
+	}
+	result.Status = StatusOK
+}
+
+// Fail adds a failed result which is not a dependency check, like
+// shutdown of the service.
+func (r *Report) Fail(name string, err error) {
+	r.Status = StatusFail
+	r.Checks = append(r.Checks, &Result{Name: name, Status: StatusFail, Error: err.Error()})
+}
+
+// Err joins errors of failed checks.
+func (r *Report) Err() error {
+	failed := make([]error, 0)
+	for _, result := range r.Checks {
+		if result.Status != StatusOK {
+			failed = append(failed, errors.New(result.Name+": "+result.Error))
+		}
+	}
+	return errors.Join(failed...)
+}
@@ -0,0 +1,358 @@
+// Package importer loads films and actors from partner catalogue dumps,
+// rows are matched to existing ones by natural key and upserted.
+package importer
+
+import (
+	"context"
+	"errors"
+	"filmoteka/db"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	TypeFilms  = "films"
+	TypeActors = "actors"
+
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+
+	// MaxRowErrors limits errors kept in the report, all of them are counted.
+	MaxRowErrors = 100
+)
+
+type Options struct {
+	Type   string `json:"type"`
+	Format string `json:"format"`
+	// DryRun validates rows and resolves cast without writing anything.
+	DryRun bool `json:"dry_run"`
+	// Mapping renames source columns to fields of films or actors.
+	Mapping map[string]string `json:"mapping,omitempty"`
+}
+
+func (o *Options) Validate() error {
+	if o.Type != TypeFilms && o.Type != TypeActors {
+		return fmt.Errorf("unknown type %q, use films or actors", o.Type)
+	}
+	if o.Format != FormatCSV && o.Format != FormatNDJSON {
+		return fmt.Errorf("unknown format %q, use csv or ndjson", o.Format)
+	}
+	return nil
+}
+
+// ParseMapping reads "source=field" pairs separated by commas.
+func ParseMapping(value string) (map[string]string, error) {
+	mapping := map[string]string{}
+	if strings.TrimSpace(value) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		column, field, ok := strings.Cut(pair, "=")
+		column = strings.ToLower(strings.TrimSpace(column))
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || column == "" || field == "" {
+			return nil, fmt.Errorf("invalid mapping %q, use column=field", pair)
+		}
+		mapping[column] = field
+	}
+	return mapping, nil
+}
+
+// FormatByName guesses format from file extension, empty when unknown.
+func FormatByName(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return FormatCSV
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	return ""
+}
+
+type RowError struct {
+	// Row is a line number of the source, header is line 1 in CSV.
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *RowError) Error() string {
+	if e.Field != "" {
+		return e.Field + ": " + e.Message
+	}
+	return e.Message
+}
+
+type Report struct {
+	DryRun    bool        `json:"dry_run"`
+	Processed int         `json:"processed"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Failed    int         `json:"failed"`
+	Errors    []*RowError `json:"errors"`
+}
+
+func (r *Report) fail(line int, err error) {
+	r.Failed++
+	if len(r.Errors) >= MaxRowErrors {
+		return
+	}
+	rowErr := &RowError{}
+	if !errors.As(err, &rowErr) {
+		rowErr = &RowError{Message: err.Error()}
+	}
+	rowErr.Row = line
+	r.Errors = append(r.Errors, rowErr)
+}
+
+var validate = validator.New()
+
+func init() {
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		return name
+	})
+}
+
+// Run reads all rows from r and upserts them one by one, each row in its
+// own transaction. Broken rows are reported and skipped, returned error
+// means the source can not be read at all. Progress is called after
+// every row with the report so far.
+func Run(ctx context.Context, pgdb *pg.DB, r io.Reader, opts Options, progress func(*Report)) (*Report, error) {
+	report := &Report{DryRun: opts.DryRun, Errors: make([]*RowError, 0)}
+
+	err := opts.Validate()
+	if err != nil {
+		return report, err
+	}
+	reader, err := newRecordReader(r, opts.Format, opts.Mapping)
+	if err != nil {
+		return report, err
+	}
+
+	upsert := upsertFilm
+	if opts.Type == TypeActors {
+		upsert = upsertActor
+	}
+
+	for {
+		err = ctx.Err()
+		if err != nil {
+			return report, err
+		}
+		rec, err := reader.next()
+		if err == io.EOF {
+			return report, nil
+		}
+		if err != nil {
+			return report, err
+		}
+
+		report.Processed++
+		created, err := runRow(ctx, pgdb, rec, opts.DryRun, upsert)
+		switch {
+		case err != nil:
+			report.fail(rec.line, err)
+		case created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		if progress != nil {
+			progress(report)
+		}
+	}
+}
+
+// upsertFunc writes the row when dry run is off and reports whether a new
+// film or actor would be created.
+type upsertFunc func(tx orm.DB, rec *record, dryRun bool) (bool, error)
+
+func runRow(ctx context.Context, pgdb *pg.DB, rec *record, dryRun bool, upsert upsertFunc) (bool, error) {
+	if rec.err != nil {
+		return false, rec.err
+	}
+	if dryRun {
+		return upsert(pgdb, rec, true)
+	}
+
+	var created bool
+	err := pgdb.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		var err error
+		created, err = upsert(tx, rec, false)
+		return err
+	})
+	return created, err
+}
+
+func upsertFilm(tx orm.DB, rec *record, dryRun bool) (bool, error) {
+	name := rec.values["name"]
+	if name == "" {
+		return false, &RowError{Field: "name", Message: "is required"}
+	}
+	date, err := parseDate(rec, "date")
+	if err != nil {
+		return false, err
+	}
+
+	film, err := db.FindFilm(tx, name, date)
+	created := errors.Is(err, pg.ErrNoRows)
+	if err != nil && !created {
+		return false, err
+	}
+	if created {
+		film = &db.Film{Name: name, Date: date}
+	}
+
+	if description, ok := rec.values["description"]; ok {
+		film.Description = description
+	}
+	if rate := rec.values["rate"]; rate != "" {
+		film.Rate, err = strconv.Atoi(rate)
+		if err != nil {
+			return false, &RowError{Field: "rate", Message: "must be an integer"}
+		}
+	}
+	err = validateRow(film)
+	if err != nil {
+		return false, err
+	}
+
+	actors, roles, err := resolveCast(tx, rec.cast)
+	if err != nil || dryRun {
+		return created, err
+	}
+
+	if created {
+		_, err = db.CreateFilm(tx, film, actors, roles)
+		return created, err
+	}
+	_, err = db.UpdateFilm(tx, film)
+	if err != nil {
+		return created, err
+	}
+	if rec.hasCast {
+		err = db.SetFilmActors(tx, film.ID, actors, roles)
+	}
+	return created, err
+}
+
+func upsertActor(tx orm.DB, rec *record, dryRun bool) (bool, error) {
+	name := rec.values["name"]
+	if name == "" {
+		return false, &RowError{Field: "name", Message: "is required"}
+	}
+	birth, err := parseDate(rec, "birth")
+	if err != nil {
+		return false, err
+	}
+
+	actor, err := db.FindActor(tx, name, birth)
+	created := errors.Is(err, pg.ErrNoRows)
+	if err != nil && !created {
+		return false, err
+	}
+	if created {
+		actor = &db.Actor{Name: name, Birth: birth}
+	}
+
+	if sex := rec.values["sex"]; sex != "" {
+		actor.Sex = strings.ToLower(sex)
+	}
+	err = validateRow(actor)
+	if err != nil || dryRun {
+		return created, err
+	}
+
+	if created {
+		_, err = db.CreateActor(tx, actor)
+	} else {
+		_, err = db.UpdateActor(tx, actor)
+	}
+	return created, err
+}
+
+func parseDate(rec *record, field string) (time.Time, error) {
+	value := rec.values[field]
+	if value == "" {
+		return time.Time{}, &RowError{Field: field, Message: "is required"}
+	}
+	date, err := time.Parse(db.DateLayout, value)
+	if err != nil {
+		return time.Time{}, &RowError{Field: field, Message: "must be a date in YYYY-MM-DD format"}
+	}
+	return date, nil
+}
+
+// validateRow checks the model against its validate tags, the first failed
+// field is reported.
+func validateRow(model interface{}) error {
+	err := validate.Struct(model)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) == 0 {
+		return err
+	}
+	fe := fieldErrs[0]
+	message := "failed on " + fe.Tag()
+	if fe.Param() != "" {
+		message += " " + fe.Param()
+	}
+	return &RowError{Field: fe.Field(), Message: message}
+}
+
+// resolveCast turns cast references into actor ids, names must match
+// exactly one actor.
+func resolveCast(tx orm.DB, cast []*castRef) ([]int, map[int]string, error) {
+	actors := make([]int, 0, len(cast))
+	roles := make(map[int]string, len(cast))
+
+	for _, ref := range cast {
+		id := ref.ID
+		if ref.ExternalID != "" {
+			actor, err := db.FindActorByExternalID(tx, ref.Source, ref.ExternalID)
+			if errors.Is(err, pg.ErrNoRows) {
+				return nil, nil, &RowError{Field: "actors", Message: fmt.Sprintf("actor %s:%s not found", ref.Source, ref.ExternalID)}
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			id = actor.ID
+		} else if id != 0 {
+			ok, err := db.ActorExists(tx, id)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !ok {
+				return nil, nil, &RowError{Field: "actors", Message: fmt.Sprintf("actor #%d not found", id)}
+			}
+		} else {
+			found, err := db.FindActorsByName(tx, ref.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch len(found) {
+			case 0:
+				return nil, nil, &RowError{Field: "actors", Message: fmt.Sprintf("actor %q not found", ref.Name)}
+			case 1:
+				id = found[0].ID
+			default:
+				return nil, nil, &RowError{Field: "actors", Message: fmt.Sprintf("actor %q is ambiguous, %d actors have this name, use #id", ref.Name, len(found))}
+			}
+		}
+
+		if _, ok := roles[int(id)]; !ok {
+			actors = append(actors, int(id))
+		}
+		roles[int(id)] = ref.Role
+	}
+	return actors, roles, nil
+}
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-pg/pg/v10"
+)
+
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+	JobFailed  = "failed"
+
+	// JobRetention is how long finished jobs can be polled.
+	JobRetention = time.Hour
+)
+
+// Job is a snapshot of a background import, progress is the share of
+// source bytes read so far.
+type Job struct {
+	ID         string     `json:"id"`
+	Options    Options    `json:"options"`
+	Status     string     `json:"status" enums:"queued,running,done,failed"`
+	Size       int64      `json:"size"`
+	Read       int64      `json:"read"`
+	Progress   float64    `json:"progress"`
+	Report     Report     `json:"report"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Jobs runs imports in background and keeps their state in memory.
+type Jobs struct {
+	// MaxSize limits size of the source in bytes, zero is unlimited.
+	MaxSize int64
+
+	db   *pg.DB
+	mu   sync.Mutex
+	jobs map[string]*Job
+
+	// ctx of running imports is canceled when Wait gives up.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+func NewJobs(db *pg.DB) *Jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Jobs{db: db, jobs: map[string]*Job{}, ctx: ctx, cancel: cancel}
+}
+
+// Start imports the spooled file in background and removes it when done.
+func (j *Jobs) Start(opts Options, path string, size int64) *Job {
+	job := &Job{
+		ID:        newJobID(),
+		Options:   opts,
+		Status:    JobQueued,
+		Size:      size,
+		Report:    Report{DryRun: opts.DryRun, Errors: make([]*RowError, 0)},
+		CreatedAt: time.Now(),
+	}
+
+	j.mu.Lock()
+	j.prune()
+	j.jobs[job.ID] = job
+	snapshot := *job
+	j.mu.Unlock()
+
+	j.running.Add(1)
+	go j.run(job, path)
+	return &snapshot
+}
+
+// Wait returns when running imports are finished. When ctx is done first
+// they are canceled, rows of the current batch are rolled back and spooled
+// files removed, before it returns ctx error.
+func (j *Jobs) Wait(ctx context.Context) error {
+	finished := make(chan struct{})
+	go func() {
+		j.running.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		j.cancel()
+		<-finished
+		return ctx.Err()
+	}
+}
+
+// Get returns a copy of the job state, false when it is unknown or expired.
+func (j *Jobs) Get(id string) (*Job, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job, ok := j.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+func (j *Jobs) run(job *Job, path string) {
+	defer j.running.Done()
+	defer os.Remove(path)
+
+	j.update(job, func(job *Job) { job.Status = JobRunning })
+
+	report, err := j.importFile(job, path)
+	if report == nil {
+		report = &Report{DryRun: job.Options.DryRun, Errors: make([]*RowError, 0)}
+	}
+
+	j.update(job, func(job *Job) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Report = *report
+		if err != nil {
+			job.Status = JobFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = JobDone
+		job.Read = job.Size
+		job.Progress = 1
+	})
+	slog.Info("import finished", "job", job.ID, "processed", report.Processed, "failed", report.Failed, "err", err)
+}
+
+func (j *Jobs) importFile(job *Job, path string) (*Report, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	counter := &countingReader{reader: file}
+	return Run(j.ctx, j.db, counter, job.Options, func(report *Report) {
+		j.update(job, func(job *Job) {
+			job.Report = *report
+			job.Read = counter.read
+			if job.Size > 0 {
+				job.Progress = float64(job.Read) / float64(job.Size)
+			}
+		})
+	})
+}
+
+func (j *Jobs) update(job *Job, change func(*Job)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	change(job)
+}
+
+// prune drops jobs finished longer than JobRetention ago, mu must be held.
+func (j *Jobs) prune() {
+	for id, job := range j.jobs {
+		if job.FinishedAt != nil && time.Since(*job.FinishedAt) > JobRetention {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+func newJobID() string {
+	buf := make([]byte, 12)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+type countingReader struct {
+	reader io.Reader
+	read   int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,261 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"filmoteka/db"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// castSeparator splits names of the cast column in CSV files.
+const castSeparator = ";"
+
+// castRef points to an actor by id, external id or name, role is optional.
+type castRef struct {
+	ID         int64  `json:"id"`
+	Source     string `json:"source"`
+	ExternalID string `json:"external_id"`
+	Name       string `json:"name"`
+	Role       string `json:"role"`
+}
+
+// record is one row of the source with field names already mapped.
+type record struct {
+	line   int
+	values map[string]string
+	cast   []*castRef
+	// hasCast is set when the source has actors column, even empty.
+	hasCast bool
+	// err is set when the row can not be parsed, reading goes on.
+	err error
+}
+
+type recordReader interface {
+	// next returns io.EOF when the source is exhausted.
+	next() (*record, error)
+}
+
+// aliases are column names partners commonly use for our fields.
+var aliases = map[string]string{
+	"title":        "name",
+	"release_date": "date",
+	"rating":       "rate",
+	"cast":         "actors",
+	"gender":       "sex",
+	"birthday":     "birth",
+}
+
+func fieldName(column string, mapping map[string]string) string {
+	column = strings.ToLower(strings.TrimSpace(column))
+	if field, ok := mapping[column]; ok {
+		return field
+	}
+	if field, ok := aliases[column]; ok {
+		return field
+	}
+	return column
+}
+
+func newRecordReader(r io.Reader, format string, mapping map[string]string) (recordReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r, mapping)
+	case FormatNDJSON:
+		return &ndjsonReader{scanner: newLineScanner(r), mapping: mapping}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, use csv or ndjson", format)
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	fields []string
+}
+
+func newCSVReader(r io.Reader, mapping map[string]string) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv header is missing")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can not read csv header: %w", err)
+	}
+
+	fields := make([]string, len(header))
+	for i, column := range header {
+		// Excel puts byte order mark before the first column.
+		fields[i] = fieldName(strings.TrimPrefix(column, "\ufeff"), mapping)
+	}
+	return &csvReader{reader: reader, fields: fields}, nil
+}
+
+func (c *csvReader) next() (*record, error) {
+	row, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	rec := &record{values: make(map[string]string, len(c.fields))}
+	if err != nil {
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) {
+			return nil, err
+		}
+		rec.line = parseErr.StartLine
+		rec.err = parseErr.Err
+		return rec, nil
+	}
+	rec.line, _ = c.reader.FieldPos(0)
+	if len(row) != len(c.fields) {
+		rec.err = fmt.Errorf("expected %d columns, got %d", len(c.fields), len(row))
+		return rec, nil
+	}
+
+	for i, field := range c.fields {
+		value := strings.TrimSpace(row[i])
+		if field != "actors" {
+			rec.values[field] = value
+			continue
+		}
+		rec.hasCast = true
+		for _, name := range strings.Split(value, castSeparator) {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				rec.cast = append(rec.cast, parseCastRef(name))
+			}
+		}
+	}
+	return rec, nil
+}
+
+// parseCastRef reads "#42" as actor id, "imdb:nm0000206" as external id
+// of a known source and anything else as actor name.
+func parseCastRef(value string) *castRef {
+	if strings.HasPrefix(value, "#") {
+		id, err := strconv.ParseInt(value[1:], 10, 64)
+		if err == nil {
+			return &castRef{ID: id}
+		}
+	}
+	source, externalID, ok := strings.Cut(value, ":")
+	if ok && externalID != "" && db.IsExternalSource(source) {
+		return &castRef{Source: source, ExternalID: externalID}
+	}
+	return &castRef{Name: value}
+}
+
+// maxLineSize limits one NDJSON line, long descriptions fit easily.
+const maxLineSize = 1 << 20
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	return scanner
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	mapping map[string]string
+	line    int
+}
+
+func (n *ndjsonReader) next() (*record, error) {
+	for n.scanner.Scan() {
+		n.line++
+		data := bytes.TrimSpace(n.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		rec := &record{line: n.line, values: map[string]string{}}
+		object := map[string]json.RawMessage{}
+		err := json.Unmarshal(data, &object)
+		if err != nil {
+			rec.err = fmt.Errorf("invalid json: %w", err)
+			return rec, nil
+		}
+		for key, raw := range object {
+			field := fieldName(key, n.mapping)
+			if field == "actors" {
+				rec.hasCast = true
+				rec.cast, err = parseJSONCast(raw)
+			} else {
+				rec.values[field], err = jsonScalar(raw)
+			}
+			if err != nil {
+				rec.err = fmt.Errorf("%s: %w", key, err)
+				break
+			}
+		}
+		return rec, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// jsonScalar renders string, number, boolean or null as field text.
+func jsonScalar(raw json.RawMessage) (string, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return strings.TrimSpace(v), nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", errors.New("expected string or number")
+}
+
+// parseJSONCast accepts array of names, ids or {"id", "name", "role"} objects.
+func parseJSONCast(raw json.RawMessage) ([]*castRef, error) {
+	items := make([]json.RawMessage, 0)
+	err := json.Unmarshal(raw, &items)
+	if err != nil {
+		return nil, errors.New("expected array")
+	}
+
+	cast := make([]*castRef, 0, len(items))
+	for _, item := range items {
+		var name string
+		if json.Unmarshal(item, &name) == nil {
+			cast = append(cast, parseCastRef(strings.TrimSpace(name)))
+			continue
+		}
+		var id int64
+		if json.Unmarshal(item, &id) == nil {
+			cast = append(cast, &castRef{ID: id})
+			continue
+		}
+		ref := &castRef{}
+		err = json.Unmarshal(item, ref)
+		if err != nil || (ref.ID == 0 && ref.Name == "" && ref.ExternalID == "") {
+			return nil, errors.New("expected actor name, id or object with id, external id or name")
+		}
+		if ref.ExternalID != "" && !db.IsExternalSource(ref.Source) {
+			return nil, fmt.Errorf("unknown source %q", ref.Source)
+		}
+		ref.Name = strings.TrimSpace(ref.Name)
+		cast = append(cast, ref)
+	}
+	return cast, nil
+}
@@ -0,0 +1,173 @@
+// Package openapi reads a Swagger 2.0 document and checks values against
+// its schemas, it knows only the parts of the format the API docs use.
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type Schema struct {
+	Ref       string        `json:"$ref"`
+	Type      string        `json:"type"`
+	Format    string        `json:"format"`
+	Enum      []interface{} `json:"enum"`
+	Minimum   *float64      `json:"minimum"`
+	Maximum   *float64      `json:"maximum"`
+	MinLength *int          `json:"minLength"`
+	MaxLength *int          `json:"maxLength"`
+	// Required lists members of objects which must be present.
+	Required   []string           `json:"required"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	// AdditionalProperties is a schema of map values or true for any.
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+}
+
+// Parameter keeps the type of path, query and header params inline, body
+// params have Body schema instead.
+type Parameter struct {
+	Schema
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Body     *Schema `json:"schema"`
+}
+
+type Response struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Operation struct {
+	Consumes   []string             `json:"consumes"`
+	Parameters []*Parameter         `json:"parameters"`
+	Responses  map[string]*Response `json:"responses"`
+}
+
+// BodyParam returns the body param of the operation or nil.
+func (o *Operation) BodyParam() *Parameter {
+	for _, param := range o.Parameters {
+		if param.In == "body" {
+			return param
+		}
+	}
+	return nil
+}
+
+type Spec struct {
+	BasePath    string                           `json:"basePath"`
+	Paths       map[string]map[string]*Operation `json:"paths"`
+	Definitions map[string]*Schema               `json:"definitions"`
+
+	// Formats check strings by format of their schema, other formats
+	// are not checked.
+	Formats map[string]func(string) bool `json:"-"`
+
+	routes []*route
+}
+
+type route struct {
+	method    string
+	path      string
+	segments  []string
+	operation *Operation
+}
+
+func Parse(data []byte) (*Spec, error) {
+	spec := &Spec{Formats: map[string]func(string) bool{}}
+	err := json.Unmarshal(data, spec)
+	if err != nil {
+		return nil, err
+	}
+	for path, methods := range spec.Paths {
+		for method, operation := range methods {
+			spec.routes = append(spec.routes, &route{
+				method:    strings.ToUpper(method),
+				path:      path,
+				segments:  splitPath(path),
+				operation: operation,
+			})
+		}
+	}
+	return spec, nil
+}
+
+// Operations lists documented operations as "METHOD /path".
+func (s *Spec) Operations() []string {
+	operations := make([]string, 0, len(s.routes))
+	for _, route := range s.routes {
+		operations = append(operations, route.method+" "+route.path)
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// Find returns the operation serving the path relative to the base path
+// with values of its path params, literal segments win over params like in
+// the router.
+func (s *Spec) Find(method string, path string) (*Operation, map[string]string) {
+	segments := splitPath(path)
+
+	var found *route
+	bestScore := -1
+	for _, route := range s.routes {
+		if route.method != method || len(route.segments) != len(segments) {
+			continue
+		}
+		score, ok := route.match(segments)
+		if ok && score > bestScore {
+			found, bestScore = route, score
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+
+	params := map[string]string{}
+	for i, segment := range found.segments {
+		if name, ok := paramName(segment); ok {
+			params[name] = segments[i]
+		}
+	}
+	return found.operation, params
+}
+
+// match scores earlier literal segments higher.
+func (r *route) match(segments []string) (int, bool) {
+	score := 0
+	for i, segment := range r.segments {
+		score <<= 1
+		if _, ok := paramName(segment); ok {
+			continue
+		}
+		if segment != segments[i] {
+			return 0, false
+		}
+		score |= 1
+	}
+	return score, true
+}
+
+// Resolve follows references of the schema.
+func (s *Spec) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+	}
+	return schema
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+func paramName(segment string) (string, bool) {
+	if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
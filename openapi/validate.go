@@ -0,0 +1,251 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rules of problems are named after schema keywords.
+const (
+	RuleRequired  = "required"
+	RuleType      = "type"
+	RuleEnum      = "enum"
+	RuleFormat    = "format"
+	RuleMinimum   = "minimum"
+	RuleMaximum   = "maximum"
+	RuleMinLength = "minLength"
+	RuleMaxLength = "maxLength"
+	// RuleUndocumented is an object member missing in the schema, it is
+	// reported only by strict validation.
+	RuleUndocumented = "undocumented"
+)
+
+// Problem is a value which does not match its schema.
+type Problem struct {
+	// Path is a JSON path of the body value or a name of the param.
+	Path string
+	Rule string
+	// Param is the value of the broken keyword, like a type or bounds.
+	Param string
+}
+
+func (p *Problem) Error() string {
+	if p.Param == "" {
+		return p.Path + ": " + p.Rule
+	}
+	return fmt.Sprintf("%s: %s %s", p.Path, p.Rule, p.Param)
+}
+
+// Validate checks a decoded JSON value, members of objects missing in the
+// schema are allowed.
+func (s *Spec) Validate(schema *Schema, value interface{}, path string) []*Problem {
+	return s.validate(schema, value, path, false)
+}
+
+// ValidateStrict is Validate which also reports undocumented members.
+func (s *Spec) ValidateStrict(schema *Schema, value interface{}, path string) []*Problem {
+	return s.validate(schema, value, path, true)
+}
+
+// ValidateParam converts raw values of a path, query or header param to its
+// type and checks them, missing and empty values are checked only for
+// presence.
+func (s *Spec) ValidateParam(param *Parameter, values []string) []*Problem {
+	if len(values) == 0 || values[0] == "" {
+		if param.Required {
+			return []*Problem{{Path: param.Name, Rule: RuleRequired}}
+		}
+		return nil
+	}
+	if param.Type == "array" {
+		items := make([]interface{}, 0)
+		for _, value := range values {
+			for _, item := range strings.Split(value, ",") {
+				items = append(items, parseParam(param.Items, item))
+			}
+		}
+		return s.validate(&param.Schema, items, param.Name, false)
+	}
+	return s.validate(&param.Schema, parseParam(&param.Schema, values[0]), param.Name, false)
+}
+
+// parseParam converts the value to the JSON type of the schema, values
+// which can not be converted stay strings and fail the type check.
+func parseParam(schema *Schema, value string) interface{} {
+	if schema == nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer":
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err == nil {
+			return float64(number)
+		}
+	case "number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return number
+		}
+	case "boolean":
+		flag, err := strconv.ParseBool(value)
+		if err == nil {
+			return flag
+		}
+	}
+	return value
+}
+
+func (s *Spec) validate(schema *Schema, value interface{}, path string, strict bool) []*Problem {
+	schema = s.Resolve(schema)
+	// Swagger 2.0 has no nullable, omitted and null values are the same.
+	if schema == nil || value == nil {
+		return nil
+	}
+
+	problem := func(rule string, param string) []*Problem {
+		return []*Problem{{Path: path, Rule: rule, Param: param}}
+	}
+	if !hasType(schema.Type, value) {
+		return problem(RuleType, schema.Type)
+	}
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		allowed := make([]string, 0, len(schema.Enum))
+		for _, item := range schema.Enum {
+			allowed = append(allowed, fmt.Sprint(item))
+		}
+		return problem(RuleEnum, strings.Join(allowed, " "))
+	}
+
+	switch value := value.(type) {
+	case float64:
+		if schema.Minimum != nil && value < *schema.Minimum {
+			return problem(RuleMinimum, formatNumber(*schema.Minimum))
+		}
+		if schema.Maximum != nil && value > *schema.Maximum {
+			return problem(RuleMaximum, formatNumber(*schema.Maximum))
+		}
+	case string:
+		length := utf8.RuneCountInString(value)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			return problem(RuleMinLength, strconv.Itoa(*schema.MinLength))
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			return problem(RuleMaxLength, strconv.Itoa(*schema.MaxLength))
+		}
+		check, ok := s.Formats[schema.Format]
+		if ok && !check(value) {
+			return problem(RuleFormat, schema.Format)
+		}
+	case []interface{}:
+		problems := make([]*Problem, 0)
+		for i, item := range value {
+			itemPath := path + "[" + strconv.Itoa(i) + "]"
+			problems = append(problems, s.validate(schema.Items, item, itemPath, strict)...)
+		}
+		return problems
+	case map[string]interface{}:
+		return s.validateObject(schema, value, path, strict)
+	}
+	return nil
+}
+
+func (s *Spec) validateObject(schema *Schema, members map[string]interface{}, path string, strict bool) []*Problem {
+	problems := make([]*Problem, 0)
+	for _, name := range schema.Required {
+		if _, ok := members[name]; !ok {
+			problems = append(problems, &Problem{Path: memberPath(path, name), Rule: RuleRequired})
+		}
+	}
+
+	// Members are sorted to report problems in the same order.
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := additionalSchema(schema.AdditionalProperties)
+	for _, name := range names {
+		member := members[name]
+		memberPath := memberPath(path, name)
+		if property, ok := schema.Properties[name]; ok {
+			problems = append(problems, s.validate(property, member, memberPath, strict)...)
+			continue
+		}
+		switch {
+		case values != nil:
+			problems = append(problems, s.validate(values, member, memberPath, strict)...)
+		case strict && !allowsAdditional(schema.AdditionalProperties):
+			problems = append(problems, &Problem{Path: memberPath, Rule: RuleUndocumented})
+		}
+	}
+	return problems
+}
+
+// additionalSchema returns the schema of map values, it is nil when any
+// values are allowed or the object is not a map.
+func additionalSchema(raw json.RawMessage) *Schema {
+	if !strings.HasPrefix(strings.TrimSpace(string(raw)), "{") {
+		return nil
+	}
+	schema := &Schema{}
+	err := json.Unmarshal(raw, schema)
+	if err != nil || (schema.Type == "" && schema.Ref == "") {
+		return nil
+	}
+	return schema
+}
+
+// allowsAdditional is true for maps of any values.
+func allowsAdditional(raw json.RawMessage) bool {
+	additional := strings.TrimSpace(string(raw))
+	return additional == "true" || strings.HasPrefix(additional, "{")
+}
+
+func hasType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == float64(int64(number))
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	}
+	return true
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, item := range enum {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func memberPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
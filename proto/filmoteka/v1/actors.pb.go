@@ -0,0 +1,667 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: filmoteka/v1/actors.proto
+
+package filmotekav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Sex int32
+
+const (
+	Sex_SEX_UNSPECIFIED Sex = 0
+	Sex_SEX_MALE        Sex = 1
+	Sex_SEX_FEMALE      Sex = 2
+)
+
+// Enum value maps for Sex.
+var (
+	Sex_name = map[int32]string{
+		0: "SEX_UNSPECIFIED",
+		1: "SEX_MALE",
+		2: "SEX_FEMALE",
+	}
+	Sex_value = map[string]int32{
+		"SEX_UNSPECIFIED": 0,
+		"SEX_MALE":        1,
+		"SEX_FEMALE":      2,
+	}
+)
+
+func (x Sex) Enum() *Sex {
+	p := new(Sex)
+	*p = x
+	return p
+}
+
+func (x Sex) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Sex) Descriptor() protoreflect.EnumDescriptor {
+	return file_filmoteka_v1_actors_proto_enumTypes[0].Descriptor()
+}
+
+func (Sex) Type() protoreflect.EnumType {
+	return &file_filmoteka_v1_actors_proto_enumTypes[0]
+}
+
+func (x Sex) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Sex.Descriptor instead.
+func (Sex) EnumDescriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{0}
+}
+
+type Actor struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Sex   Sex                    `protobuf:"varint,3,opt,name=sex,proto3,enum=filmoteka.v1.Sex" json:"sex,omitempty"`
+	// Birthday in YYYY-MM-DD format.
+	Birthday string       `protobuf:"bytes,4,opt,name=birthday,proto3" json:"birthday,omitempty"`
+	Films    []*ActorFilm `protobuf:"bytes,5,rep,name=films,proto3" json:"films,omitempty"`
+	// External catalogue, imdb, kinopoisk or tmdb, to the actor id in it.
+	ExternalIds   map[string]string `protobuf:"bytes,6,rep,name=external_ids,json=externalIds,proto3" json:"external_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Actor) Reset() {
+	*x = Actor{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Actor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Actor) ProtoMessage() {}
+
+func (x *Actor) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Actor.ProtoReflect.Descriptor instead.
+func (*Actor) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Actor) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Actor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Actor) GetSex() Sex {
+	if x != nil {
+		return x.Sex
+	}
+	return Sex_SEX_UNSPECIFIED
+}
+
+func (x *Actor) GetBirthday() string {
+	if x != nil {
+		return x.Birthday
+	}
+	return ""
+}
+
+func (x *Actor) GetFilms() []*ActorFilm {
+	if x != nil {
+		return x.Films
+	}
+	return nil
+}
+
+func (x *Actor) GetExternalIds() map[string]string {
+	if x != nil {
+		return x.ExternalIds
+	}
+	return nil
+}
+
+type ActorFilm struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FilmId        int64                  `protobuf:"varint,1,opt,name=film_id,json=filmId,proto3" json:"film_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActorFilm) Reset() {
+	*x = ActorFilm{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActorFilm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActorFilm) ProtoMessage() {}
+
+func (x *ActorFilm) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActorFilm.ProtoReflect.Descriptor instead.
+func (*ActorFilm) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ActorFilm) GetFilmId() int64 {
+	if x != nil {
+		return x.FilmId
+	}
+	return 0
+}
+
+func (x *ActorFilm) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ActorFilm) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type GetActorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetActorRequest) Reset() {
+	*x = GetActorRequest{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetActorRequest) ProtoMessage() {}
+
+func (x *GetActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetActorRequest.ProtoReflect.Descriptor instead.
+func (*GetActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetActorRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListActorsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActorsRequest) Reset() {
+	*x = ListActorsRequest{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActorsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActorsRequest) ProtoMessage() {}
+
+func (x *ListActorsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActorsRequest.ProtoReflect.Descriptor instead.
+func (*ListActorsRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{3}
+}
+
+type CreateActorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Sex           Sex                    `protobuf:"varint,2,opt,name=sex,proto3,enum=filmoteka.v1.Sex" json:"sex,omitempty"`
+	Birthday      string                 `protobuf:"bytes,3,opt,name=birthday,proto3" json:"birthday,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateActorRequest) Reset() {
+	*x = CreateActorRequest{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateActorRequest) ProtoMessage() {}
+
+func (x *CreateActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateActorRequest.ProtoReflect.Descriptor instead.
+func (*CreateActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateActorRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateActorRequest) GetSex() Sex {
+	if x != nil {
+		return x.Sex
+	}
+	return Sex_SEX_UNSPECIFIED
+}
+
+func (x *CreateActorRequest) GetBirthday() string {
+	if x != nil {
+		return x.Birthday
+	}
+	return ""
+}
+
+// UpdateActorRequest changes only present fields.
+type UpdateActorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Sex           *Sex                   `protobuf:"varint,3,opt,name=sex,proto3,enum=filmoteka.v1.Sex,oneof" json:"sex,omitempty"`
+	Birthday      *string                `protobuf:"bytes,4,opt,name=birthday,proto3,oneof" json:"birthday,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateActorRequest) Reset() {
+	*x = UpdateActorRequest{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateActorRequest) ProtoMessage() {}
+
+func (x *UpdateActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateActorRequest.ProtoReflect.Descriptor instead.
+func (*UpdateActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateActorRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateActorRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateActorRequest) GetSex() Sex {
+	if x != nil && x.Sex != nil {
+		return *x.Sex
+	}
+	return Sex_SEX_UNSPECIFIED
+}
+
+func (x *UpdateActorRequest) GetBirthday() string {
+	if x != nil && x.Birthday != nil {
+		return *x.Birthday
+	}
+	return ""
+}
+
+type DeleteActorRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteActorRequest) Reset() {
+	*x = DeleteActorRequest{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteActorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteActorRequest) ProtoMessage() {}
+
+func (x *DeleteActorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteActorRequest.ProtoReflect.Descriptor instead.
+func (*DeleteActorRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteActorRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteActorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteActorResponse) Reset() {
+	*x = DeleteActorResponse{}
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteActorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteActorResponse) ProtoMessage() {}
+
+func (x *DeleteActorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_actors_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteActorResponse.ProtoReflect.Descriptor instead.
+func (*DeleteActorResponse) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_actors_proto_rawDescGZIP(), []int{7}
+}
+
+var File_filmoteka_v1_actors_proto protoreflect.FileDescriptor
+
+var file_filmoteka_v1_actors_proto_rawDesc = string([]byte{
+	0x0a, 0x19, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x2f, 0x61,
+	0x63, 0x74, 0x6f, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x66, 0x69, 0x6c,
+	0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x22, 0xa4, 0x02, 0x0a, 0x05, 0x41, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x03, 0x73, 0x65, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x78, 0x52, 0x03, 0x73, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08,
+	0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x12, 0x2d, 0x0a, 0x05, 0x66, 0x69, 0x6c, 0x6d,
+	0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74,
+	0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x46, 0x69, 0x6c, 0x6d,
+	0x52, 0x05, 0x66, 0x69, 0x6c, 0x6d, 0x73, 0x12, 0x47, 0x0a, 0x0c, 0x65, 0x78, 0x74, 0x65, 0x72,
+	0x6e, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74,
+	0x6f, 0x72, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x49, 0x64, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x0b, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x49, 0x64, 0x73,
+	0x1a, 0x3e, 0x0a, 0x10, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x49, 0x64, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x4c, 0x0a, 0x09, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x46, 0x69, 0x6c, 0x6d, 0x12, 0x17, 0x0a,
+	0x07, 0x66, 0x69, 0x6c, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06,
+	0x66, 0x69, 0x6c, 0x6d, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f,
+	0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x22, 0x21,
+	0x0a, 0x0f, 0x47, 0x65, 0x74, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69,
+	0x64, 0x22, 0x13, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x69, 0x0a, 0x12, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x41, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x23, 0x0a, 0x03, 0x73, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x78,
+	0x52, 0x03, 0x73, 0x65, 0x78, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61,
+	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61,
+	0x79, 0x22, 0xa6, 0x01, 0x0a, 0x12, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f,
+	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x28, 0x0a, 0x03, 0x73, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11,
+	0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65,
+	0x78, 0x48, 0x01, 0x52, 0x03, 0x73, 0x65, 0x78, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x62,
+	0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52,
+	0x08, 0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x73, 0x65, 0x78, 0x42, 0x0b, 0x0a,
+	0x09, 0x5f, 0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x22, 0x24, 0x0a, 0x12, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64,
+	0x22, 0x15, 0x0a, 0x13, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a, 0x38, 0x0a, 0x03, 0x53, 0x65, 0x78, 0x12, 0x13,
+	0x0a, 0x0f, 0x53, 0x45, 0x58, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x53, 0x45, 0x58, 0x5f, 0x4d, 0x41, 0x4c, 0x45, 0x10,
+	0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x45, 0x58, 0x5f, 0x46, 0x45, 0x4d, 0x41, 0x4c, 0x45, 0x10,
+	0x02, 0x32, 0xf4, 0x02, 0x0a, 0x0c, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x3e, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x1d,
+	0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74,
+	0x6f, 0x72, 0x12, 0x44, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x73,
+	0x12, 0x1f, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x13, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31,
+	0x2e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x30, 0x01, 0x12, 0x44, 0x0a, 0x0b, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x20, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74,
+	0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x41, 0x63, 0x74,
+	0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x66, 0x69, 0x6c, 0x6d,
+	0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x44,
+	0x0a, 0x0b, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x20, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x13, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x63, 0x74, 0x6f, 0x72, 0x12, 0x52, 0x0a, 0x0b, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63,
+	0x74, 0x6f, 0x72, 0x12, 0x20, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b,
+	0x61, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x41, 0x63, 0x74, 0x6f, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2a, 0x5a, 0x28, 0x66, 0x69, 0x6c, 0x6d,
+	0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x66, 0x69, 0x6c, 0x6d,
+	0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x3b, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65,
+	0x6b, 0x61, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_filmoteka_v1_actors_proto_rawDescOnce sync.Once
+	file_filmoteka_v1_actors_proto_rawDescData []byte
+)
+
+func file_filmoteka_v1_actors_proto_rawDescGZIP() []byte {
+	file_filmoteka_v1_actors_proto_rawDescOnce.Do(func() {
+		file_filmoteka_v1_actors_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_filmoteka_v1_actors_proto_rawDesc), len(file_filmoteka_v1_actors_proto_rawDesc)))
+	})
+	return file_filmoteka_v1_actors_proto_rawDescData
+}
+
+var file_filmoteka_v1_actors_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_filmoteka_v1_actors_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_filmoteka_v1_actors_proto_goTypes = []any{
+	(Sex)(0),                    // 0: filmoteka.v1.Sex
+	(*Actor)(nil),               // 1: filmoteka.v1.Actor
+	(*ActorFilm)(nil),           // 2: filmoteka.v1.ActorFilm
+	(*GetActorRequest)(nil),     // 3: filmoteka.v1.GetActorRequest
+	(*ListActorsRequest)(nil),   // 4: filmoteka.v1.ListActorsRequest
+	(*CreateActorRequest)(nil),  // 5: filmoteka.v1.CreateActorRequest
+	(*UpdateActorRequest)(nil),  // 6: filmoteka.v1.UpdateActorRequest
+	(*DeleteActorRequest)(nil),  // 7: filmoteka.v1.DeleteActorRequest
+	(*DeleteActorResponse)(nil), // 8: filmoteka.v1.DeleteActorResponse
+	nil,                         // 9: filmoteka.v1.Actor.ExternalIdsEntry
+}
+var file_filmoteka_v1_actors_proto_depIdxs = []int32{
+	0,  // 0: filmoteka.v1.Actor.sex:type_name -> filmoteka.v1.Sex
+	2,  // 1: filmoteka.v1.Actor.films:type_name -> filmoteka.v1.ActorFilm
+	9,  // 2: filmoteka.v1.Actor.external_ids:type_name -> filmoteka.v1.Actor.ExternalIdsEntry
+	0,  // 3: filmoteka.v1.CreateActorRequest.sex:type_name -> filmoteka.v1.Sex
+	0,  // 4: filmoteka.v1.UpdateActorRequest.sex:type_name -> filmoteka.v1.Sex
+	3,  // 5: filmoteka.v1.ActorService.GetActor:input_type -> filmoteka.v1.GetActorRequest
+	4,  // 6: filmoteka.v1.ActorService.ListActors:input_type -> filmoteka.v1.ListActorsRequest
+	5,  // 7: filmoteka.v1.ActorService.CreateActor:input_type -> filmoteka.v1.CreateActorRequest
+	6,  // 8: filmoteka.v1.ActorService.UpdateActor:input_type -> filmoteka.v1.UpdateActorRequest
+	7,  // 9: filmoteka.v1.ActorService.DeleteActor:input_type -> filmoteka.v1.DeleteActorRequest
+	1,  // 10: filmoteka.v1.ActorService.GetActor:output_type -> filmoteka.v1.Actor
+	1,  // 11: filmoteka.v1.ActorService.ListActors:output_type -> filmoteka.v1.Actor
+	1,  // 12: filmoteka.v1.ActorService.CreateActor:output_type -> filmoteka.v1.Actor
+	1,  // 13: filmoteka.v1.ActorService.UpdateActor:output_type -> filmoteka.v1.Actor
+	8,  // 14: filmoteka.v1.ActorService.DeleteActor:output_type -> filmoteka.v1.DeleteActorResponse
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_filmoteka_v1_actors_proto_init() }
+func file_filmoteka_v1_actors_proto_init() {
+	if File_filmoteka_v1_actors_proto != nil {
+		return
+	}
+	file_filmoteka_v1_actors_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_filmoteka_v1_actors_proto_rawDesc), len(file_filmoteka_v1_actors_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_filmoteka_v1_actors_proto_goTypes,
+		DependencyIndexes: file_filmoteka_v1_actors_proto_depIdxs,
+		EnumInfos:         file_filmoteka_v1_actors_proto_enumTypes,
+		MessageInfos:      file_filmoteka_v1_actors_proto_msgTypes,
+	}.Build()
+	File_filmoteka_v1_actors_proto = out.File
+	file_filmoteka_v1_actors_proto_goTypes = nil
+	file_filmoteka_v1_actors_proto_depIdxs = nil
+}
@@ -0,0 +1,283 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: filmoteka/v1/actors.proto
+
+package filmotekav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ActorService_GetActor_FullMethodName    = "/filmoteka.v1.ActorService/GetActor"
+	ActorService_ListActors_FullMethodName  = "/filmoteka.v1.ActorService/ListActors"
+	ActorService_CreateActor_FullMethodName = "/filmoteka.v1.ActorService/CreateActor"
+	ActorService_UpdateActor_FullMethodName = "/filmoteka.v1.ActorService/UpdateActor"
+	ActorService_DeleteActor_FullMethodName = "/filmoteka.v1.ActorService/DeleteActor"
+)
+
+// ActorServiceClient is the client API for ActorService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ActorService reads actors for any authenticated user, writes are allowed
+// only for admins like in REST API.
+type ActorServiceClient interface {
+	GetActor(ctx context.Context, in *GetActorRequest, opts ...grpc.CallOption) (*Actor, error)
+	ListActors(ctx context.Context, in *ListActorsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Actor], error)
+	CreateActor(ctx context.Context, in *CreateActorRequest, opts ...grpc.CallOption) (*Actor, error)
+	UpdateActor(ctx context.Context, in *UpdateActorRequest, opts ...grpc.CallOption) (*Actor, error)
+	DeleteActor(ctx context.Context, in *DeleteActorRequest, opts ...grpc.CallOption) (*DeleteActorResponse, error)
+}
+
+type actorServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewActorServiceClient(cc grpc.ClientConnInterface) ActorServiceClient {
+	return &actorServiceClient{cc}
+}
+
+func (c *actorServiceClient) GetActor(ctx context.Context, in *GetActorRequest, opts ...grpc.CallOption) (*Actor, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Actor)
+	err := c.cc.Invoke(ctx, ActorService_GetActor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *actorServiceClient) ListActors(ctx context.Context, in *ListActorsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Actor], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ActorService_ServiceDesc.Streams[0], ActorService_ListActors_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListActorsRequest, Actor]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ActorService_ListActorsClient = grpc.ServerStreamingClient[Actor]
+
+func (c *actorServiceClient) CreateActor(ctx context.Context, in *CreateActorRequest, opts ...grpc.CallOption) (*Actor, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Actor)
+	err := c.cc.Invoke(ctx, ActorService_CreateActor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *actorServiceClient) UpdateActor(ctx context.Context, in *UpdateActorRequest, opts ...grpc.CallOption) (*Actor, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Actor)
+	err := c.cc.Invoke(ctx, ActorService_UpdateActor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *actorServiceClient) DeleteActor(ctx context.Context, in *DeleteActorRequest, opts ...grpc.CallOption) (*DeleteActorResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteActorResponse)
+	err := c.cc.Invoke(ctx, ActorService_DeleteActor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ActorServiceServer is the server API for ActorService service.
+// All implementations must embed UnimplementedActorServiceServer
+// for forward compatibility.
+//
+// ActorService reads actors for any authenticated user, writes are allowed
+// only for admins like in REST API.
+type ActorServiceServer interface {
+	GetActor(context.Context, *GetActorRequest) (*Actor, error)
+	ListActors(*ListActorsRequest, grpc.ServerStreamingServer[Actor]) error
+	CreateActor(context.Context, *CreateActorRequest) (*Actor, error)
+	UpdateActor(context.Context, *UpdateActorRequest) (*Actor, error)
+	DeleteActor(context.Context, *DeleteActorRequest) (*DeleteActorResponse, error)
+	mustEmbedUnimplementedActorServiceServer()
+}
+
+// UnimplementedActorServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedActorServiceServer struct{}
+
+func (UnimplementedActorServiceServer) GetActor(context.Context, *GetActorRequest) (*Actor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActor not implemented")
+}
+func (UnimplementedActorServiceServer) ListActors(*ListActorsRequest, grpc.ServerStreamingServer[Actor]) error {
+	return status.Errorf(codes.Unimplemented, "method ListActors not implemented")
+}
+func (UnimplementedActorServiceServer) CreateActor(context.Context, *CreateActorRequest) (*Actor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateActor not implemented")
+}
+func (UnimplementedActorServiceServer) UpdateActor(context.Context, *UpdateActorRequest) (*Actor, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateActor not implemented")
+}
+func (UnimplementedActorServiceServer) DeleteActor(context.Context, *DeleteActorRequest) (*DeleteActorResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteActor not implemented")
+}
+func (UnimplementedActorServiceServer) mustEmbedUnimplementedActorServiceServer() {}
+func (UnimplementedActorServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeActorServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ActorServiceServer will
+// result in compilation errors.
+type UnsafeActorServiceServer interface {
+	mustEmbedUnimplementedActorServiceServer()
+}
+
+func RegisterActorServiceServer(s grpc.ServiceRegistrar, srv ActorServiceServer) {
+	// If the following call pancis, it indicates UnimplementedActorServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ActorService_ServiceDesc, srv)
+}
+
+func _ActorService_GetActor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActorServiceServer).GetActor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ActorService_GetActor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActorServiceServer).GetActor(ctx, req.(*GetActorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActorService_ListActors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListActorsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ActorServiceServer).ListActors(m, &grpc.GenericServerStream[ListActorsRequest, Actor]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ActorService_ListActorsServer = grpc.ServerStreamingServer[Actor]
+
+func _ActorService_CreateActor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateActorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActorServiceServer).CreateActor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ActorService_CreateActor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActorServiceServer).CreateActor(ctx, req.(*CreateActorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActorService_UpdateActor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateActorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActorServiceServer).UpdateActor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ActorService_UpdateActor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActorServiceServer).UpdateActor(ctx, req.(*UpdateActorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActorService_DeleteActor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteActorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActorServiceServer).DeleteActor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ActorService_DeleteActor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActorServiceServer).DeleteActor(ctx, req.(*DeleteActorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ActorService_ServiceDesc is the grpc.ServiceDesc for ActorService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ActorService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "filmoteka.v1.ActorService",
+	HandlerType: (*ActorServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetActor",
+			Handler:    _ActorService_GetActor_Handler,
+		},
+		{
+			MethodName: "CreateActor",
+			Handler:    _ActorService_CreateActor_Handler,
+		},
+		{
+			MethodName: "UpdateActor",
+			Handler:    _ActorService_UpdateActor_Handler,
+		},
+		{
+			MethodName: "DeleteActor",
+			Handler:    _ActorService_DeleteActor_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListActors",
+			Handler:       _ActorService_ListActors_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "filmoteka/v1/actors.proto",
+}
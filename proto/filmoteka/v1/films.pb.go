@@ -0,0 +1,926 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: filmoteka/v1/films.proto
+
+package filmotekav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FilmSort int32
+
+const (
+	// Best rated first.
+	FilmSort_FILM_SORT_UNSPECIFIED FilmSort = 0
+	FilmSort_FILM_SORT_NAME        FilmSort = 1
+	FilmSort_FILM_SORT_DATE        FilmSort = 2
+)
+
+// Enum value maps for FilmSort.
+var (
+	FilmSort_name = map[int32]string{
+		0: "FILM_SORT_UNSPECIFIED",
+		1: "FILM_SORT_NAME",
+		2: "FILM_SORT_DATE",
+	}
+	FilmSort_value = map[string]int32{
+		"FILM_SORT_UNSPECIFIED": 0,
+		"FILM_SORT_NAME":        1,
+		"FILM_SORT_DATE":        2,
+	}
+)
+
+func (x FilmSort) Enum() *FilmSort {
+	p := new(FilmSort)
+	*p = x
+	return p
+}
+
+func (x FilmSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FilmSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_filmoteka_v1_films_proto_enumTypes[0].Descriptor()
+}
+
+func (FilmSort) Type() protoreflect.EnumType {
+	return &file_filmoteka_v1_films_proto_enumTypes[0]
+}
+
+func (x FilmSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FilmSort.Descriptor instead.
+func (FilmSort) EnumDescriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{0}
+}
+
+type FilmFilterField int32
+
+const (
+	FilmFilterField_FILM_FILTER_FIELD_UNSPECIFIED FilmFilterField = 0
+	FilmFilterField_FILM_FILTER_FIELD_NAME        FilmFilterField = 1
+	FilmFilterField_FILM_FILTER_FIELD_DESCRIPTION FilmFilterField = 2
+)
+
+// Enum value maps for FilmFilterField.
+var (
+	FilmFilterField_name = map[int32]string{
+		0: "FILM_FILTER_FIELD_UNSPECIFIED",
+		1: "FILM_FILTER_FIELD_NAME",
+		2: "FILM_FILTER_FIELD_DESCRIPTION",
+	}
+	FilmFilterField_value = map[string]int32{
+		"FILM_FILTER_FIELD_UNSPECIFIED": 0,
+		"FILM_FILTER_FIELD_NAME":        1,
+		"FILM_FILTER_FIELD_DESCRIPTION": 2,
+	}
+)
+
+func (x FilmFilterField) Enum() *FilmFilterField {
+	p := new(FilmFilterField)
+	*p = x
+	return p
+}
+
+func (x FilmFilterField) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FilmFilterField) Descriptor() protoreflect.EnumDescriptor {
+	return file_filmoteka_v1_films_proto_enumTypes[1].Descriptor()
+}
+
+func (FilmFilterField) Type() protoreflect.EnumType {
+	return &file_filmoteka_v1_films_proto_enumTypes[1]
+}
+
+func (x FilmFilterField) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FilmFilterField.Descriptor instead.
+func (FilmFilterField) EnumDescriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{1}
+}
+
+type Film struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	// Release date in YYYY-MM-DD format.
+	Date string        `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	Rate int32         `protobuf:"varint,5,opt,name=rate,proto3" json:"rate,omitempty"`
+	Cast []*CastMember `protobuf:"bytes,6,rep,name=cast,proto3" json:"cast,omitempty"`
+	// External catalogue, imdb, kinopoisk or tmdb, to the film id in it.
+	ExternalIds   map[string]string `protobuf:"bytes,7,rep,name=external_ids,json=externalIds,proto3" json:"external_ids,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Film) Reset() {
+	*x = Film{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Film) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Film) ProtoMessage() {}
+
+func (x *Film) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Film.ProtoReflect.Descriptor instead.
+func (*Film) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Film) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Film) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Film) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Film) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *Film) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *Film) GetCast() []*CastMember {
+	if x != nil {
+		return x.Cast
+	}
+	return nil
+}
+
+func (x *Film) GetExternalIds() map[string]string {
+	if x != nil {
+		return x.ExternalIds
+	}
+	return nil
+}
+
+type CastMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActorId       int64                  `protobuf:"varint,1,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	ActorName     string                 `protobuf:"bytes,2,opt,name=actor_name,json=actorName,proto3" json:"actor_name,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CastMember) Reset() {
+	*x = CastMember{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CastMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CastMember) ProtoMessage() {}
+
+func (x *CastMember) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CastMember.ProtoReflect.Descriptor instead.
+func (*CastMember) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CastMember) GetActorId() int64 {
+	if x != nil {
+		return x.ActorId
+	}
+	return 0
+}
+
+func (x *CastMember) GetActorName() string {
+	if x != nil {
+		return x.ActorName
+	}
+	return ""
+}
+
+func (x *CastMember) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type CastInput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActorId       int64                  `protobuf:"varint,1,opt,name=actor_id,json=actorId,proto3" json:"actor_id,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CastInput) Reset() {
+	*x = CastInput{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CastInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CastInput) ProtoMessage() {}
+
+func (x *CastInput) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CastInput.ProtoReflect.Descriptor instead.
+func (*CastInput) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CastInput) GetActorId() int64 {
+	if x != nil {
+		return x.ActorId
+	}
+	return 0
+}
+
+func (x *CastInput) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type GetFilmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFilmRequest) Reset() {
+	*x = GetFilmRequest{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFilmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFilmRequest) ProtoMessage() {}
+
+func (x *GetFilmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFilmRequest.ProtoReflect.Descriptor instead.
+func (*GetFilmRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetFilmRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// FilmFilter keeps films with the field containing the value.
+type FilmFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         FilmFilterField        `protobuf:"varint,1,opt,name=field,proto3,enum=filmoteka.v1.FilmFilterField" json:"field,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilmFilter) Reset() {
+	*x = FilmFilter{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilmFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilmFilter) ProtoMessage() {}
+
+func (x *FilmFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilmFilter.ProtoReflect.Descriptor instead.
+func (*FilmFilter) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FilmFilter) GetField() FilmFilterField {
+	if x != nil {
+		return x.Field
+	}
+	return FilmFilterField_FILM_FILTER_FIELD_UNSPECIFIED
+}
+
+func (x *FilmFilter) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type ListFilmsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SortBy        FilmSort               `protobuf:"varint,1,opt,name=sort_by,json=sortBy,proto3,enum=filmoteka.v1.FilmSort" json:"sort_by,omitempty"`
+	Filter        *FilmFilter            `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilmsRequest) Reset() {
+	*x = ListFilmsRequest{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilmsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilmsRequest) ProtoMessage() {}
+
+func (x *ListFilmsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilmsRequest.ProtoReflect.Descriptor instead.
+func (*ListFilmsRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListFilmsRequest) GetSortBy() FilmSort {
+	if x != nil {
+		return x.SortBy
+	}
+	return FilmSort_FILM_SORT_UNSPECIFIED
+}
+
+func (x *ListFilmsRequest) GetFilter() *FilmFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type CreateFilmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Date          string                 `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"`
+	Rate          int32                  `protobuf:"varint,4,opt,name=rate,proto3" json:"rate,omitempty"`
+	Cast          []*CastInput           `protobuf:"bytes,5,rep,name=cast,proto3" json:"cast,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateFilmRequest) Reset() {
+	*x = CreateFilmRequest{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateFilmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateFilmRequest) ProtoMessage() {}
+
+func (x *CreateFilmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateFilmRequest.ProtoReflect.Descriptor instead.
+func (*CreateFilmRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateFilmRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateFilmRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateFilmRequest) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *CreateFilmRequest) GetRate() int32 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *CreateFilmRequest) GetCast() []*CastInput {
+	if x != nil {
+		return x.Cast
+	}
+	return nil
+}
+
+// UpdateFilmRequest changes only present fields, cast is replaced when
+// replace_cast is set.
+type UpdateFilmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          *string                `protobuf:"bytes,2,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Description   *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	Date          *string                `protobuf:"bytes,4,opt,name=date,proto3,oneof" json:"date,omitempty"`
+	Rate          *int32                 `protobuf:"varint,5,opt,name=rate,proto3,oneof" json:"rate,omitempty"`
+	ReplaceCast   bool                   `protobuf:"varint,6,opt,name=replace_cast,json=replaceCast,proto3" json:"replace_cast,omitempty"`
+	Cast          []*CastInput           `protobuf:"bytes,7,rep,name=cast,proto3" json:"cast,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateFilmRequest) Reset() {
+	*x = UpdateFilmRequest{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateFilmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateFilmRequest) ProtoMessage() {}
+
+func (x *UpdateFilmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateFilmRequest.ProtoReflect.Descriptor instead.
+func (*UpdateFilmRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateFilmRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateFilmRequest) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *UpdateFilmRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateFilmRequest) GetDate() string {
+	if x != nil && x.Date != nil {
+		return *x.Date
+	}
+	return ""
+}
+
+func (x *UpdateFilmRequest) GetRate() int32 {
+	if x != nil && x.Rate != nil {
+		return *x.Rate
+	}
+	return 0
+}
+
+func (x *UpdateFilmRequest) GetReplaceCast() bool {
+	if x != nil {
+		return x.ReplaceCast
+	}
+	return false
+}
+
+func (x *UpdateFilmRequest) GetCast() []*CastInput {
+	if x != nil {
+		return x.Cast
+	}
+	return nil
+}
+
+type DeleteFilmRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFilmRequest) Reset() {
+	*x = DeleteFilmRequest{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFilmRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFilmRequest) ProtoMessage() {}
+
+func (x *DeleteFilmRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFilmRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFilmRequest) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteFilmRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteFilmResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFilmResponse) Reset() {
+	*x = DeleteFilmResponse{}
+	mi := &file_filmoteka_v1_films_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFilmResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFilmResponse) ProtoMessage() {}
+
+func (x *DeleteFilmResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_filmoteka_v1_films_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFilmResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFilmResponse) Descriptor() ([]byte, []int) {
+	return file_filmoteka_v1_films_proto_rawDescGZIP(), []int{9}
+}
+
+var File_filmoteka_v1_films_proto protoreflect.FileDescriptor
+
+var file_filmoteka_v1_films_proto_rawDesc = string([]byte{
+	0x0a, 0x18, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x2f, 0x66,
+	0x69, 0x6c, 0x6d, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x66, 0x69, 0x6c, 0x6d,
+	0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x22, 0xaa, 0x02, 0x0a, 0x04, 0x46, 0x69, 0x6c,
+	0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72,
+	0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72, 0x61, 0x74, 0x65, 0x12,
+	0x2c, 0x0a, 0x04, 0x63, 0x61, 0x73, 0x74, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x73,
+	0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x52, 0x04, 0x63, 0x61, 0x73, 0x74, 0x12, 0x46, 0x0a,
+	0x0c, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x07, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e,
+	0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x6d, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
+	0x49, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x65, 0x78, 0x74, 0x65, 0x72, 0x6e,
+	0x61, 0x6c, 0x49, 0x64, 0x73, 0x1a, 0x3e, 0x0a, 0x10, 0x45, 0x78, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x49, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x5a, 0x0a, 0x0a, 0x43, 0x61, 0x73, 0x74, 0x4d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x12, 0x19, 0x0a, 0x08, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x49, 0x64, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c,
+	0x65, 0x22, 0x3a, 0x0a, 0x09, 0x43, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x6f, 0x6c,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x22, 0x20, 0x0a,
+	0x0e, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22,
+	0x57, 0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x33, 0x0a,
+	0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x66,
+	0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x6d,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x52, 0x05, 0x66, 0x69, 0x65,
+	0x6c, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x75, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74,
+	0x46, 0x69, 0x6c, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f, 0x0a, 0x07,
+	0x73, 0x6f, 0x72, 0x74, 0x5f, 0x62, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c,
+	0x6d, 0x53, 0x6f, 0x72, 0x74, 0x52, 0x06, 0x73, 0x6f, 0x72, 0x74, 0x42, 0x79, 0x12, 0x30, 0x0a,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c,
+	0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22,
+	0x9e, 0x01, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x64,
+	0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x72,
+	0x61, 0x74, 0x65, 0x12, 0x2b, 0x0a, 0x04, 0x63, 0x61, 0x73, 0x74, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x17, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x70, 0x75, 0x74, 0x52, 0x04, 0x63, 0x61, 0x73, 0x74,
+	0x22, 0x90, 0x02, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x17, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x25, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x04, 0x64, 0x61, 0x74, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x17, 0x0a, 0x04, 0x72, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x48, 0x03, 0x52,
+	0x04, 0x72, 0x61, 0x74, 0x65, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x65, 0x70, 0x6c,
+	0x61, 0x63, 0x65, 0x5f, 0x63, 0x61, 0x73, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b,
+	0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x43, 0x61, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x04, 0x63,
+	0x61, 0x73, 0x74, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x66, 0x69, 0x6c, 0x6d,
+	0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x73, 0x74, 0x49, 0x6e, 0x70,
+	0x75, 0x74, 0x52, 0x04, 0x63, 0x61, 0x73, 0x74, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6e, 0x61, 0x6d,
+	0x65, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x72,
+	0x61, 0x74, 0x65, 0x22, 0x23, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x69, 0x6c,
+	0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a, 0x4d,
+	0x0a, 0x08, 0x46, 0x69, 0x6c, 0x6d, 0x53, 0x6f, 0x72, 0x74, 0x12, 0x19, 0x0a, 0x15, 0x46, 0x49,
+	0x4c, 0x4d, 0x5f, 0x53, 0x4f, 0x52, 0x54, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
+	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x49, 0x4c, 0x4d, 0x5f, 0x53, 0x4f,
+	0x52, 0x54, 0x5f, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x01, 0x12, 0x12, 0x0a, 0x0e, 0x46, 0x49, 0x4c,
+	0x4d, 0x5f, 0x53, 0x4f, 0x52, 0x54, 0x5f, 0x44, 0x41, 0x54, 0x45, 0x10, 0x02, 0x2a, 0x73, 0x0a,
+	0x0f, 0x46, 0x69, 0x6c, 0x6d, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x12, 0x21, 0x0a, 0x1d, 0x46, 0x49, 0x4c, 0x4d, 0x5f, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f,
+	0x46, 0x49, 0x45, 0x4c, 0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x1a, 0x0a, 0x16, 0x46, 0x49, 0x4c, 0x4d, 0x5f, 0x46, 0x49, 0x4c, 0x54,
+	0x45, 0x52, 0x5f, 0x46, 0x49, 0x45, 0x4c, 0x44, 0x5f, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x01, 0x12,
+	0x21, 0x0a, 0x1d, 0x46, 0x49, 0x4c, 0x4d, 0x5f, 0x46, 0x49, 0x4c, 0x54, 0x45, 0x52, 0x5f, 0x46,
+	0x49, 0x45, 0x4c, 0x44, 0x5f, 0x44, 0x45, 0x53, 0x43, 0x52, 0x49, 0x50, 0x54, 0x49, 0x4f, 0x4e,
+	0x10, 0x02, 0x32, 0xe4, 0x02, 0x0a, 0x0b, 0x46, 0x69, 0x6c, 0x6d, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x3b, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x46, 0x69, 0x6c, 0x6d, 0x12, 0x1c, 0x2e,
+	0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x46, 0x69, 0x6c, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x66, 0x69,
+	0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x6d, 0x12,
+	0x41, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x46, 0x69, 0x6c, 0x6d, 0x73, 0x12, 0x1e, 0x2e, 0x66,
+	0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x46, 0x69, 0x6c, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x66,
+	0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x6d,
+	0x30, 0x01, 0x12, 0x41, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d,
+	0x12, 0x1f, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x12, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x69, 0x6c, 0x6d, 0x12, 0x41, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46,
+	0x69, 0x6c, 0x6d, 0x12, 0x1f, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2e,
+	0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x69, 0x6c, 0x6d, 0x12, 0x4f, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d, 0x12, 0x1f, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74, 0x65,
+	0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x69, 0x6c, 0x6d,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74,
+	0x65, 0x6b, 0x61, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x46, 0x69, 0x6c,
+	0x6d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2a, 0x5a, 0x28, 0x66, 0x69, 0x6c,
+	0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x66, 0x69, 0x6c,
+	0x6d, 0x6f, 0x74, 0x65, 0x6b, 0x61, 0x2f, 0x76, 0x31, 0x3b, 0x66, 0x69, 0x6c, 0x6d, 0x6f, 0x74,
+	0x65, 0x6b, 0x61, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_filmoteka_v1_films_proto_rawDescOnce sync.Once
+	file_filmoteka_v1_films_proto_rawDescData []byte
+)
+
+func file_filmoteka_v1_films_proto_rawDescGZIP() []byte {
+	file_filmoteka_v1_films_proto_rawDescOnce.Do(func() {
+		file_filmoteka_v1_films_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_filmoteka_v1_films_proto_rawDesc), len(file_filmoteka_v1_films_proto_rawDesc)))
+	})
+	return file_filmoteka_v1_films_proto_rawDescData
+}
+
+var file_filmoteka_v1_films_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_filmoteka_v1_films_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_filmoteka_v1_films_proto_goTypes = []any{
+	(FilmSort)(0),              // 0: filmoteka.v1.FilmSort
+	(FilmFilterField)(0),       // 1: filmoteka.v1.FilmFilterField
+	(*Film)(nil),               // 2: filmoteka.v1.Film
+	(*CastMember)(nil),         // 3: filmoteka.v1.CastMember
+	(*CastInput)(nil),          // 4: filmoteka.v1.CastInput
+	(*GetFilmRequest)(nil),     // 5: filmoteka.v1.GetFilmRequest
+	(*FilmFilter)(nil),         // 6: filmoteka.v1.FilmFilter
+	(*ListFilmsRequest)(nil),   // 7: filmoteka.v1.ListFilmsRequest
+	(*CreateFilmRequest)(nil),  // 8: filmoteka.v1.CreateFilmRequest
+	(*UpdateFilmRequest)(nil),  // 9: filmoteka.v1.UpdateFilmRequest
+	(*DeleteFilmRequest)(nil),  // 10: filmoteka.v1.DeleteFilmRequest
+	(*DeleteFilmResponse)(nil), // 11: filmoteka.v1.DeleteFilmResponse
+	nil,                        // 12: filmoteka.v1.Film.ExternalIdsEntry
+}
+var file_filmoteka_v1_films_proto_depIdxs = []int32{
+	3,  // 0: filmoteka.v1.Film.cast:type_name -> filmoteka.v1.CastMember
+	12, // 1: filmoteka.v1.Film.external_ids:type_name -> filmoteka.v1.Film.ExternalIdsEntry
+	1,  // 2: filmoteka.v1.FilmFilter.field:type_name -> filmoteka.v1.FilmFilterField
+	0,  // 3: filmoteka.v1.ListFilmsRequest.sort_by:type_name -> filmoteka.v1.FilmSort
+	6,  // 4: filmoteka.v1.ListFilmsRequest.filter:type_name -> filmoteka.v1.FilmFilter
+	4,  // 5: filmoteka.v1.CreateFilmRequest.cast:type_name -> filmoteka.v1.CastInput
+	4,  // 6: filmoteka.v1.UpdateFilmRequest.cast:type_name -> filmoteka.v1.CastInput
+	5,  // 7: filmoteka.v1.FilmService.GetFilm:input_type -> filmoteka.v1.GetFilmRequest
+	7,  // 8: filmoteka.v1.FilmService.ListFilms:input_type -> filmoteka.v1.ListFilmsRequest
+	8,  // 9: filmoteka.v1.FilmService.CreateFilm:input_type -> filmoteka.v1.CreateFilmRequest
+	9,  // 10: filmoteka.v1.FilmService.UpdateFilm:input_type -> filmoteka.v1.UpdateFilmRequest
+	10, // 11: filmoteka.v1.FilmService.DeleteFilm:input_type -> filmoteka.v1.DeleteFilmRequest
+	2,  // 12: filmoteka.v1.FilmService.GetFilm:output_type -> filmoteka.v1.Film
+	2,  // 13: filmoteka.v1.FilmService.ListFilms:output_type -> filmoteka.v1.Film
+	2,  // 14: filmoteka.v1.FilmService.CreateFilm:output_type -> filmoteka.v1.Film
+	2,  // 15: filmoteka.v1.FilmService.UpdateFilm:output_type -> filmoteka.v1.Film
+	11, // 16: filmoteka.v1.FilmService.DeleteFilm:output_type -> filmoteka.v1.DeleteFilmResponse
+	12, // [12:17] is the sub-list for method output_type
+	7,  // [7:12] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_filmoteka_v1_films_proto_init() }
+func file_filmoteka_v1_films_proto_init() {
+	if File_filmoteka_v1_films_proto != nil {
+		return
+	}
+	file_filmoteka_v1_films_proto_msgTypes[7].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_filmoteka_v1_films_proto_rawDesc), len(file_filmoteka_v1_films_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_filmoteka_v1_films_proto_goTypes,
+		DependencyIndexes: file_filmoteka_v1_films_proto_depIdxs,
+		EnumInfos:         file_filmoteka_v1_films_proto_enumTypes,
+		MessageInfos:      file_filmoteka_v1_films_proto_msgTypes,
+	}.Build()
+	File_filmoteka_v1_films_proto = out.File
+	file_filmoteka_v1_films_proto_goTypes = nil
+	file_filmoteka_v1_films_proto_depIdxs = nil
+}
@@ -0,0 +1,287 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: filmoteka/v1/films.proto
+
+package filmotekav1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	FilmService_GetFilm_FullMethodName    = "/filmoteka.v1.FilmService/GetFilm"
+	FilmService_ListFilms_FullMethodName  = "/filmoteka.v1.FilmService/ListFilms"
+	FilmService_CreateFilm_FullMethodName = "/filmoteka.v1.FilmService/CreateFilm"
+	FilmService_UpdateFilm_FullMethodName = "/filmoteka.v1.FilmService/UpdateFilm"
+	FilmService_DeleteFilm_FullMethodName = "/filmoteka.v1.FilmService/DeleteFilm"
+)
+
+// FilmServiceClient is the client API for FilmService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// FilmService reads films for any authenticated user, writes are allowed
+// only for admins like in REST API.
+type FilmServiceClient interface {
+	GetFilm(ctx context.Context, in *GetFilmRequest, opts ...grpc.CallOption) (*Film, error)
+	// ListFilms streams films one by one, so large catalogues are not
+	// buffered on either side.
+	ListFilms(ctx context.Context, in *ListFilmsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Film], error)
+	CreateFilm(ctx context.Context, in *CreateFilmRequest, opts ...grpc.CallOption) (*Film, error)
+	UpdateFilm(ctx context.Context, in *UpdateFilmRequest, opts ...grpc.CallOption) (*Film, error)
+	DeleteFilm(ctx context.Context, in *DeleteFilmRequest, opts ...grpc.CallOption) (*DeleteFilmResponse, error)
+}
+
+type filmServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFilmServiceClient(cc grpc.ClientConnInterface) FilmServiceClient {
+	return &filmServiceClient{cc}
+}
+
+func (c *filmServiceClient) GetFilm(ctx context.Context, in *GetFilmRequest, opts ...grpc.CallOption) (*Film, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Film)
+	err := c.cc.Invoke(ctx, FilmService_GetFilm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filmServiceClient) ListFilms(ctx context.Context, in *ListFilmsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Film], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &FilmService_ServiceDesc.Streams[0], FilmService_ListFilms_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListFilmsRequest, Film]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilmService_ListFilmsClient = grpc.ServerStreamingClient[Film]
+
+func (c *filmServiceClient) CreateFilm(ctx context.Context, in *CreateFilmRequest, opts ...grpc.CallOption) (*Film, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Film)
+	err := c.cc.Invoke(ctx, FilmService_CreateFilm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filmServiceClient) UpdateFilm(ctx context.Context, in *UpdateFilmRequest, opts ...grpc.CallOption) (*Film, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Film)
+	err := c.cc.Invoke(ctx, FilmService_UpdateFilm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *filmServiceClient) DeleteFilm(ctx context.Context, in *DeleteFilmRequest, opts ...grpc.CallOption) (*DeleteFilmResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteFilmResponse)
+	err := c.cc.Invoke(ctx, FilmService_DeleteFilm_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FilmServiceServer is the server API for FilmService service.
+// All implementations must embed UnimplementedFilmServiceServer
+// for forward compatibility.
+//
+// FilmService reads films for any authenticated user, writes are allowed
+// only for admins like in REST API.
+type FilmServiceServer interface {
+	GetFilm(context.Context, *GetFilmRequest) (*Film, error)
+	// ListFilms streams films one by one, so large catalogues are not
+	// buffered on either side.
+	ListFilms(*ListFilmsRequest, grpc.ServerStreamingServer[Film]) error
+	CreateFilm(context.Context, *CreateFilmRequest) (*Film, error)
+	UpdateFilm(context.Context, *UpdateFilmRequest) (*Film, error)
+	DeleteFilm(context.Context, *DeleteFilmRequest) (*DeleteFilmResponse, error)
+	mustEmbedUnimplementedFilmServiceServer()
+}
+
+// UnimplementedFilmServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedFilmServiceServer struct{}
+
+func (UnimplementedFilmServiceServer) GetFilm(context.Context, *GetFilmRequest) (*Film, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFilm not implemented")
+}
+func (UnimplementedFilmServiceServer) ListFilms(*ListFilmsRequest, grpc.ServerStreamingServer[Film]) error {
+	return status.Errorf(codes.Unimplemented, "method ListFilms not implemented")
+}
+func (UnimplementedFilmServiceServer) CreateFilm(context.Context, *CreateFilmRequest) (*Film, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFilm not implemented")
+}
+func (UnimplementedFilmServiceServer) UpdateFilm(context.Context, *UpdateFilmRequest) (*Film, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFilm not implemented")
+}
+func (UnimplementedFilmServiceServer) DeleteFilm(context.Context, *DeleteFilmRequest) (*DeleteFilmResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFilm not implemented")
+}
+func (UnimplementedFilmServiceServer) mustEmbedUnimplementedFilmServiceServer() {}
+func (UnimplementedFilmServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeFilmServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FilmServiceServer will
+// result in compilation errors.
+type UnsafeFilmServiceServer interface {
+	mustEmbedUnimplementedFilmServiceServer()
+}
+
+func RegisterFilmServiceServer(s grpc.ServiceRegistrar, srv FilmServiceServer) {
+	// If the following call pancis, it indicates UnimplementedFilmServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&FilmService_ServiceDesc, srv)
+}
+
+func _FilmService_GetFilm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFilmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilmServiceServer).GetFilm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilmService_GetFilm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilmServiceServer).GetFilm(ctx, req.(*GetFilmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilmService_ListFilms_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListFilmsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FilmServiceServer).ListFilms(m, &grpc.GenericServerStream[ListFilmsRequest, Film]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type FilmService_ListFilmsServer = grpc.ServerStreamingServer[Film]
+
+func _FilmService_CreateFilm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFilmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilmServiceServer).CreateFilm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilmService_CreateFilm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilmServiceServer).CreateFilm(ctx, req.(*CreateFilmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilmService_UpdateFilm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateFilmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilmServiceServer).UpdateFilm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilmService_UpdateFilm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilmServiceServer).UpdateFilm(ctx, req.(*UpdateFilmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FilmService_DeleteFilm_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFilmRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FilmServiceServer).DeleteFilm(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FilmService_DeleteFilm_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FilmServiceServer).DeleteFilm(ctx, req.(*DeleteFilmRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// FilmService_ServiceDesc is the grpc.ServiceDesc for FilmService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FilmService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "filmoteka.v1.FilmService",
+	HandlerType: (*FilmServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetFilm",
+			Handler:    _FilmService_GetFilm_Handler,
+		},
+		{
+			MethodName: "CreateFilm",
+			Handler:    _FilmService_CreateFilm_Handler,
+		},
+		{
+			MethodName: "UpdateFilm",
+			Handler:    _FilmService_UpdateFilm_Handler,
+		},
+		{
+			MethodName: "DeleteFilm",
+			Handler:    _FilmService_DeleteFilm_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListFilms",
+			Handler:       _FilmService_ListFilms_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "filmoteka/v1/films.proto",
+}
@@ -87,7 +87,7 @@ func TestCreateActors(t *testing.T) {
 			name:     "Client Auth",
 			username: "client",
 			password: "client",
-			code:     401,
+			code:     403,
 		},
 		{
 			name:        "Admin Auth Valid Data",
@@ -186,7 +186,7 @@ func TestUpdateActors(t *testing.T) {
 			actor_id: "1",
 			username: "client",
 			password: "client",
-			code:     401,
+			code:     403,
 		},
 		{
 			name:        "Admin Auth Valid Data No ID",
@@ -304,7 +304,7 @@ func TestDeleteActors(t *testing.T) {
 			actor_id: "1",
 			username: "client",
 			password: "client",
-			code:     401,
+			code:     403,
 		},
 		{
 			name:     "Admin Auth Valid Data No ID",
@@ -324,7 +324,7 @@ func TestDeleteActors(t *testing.T) {
 			username: "admin",
 			password: "admin",
 			wrong_id: "45",
-			code:     400,
+			code:     404,
 		},
 	}
 	for _, tc := range testCases {
@@ -392,3 +392,155 @@ func TestDeleteActors(t *testing.T) {
 		})
 	}
 }
+
+func createTestActor(t *testing.T, name string) int64 {
+	body, _ := json.Marshal(map[string]string{
+		"name":  name,
+		"sex":   "female",
+		"birth": "1990-01-01",
+	})
+	request, _ := http.NewRequest("POST", "/actors", bytes.NewBuffer(body))
+	request.SetBasicAuth("admin", "admin")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	resp := api_models.ActorResponse{}
+	err := json.Unmarshal(writer.Body.Bytes(), &resp)
+	if err != nil || resp.Actor == nil {
+		t.Fatalf("cant create actor %s: %s", name, writer.Body.String())
+	}
+	return resp.Actor.ID
+}
+
+func createTestFilm(t *testing.T, name string, date string, actors []int64) {
+	req := api_models.CreateFilmRequest{
+		Name:  name,
+		Date:  date,
+		Rate:  5,
+		Roles: map[int]string{},
+	}
+	for _, id := range actors {
+		req.Actors = append(req.Actors, int(id))
+		req.Roles[int(id)] = "Role of " + strconv.FormatInt(id, 10)
+	}
+	body, _ := json.Marshal(req)
+	request, _ := http.NewRequest("POST", "/films", bytes.NewBuffer(body))
+	request.SetBasicAuth("admin", "admin")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	if writer.Code != 200 {
+		t.Fatalf("cant create film %s: %s", name, writer.Body.String())
+	}
+}
+
+func TestActorGraph(t *testing.T) {
+	first := createTestActor(t, "GraphActor1")
+	second := createTestActor(t, "GraphActor2")
+	third := createTestActor(t, "GraphActor3")
+	createTestFilm(t, "GraphFilm2", "2010-05-05", []int64{second, third})
+	createTestFilm(t, "GraphFilm1", "2005-05-05", []int64{first, second})
+
+	testCases := []struct {
+		name     string
+		url      string
+		username string
+		password string
+		code     int
+	}{
+		{
+			name: "Filmography No Auth",
+			url:  "/actors/" + strconv.FormatInt(second, 10) + "/filmography",
+			code: 401,
+		},
+		{
+			name:     "Filmography",
+			url:      "/actors/" + strconv.FormatInt(second, 10) + "/filmography",
+			username: "client",
+			password: "client",
+			code:     200,
+		},
+		{
+			name:     "Filmography Unknown Actor",
+			url:      "/actors/100500/filmography",
+			username: "client",
+			password: "client",
+			code:     404,
+		},
+		{
+			name:     "Costars",
+			url:      "/actors/" + strconv.FormatInt(second, 10) + "/costars",
+			username: "client",
+			password: "client",
+			code:     200,
+		},
+		{
+			name:     "Path",
+			url:      "/actors/path?from=" + strconv.FormatInt(first, 10) + "&to=" + strconv.FormatInt(third, 10),
+			username: "client",
+			password: "client",
+			code:     200,
+		},
+		{
+			name:     "Path Invalide Params",
+			url:      "/actors/path?from=first",
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", tc.url, bytes.NewBufferString(""))
+			if tc.username != "" && tc.password != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.code, writer.Code)
+		})
+	}
+
+	t.Run("Filmography Order", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/actors/"+strconv.FormatInt(second, 10)+"/filmography", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		resp := api_models.FilmographyResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &resp)
+		if assert.Len(t, resp.Films, 2) && assert.Len(t, resp.Years, 2) {
+			assert.Equal(t, "GraphFilm1", resp.Films[0].Name)
+			assert.Equal(t, "Role of "+strconv.FormatInt(second, 10), resp.Films[0].Role)
+			assert.Equal(t, 2005, resp.Years[0].Year)
+		}
+	})
+
+	t.Run("Costars Shared Films", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/actors/"+strconv.FormatInt(second, 10)+"/costars", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		resp := api_models.CostarsResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &resp)
+		if assert.Len(t, resp.Costars, 2) {
+			assert.Equal(t, 1, resp.Costars[0].SharedFilms)
+		}
+	})
+
+	t.Run("Path Degrees", func(t *testing.T) {
+		url := "/actors/path?from=" + strconv.FormatInt(first, 10) + "&to=" + strconv.FormatInt(third, 10)
+		request, _ := http.NewRequest("GET", url, nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		resp := api_models.CostarPathResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &resp)
+		assert.Equal(t, 2, resp.Degrees)
+		if assert.Len(t, resp.Path, 3) {
+			assert.Equal(t, "GraphFilm1", resp.Path[0].FilmName)
+			assert.Equal(t, third, resp.Path[2].ActorID)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"filmoteka/api"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySuggester(t *testing.T) {
+	loads := 0
+	suggester := db.NewMemorySuggester(func() ([]*db.Suggestion, error) {
+		loads++
+		return []*db.Suggestion{
+			{Type: db.SearchActor, ID: 1, Name: "Киану Ривз"},
+			{Type: db.SearchActor, ID: 2, Name: "Кира Найтли"},
+			{Type: db.SearchFilm, ID: 1, Name: "Кин-дза-дза!"},
+			{Type: db.SearchFilm, ID: 2, Name: "Матрица"},
+		}, nil
+	}, time.Minute)
+
+	testCases := []struct {
+		name        string
+		query       string
+		suggestType string
+		expected    []string
+	}{
+		{
+			name:     "Typo",
+			query:    "Кеану Ривс",
+			expected: []string{"Киану Ривз"},
+		},
+		{
+			name:     "Prefix First",
+			query:    "ки",
+			expected: []string{"Кин-дза-дза!", "Киану Ривз", "Кира Найтли"},
+		},
+		{
+			name:        "Type Filter",
+			query:       "ки",
+			suggestType: db.SearchActor,
+			expected:    []string{"Киану Ривз", "Кира Найтли"},
+		},
+		{
+			name:  "No Match",
+			query: "Терминатор",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			suggestions, err := suggester.Suggest(context.Background(), tc.query, tc.suggestType, 10)
+			assert.NoError(t, err)
+			names := make([]string, 0)
+			for _, suggestion := range suggestions {
+				names = append(names, suggestion.Name)
+			}
+			assert.ElementsMatch(t, tc.expected, names)
+		})
+	}
+	assert.Equal(t, 1, loads)
+}
+
+func TestAutocomplete(t *testing.T) {
+	createTestActor(t, "Киану Ривз")
+
+	testCases := []struct {
+		name     string
+		query    url.Values
+		username string
+		password string
+		code     int
+		result   string
+	}{
+		{
+			name:  "No Auth",
+			query: url.Values{"q": {"Кеану"}},
+			code:  401,
+		},
+		{
+			name:     "Invalide Limit",
+			query:    url.Values{"q": {"Кеану"}, "limit": {"1000"}},
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+		{
+			name:     "Typo",
+			query:    url.Values{"q": {"Кеану Ривс"}, "type": {"actor"}},
+			username: "client",
+			password: "client",
+			code:     200,
+			result:   "Киану Ривз",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", "/autocomplete?"+tc.query.Encode(), nil)
+			if tc.username != "" && tc.password != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.code, writer.Code)
+
+			if writer.Code == 200 {
+				resp := api_models.AutocompleteResponse{}
+				json.Unmarshal(writer.Body.Bytes(), &resp)
+				if assert.NotEmpty(t, resp.Suggestions) {
+					assert.Equal(t, tc.result, resp.Suggestions[0].Name)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryAutocomplete(t *testing.T) {
+	createTestActor(t, "Кира Найтли")
+
+	// Memory backend does not need pg_trgm.
+	cfg := *testConfig
+	cfg.Autocomplete.Backend = "memory"
+	memory := api.StartAPI(testDB, &cfg)
+
+	request, _ := http.NewRequest("GET", "/autocomplete?"+url.Values{"q": {"Кира Найтлии"}, "type": {"actor"}}.Encode(), nil)
+	request.SetBasicAuth("client", "client")
+	writer := httptest.NewRecorder()
+	memory.ServeHTTP(writer, request)
+	assert.Equal(t, 200, writer.Code)
+
+	resp := api_models.AutocompleteResponse{}
+	json.Unmarshal(writer.Body.Bytes(), &resp)
+	if assert.NotEmpty(t, resp.Suggestions) {
+		assert.Equal(t, "Кира Найтли", resp.Suggestions[0].Name)
+	}
+}
@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	api_models "filmoteka/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkActors(t *testing.T) {
+	existing := createTestActor(t, "BulkActor")
+
+	testCases := []struct {
+		name     string
+		body     string
+		username string
+		password string
+		code     int
+		statuses []int
+	}{
+		{
+			name:     "Client",
+			body:     `{"operations":[{"op":"delete","id":1}]}`,
+			username: "client",
+			password: "client",
+			code:     403,
+		},
+		{
+			name:     "Empty",
+			body:     `{"operations":[]}`,
+			username: "admin",
+			password: "admin",
+			code:     400,
+		},
+		{
+			name:     "Unknown Operation",
+			body:     `{"operations":[{"op":"merge","id":1}]}`,
+			username: "admin",
+			password: "admin",
+			code:     400,
+		},
+		{
+			name: "Best Effort",
+			body: `{"mode":"best_effort","operations":[` +
+				`{"op":"create","data":{"name":"BulkCreated","sex":"male","birth":"1980-02-02"}},` +
+				`{"op":"create","data":{"name":"BulkInvalid","sex":"male","birth":"02.02.1980"}},` +
+				`{"op":"update","id":` + strconv.FormatInt(existing, 10) + `,"data":{"name":"BulkUpdated"}},` +
+				`{"op":"delete","id":100500}]}`,
+			username: "admin",
+			password: "admin",
+			code:     207,
+			statuses: []int{200, 400, 200, 404},
+		},
+		{
+			name: "Transaction Rollback",
+			body: `{"operations":[` +
+				`{"op":"create","data":{"name":"BulkRolledBack","sex":"male","birth":"1980-02-02"}},` +
+				`{"op":"delete","id":100500},` +
+				`{"op":"create","data":{"name":"BulkSkipped","sex":"male","birth":"1980-02-02"}}]}`,
+			username: "admin",
+			password: "admin",
+			code:     207,
+			statuses: []int{424, 404, 424},
+		},
+		{
+			name: "Transaction",
+			body: `{"mode":"transaction","operations":[` +
+				`{"op":"create","data":{"name":"BulkCommitted","sex":"female","birth":"1985-03-03"}},` +
+				`{"op":"delete","id":` + strconv.FormatInt(existing, 10) + `}]}`,
+			username: "admin",
+			password: "admin",
+			code:     200,
+			statuses: []int{200, 200},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", "/actors/bulk", bytes.NewBufferString(tc.body))
+			request.SetBasicAuth(tc.username, tc.password)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.code, writer.Code)
+			if tc.statuses == nil {
+				return
+			}
+
+			resp := api_models.BulkResponse{}
+			err := json.Unmarshal(writer.Body.Bytes(), &resp)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.code == 200, resp.Success)
+			if assert.Len(t, resp.Results, len(tc.statuses)) {
+				for i, result := range resp.Results {
+					assert.Equal(t, i, result.Index)
+					assert.Equal(t, tc.statuses[i], result.Status)
+					if result.Status == 200 {
+						assert.Nil(t, result.Error)
+					} else {
+						assert.NotNil(t, result.Error)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBulkFilms(t *testing.T) {
+	actor := createTestActor(t, "BulkFilmActor")
+
+	body := `{"mode":"best_effort","operations":[` +
+		`{"op":"create","data":{"name":"BulkFilm","date":"2001-01-01","rate":7,"actors":[` + strconv.FormatInt(actor, 10) + `]}},` +
+		`{"op":"create","data":{"name":"BulkFilmInvalid","date":"2001-01-01","rate":11}},` +
+		`{"op":"update","id":100500,"data":{"rate":3}}]}`
+	request, _ := http.NewRequest("POST", "/films/bulk", bytes.NewBufferString(body))
+	request.SetBasicAuth("admin", "admin")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	assert.Equal(t, 207, writer.Code)
+	resp := api_models.BulkResponse{}
+	err := json.Unmarshal(writer.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	if assert.Len(t, resp.Results, 3) {
+		assert.Equal(t, 200, resp.Results[0].Status)
+		if assert.NotNil(t, resp.Results[0].Film) {
+			assert.Equal(t, "BulkFilm", resp.Results[0].Film.Name)
+			assert.Len(t, resp.Results[0].Film.Actors, 1)
+		}
+		assert.Equal(t, 400, resp.Results[1].Status)
+		if assert.NotNil(t, resp.Results[1].Error) {
+			assert.Equal(t, "validation_failed", resp.Results[1].Error.Code)
+		}
+		assert.Equal(t, 404, resp.Results[2].Status)
+		if assert.NotNil(t, resp.Results[2].Error) {
+			assert.Equal(t, "film_not_found", resp.Results[2].Error.Code)
+		}
+	}
+}
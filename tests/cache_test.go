@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func cachedGet(url string, username string) *httptest.ResponseRecorder {
+	request, _ := http.NewRequest("GET", url, nil)
+	request.SetBasicAuth(username, username)
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	return writer
+}
+
+func TestResponseCache(t *testing.T) {
+	first := cachedGet("/v2/films?limit=50&offset=0", "client")
+	assert.Equal(t, 200, first.Code)
+	assert.Equal(t, "MISS", first.Header().Get("X-Cache"))
+	assert.Equal(t, "private, no-cache", first.Header().Get("Cache-Control"))
+
+	t.Run("Hit", func(t *testing.T) {
+		writer := cachedGet("/v2/films?limit=50&offset=0", "client")
+		assert.Equal(t, 200, writer.Code)
+		assert.Equal(t, "HIT", writer.Header().Get("X-Cache"))
+		assert.Equal(t, first.Body.String(), writer.Body.String())
+		assert.Equal(t, first.Header().Get("Content-Type"), writer.Header().Get("Content-Type"))
+	})
+
+	t.Run("Normalized Query", func(t *testing.T) {
+		writer := cachedGet("/v2/films?offset=0&limit=50", "client")
+		assert.Equal(t, "HIT", writer.Header().Get("X-Cache"))
+	})
+
+	t.Run("Keyed By Role", func(t *testing.T) {
+		writer := cachedGet("/v2/films?limit=50&offset=0", "admin")
+		assert.Equal(t, 200, writer.Code)
+		assert.Equal(t, "MISS", writer.Header().Get("X-Cache"))
+	})
+
+	t.Run("Errors Are Not Cached", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			writer := cachedGet("/v2/films/999999", "client")
+			assert.Equal(t, 404, writer.Code)
+			assert.Empty(t, writer.Header().Get("X-Cache"))
+			assert.Empty(t, writer.Header().Get("Cache-Control"))
+		}
+	})
+
+	t.Run("Invalidated By Writes", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{
+			"name":        "CachedFilm",
+			"description": "Film created after the list was cached",
+			"date":        "2001-01-01",
+			"rate":        5,
+			"actors":      []int{},
+		})
+		request, _ := http.NewRequest("POST", "/v2/films", bytes.NewBuffer(body))
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 200, writer.Code)
+
+		// The write bumped the version keys are built from.
+		version, err := db.GetCatalogueVersion(testDB)
+		assert.NoError(t, err)
+		lastEventID, _ := db.LastEventID(testDB)
+		assert.Equal(t, lastEventID, version)
+
+		writer = cachedGet("/v2/films?limit=50&offset=0", "client")
+		assert.Equal(t, "MISS", writer.Header().Get("X-Cache"))
+		res := api_models.FilmsResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &res)
+		names := make([]string, 0, len(res.Films))
+		for _, film := range res.Films {
+			names = append(names, film.Name)
+		}
+		assert.Contains(t, names, "CachedFilm")
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		writer := cachedGet("/debug/vars", "client")
+		assert.Equal(t, 403, writer.Code)
+
+		writer = cachedGet("/debug/vars", "admin")
+		assert.Equal(t, 200, writer.Code)
+		vars := map[string]json.RawMessage{}
+		json.Unmarshal(writer.Body.Bytes(), &vars)
+		metrics := map[string]int{}
+		json.Unmarshal(vars["response_cache"], &metrics)
+		assert.Greater(t, metrics["hits"], 0)
+		assert.Greater(t, metrics["misses"], 0)
+	})
+}
@@ -0,0 +1,221 @@
+package tests
+
+import (
+	"context"
+	"filmoteka/client"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(t *testing.T, handler http.Handler, username string, opts ...client.Option) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]client.Option{
+		client.WithBasicAuth(username, username),
+		client.WithRetry(3, time.Millisecond, 10*time.Millisecond),
+	}, opts...)
+	c, err := client.New(server.URL, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestClientFilms(t *testing.T) {
+	ctx := context.Background()
+	admin := newClient(t, router, "admin")
+
+	actor, err := admin.Actors.Create(ctx, &client.CreateActorRequest{Name: "ClientActor", Sex: "female", Birth: "1980-08-08"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	film, err := admin.Films.Create(ctx, &client.CreateFilmRequest{
+		Name: "ClientFilm", Description: "From SDK", Date: "2012-12-12", Rate: 4,
+		Actors: []int{int(actor.ID)}, Roles: map[int]string{int(actor.ID): "Narrator"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "ClientFilm", film.Name)
+	assert.Equal(t, "2012-12-12", film.Date.Format(client.DateLayout))
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := newClient(t, router, "client").Films.Get(ctx, film.ID)
+		if assert.NoError(t, err) && assert.Len(t, got.Actors, 1) {
+			assert.Equal(t, "ClientActor", got.Actors[0].Name)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		updated, err := admin.Films.Update(ctx, film.ID, &client.UpdateFilmRequest{
+			Name: "ClientFilm", Description: "Updated", Date: "2012-12-12", Rate: 5,
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, 5, updated.Rate)
+			assert.Equal(t, "Updated", updated.Description)
+		}
+
+		renamed, err := admin.Actors.Update(ctx, actor.ID, &client.UpdateActorRequest{Name: "ClientActress"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "ClientActress", renamed.Name)
+			assert.Equal(t, "female", renamed.Sex)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := admin.Films.Get(ctx, 100500)
+		assert.True(t, client.IsNotFound(err))
+		var apiErr *client.Error
+		if assert.ErrorAs(t, err, &apiErr) {
+			assert.Equal(t, "film_not_found", apiErr.Code)
+		}
+
+		_, err = newClient(t, router, "client").Films.Create(ctx, &client.CreateFilmRequest{Name: "Nope", Date: "2001-01-01"})
+		assert.True(t, client.IsForbidden(err))
+
+		_, err = newClient(t, router, "nobody").Films.List(ctx, nil)
+		assert.True(t, client.IsUnauthorized(err))
+
+		_, err = admin.Films.Create(ctx, &client.CreateFilmRequest{Name: "", Date: "2001-01-01"})
+		if assert.ErrorAs(t, err, &apiErr) && assert.NotEmpty(t, apiErr.Fields) {
+			assert.Equal(t, "name", apiErr.Fields[0].Field)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, admin.Films.Delete(ctx, film.ID))
+		assert.NoError(t, admin.Actors.Delete(ctx, actor.ID))
+		_, err := admin.Actors.Get(ctx, actor.ID)
+		assert.True(t, client.IsNotFound(err))
+	})
+}
+
+func TestClientIterators(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t, router, "client")
+
+	all, err := c.Films.List(ctx, &client.FilmListOptions{SortBy: client.SortByName})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	pages := 0
+	counting := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		router.ServeHTTP(w, r)
+	})
+	it := newClient(t, counting, "client").Films.Iter(ctx, &client.FilmListOptions{SortBy: client.SortByName, Limit: 1})
+	names := make([]string, 0)
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+	assert.NoError(t, it.Err())
+	expected := make([]string, 0, len(all))
+	for _, film := range all {
+		expected = append(expected, film.Name)
+	}
+	assert.Equal(t, expected, names)
+	assert.Equal(t, len(all)+1, pages)
+
+	actors := c.Actors.Iter(ctx, &client.ActorListOptions{Limit: 1})
+	count := 0
+	for actors.Next() {
+		count++
+	}
+	assert.NoError(t, actors.Err())
+	assert.GreaterOrEqual(t, count, 2)
+
+	_, err = c.Films.List(ctx, &client.FilmListOptions{Limit: 5000})
+	assert.Equal(t, http.StatusBadRequest, client.StatusCode(err))
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts atomic.Int32
+	keys := make([]string, 0)
+	flaky := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if attempts.Add(1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		router.ServeHTTP(w, r)
+	})
+
+	c := newClient(t, flaky, "admin")
+	actor, err := c.Actors.Create(ctx, &client.CreateActorRequest{Name: "RetriedActor", Sex: "male", Birth: "1990-09-09"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "RetriedActor", actor.Name)
+		c.Actors.Delete(ctx, actor.ID)
+	}
+	if assert.GreaterOrEqual(t, len(keys), 3) {
+		assert.NotEmpty(t, keys[0])
+		assert.Equal(t, keys[0], keys[1])
+		assert.Equal(t, keys[0], keys[2])
+	}
+
+	attempts.Store(0)
+	once := newClient(t, flaky, "admin", client.WithRetry(1, time.Millisecond, time.Millisecond))
+	_, err = once.Films.List(ctx, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = once.Films.List(cancelled, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestClientMatchesSpec fails when fields of client models differ from
+// definitions of the served OpenAPI document.
+func TestClientMatchesSpec(t *testing.T) {
+	spec := loadSpec(t)
+
+	models := map[string]interface{}{
+		"api_models.Film":         client.Film{},
+		"api_models.FilmSummary":  client.FilmSummary{},
+		"api_models.Actor":        client.Actor{},
+		"api_models.ActorSummary": client.ActorSummary{},
+		"errs.FieldError":         client.FieldError{},
+	}
+	for name, model := range models {
+		definition, ok := spec.Definitions[name]
+		if !assert.True(t, ok, name) {
+			continue
+		}
+		properties := make([]string, 0, len(definition.Properties))
+		for property := range definition.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+		assert.Equal(t, properties, jsonFields(model), name)
+	}
+
+	// Deprecated success and error members are not read by the client.
+	problem := spec.Definitions["api.ErrorResponse"]
+	for _, field := range jsonFields(client.Error{}) {
+		assert.Contains(t, problem.Properties, field)
+	}
+}
+
+func jsonFields(model interface{}) []string {
+	fields := make([]string, 0)
+	modelType := reflect.TypeOf(model)
+	for i := 0; i < modelType.NumField(); i++ {
+		name := strings.SplitN(modelType.Field(i).Tag.Get("json"), ",", 2)[0]
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"filmoteka/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// xmlActorResponse reads actor responses sent as XML.
+type xmlActorResponse struct {
+	XMLName xml.Name `xml:"response"`
+	Success bool     `xml:"success"`
+	Actor   struct {
+		ID   int64  `xml:"id"`
+		Name string `xml:"name"`
+		Sex  string `xml:"sex"`
+	} `xml:"actor"`
+}
+
+func TestContentNegotiation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		accept      string
+		status      int
+		contentType string
+	}{
+		{
+			name:        "Default",
+			status:      200,
+			contentType: "application/json",
+		},
+		{
+			name:        "Any",
+			accept:      "*/*",
+			status:      200,
+			contentType: "application/json",
+		},
+		{
+			name:        "XML",
+			accept:      "application/xml",
+			status:      200,
+			contentType: "application/xml",
+		},
+		{
+			name:        "MessagePack",
+			accept:      "application/msgpack",
+			status:      200,
+			contentType: "application/msgpack",
+		},
+		{
+			name:        "Quality",
+			accept:      "application/json;q=0.5, application/xml",
+			status:      200,
+			contentType: "application/xml",
+		},
+		{
+			name:        "Not Acceptable",
+			accept:      "text/html",
+			status:      406,
+			contentType: "application/problem+json",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", "/v2/actors", nil)
+			request.SetBasicAuth("client", "client")
+			if tc.accept != "" {
+				request.Header.Set("Accept", tc.accept)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.status, writer.Code)
+			assert.Equal(t, tc.contentType, writer.Header().Get("Content-Type"))
+			assert.Contains(t, writer.Header().Values("Vary"), "Accept")
+		})
+	}
+
+	t.Run("XML Problem", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/v2/actors/0", nil)
+		request.SetBasicAuth("client", "client")
+		request.Header.Set("Accept", "application/xml")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 404, writer.Code)
+		assert.Equal(t, "application/problem+xml", writer.Header().Get("Content-Type"))
+
+		problem := struct {
+			XMLName xml.Name `xml:"urn:ietf:rfc:7807 problem"`
+			Status  int      `xml:"status"`
+			Code    string   `xml:"code"`
+		}{}
+		err := xml.Unmarshal(writer.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		assert.Equal(t, 404, problem.Status)
+		assert.Equal(t, "actor_not_found", problem.Code)
+	})
+}
+
+func TestRequestFormats(t *testing.T) {
+	msgpackBody, _ := msgpack.Marshal(map[string]string{
+		"name":  "MsgpackActor",
+		"sex":   "male",
+		"birth": "1990-02-03",
+	})
+	testCases := []struct {
+		name        string
+		contentType string
+		body        []byte
+		status      int
+		code        string
+		actorName   string
+	}{
+		{
+			name:        "XML",
+			contentType: "application/xml",
+			body:        []byte(`<?xml version="1.0"?><request><name>XMLActor</name><sex>female</sex><birth>1990-02-03</birth></request>`),
+			status:      200,
+			actorName:   "XMLActor",
+		},
+		{
+			name:        "MessagePack",
+			contentType: "application/msgpack",
+			body:        msgpackBody,
+			status:      200,
+			actorName:   "MsgpackActor",
+		},
+		{
+			name:        "Invalid XML",
+			contentType: "application/xml",
+			body:        []byte(`<request><name>`),
+			status:      400,
+			code:        "invalid_body",
+		},
+		{
+			name:        "XML Validation",
+			contentType: "application/xml",
+			body:        []byte(`<request><name>XMLActor</name><sex>unknown</sex><birth>1990-02-03</birth></request>`),
+			status:      400,
+			code:        "validation_failed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", "/v2/actors", bytes.NewBuffer(tc.body))
+			request.SetBasicAuth("admin", "admin")
+			request.Header.Set("Content-Type", tc.contentType)
+			request.Header.Set("Accept", "application/xml")
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.status, writer.Code)
+
+			if tc.code != "" {
+				problem := struct {
+					Code string `xml:"code"`
+				}{}
+				xml.Unmarshal(writer.Body.Bytes(), &problem)
+				assert.Equal(t, tc.code, problem.Code)
+				return
+			}
+			res := xmlActorResponse{}
+			err := xml.Unmarshal(writer.Body.Bytes(), &res)
+			assert.NoError(t, err)
+			assert.True(t, res.Success)
+			assert.Equal(t, tc.actorName, res.Actor.Name)
+
+			request, _ = http.NewRequest("GET", "/v2/actors/"+strconv.FormatInt(res.Actor.ID, 10), nil)
+			request.SetBasicAuth("client", "client")
+			request.Header.Set("Accept", "application/msgpack")
+			writer = httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, 200, writer.Code)
+
+			found := map[string]interface{}{}
+			err = msgpack.Unmarshal(writer.Body.Bytes(), &found)
+			assert.NoError(t, err)
+			actor, _ := found["actor"].(map[string]interface{})
+			assert.Equal(t, tc.actorName, actor["name"])
+		})
+	}
+
+	t.Run("JSON Stays Default", func(t *testing.T) {
+		request, _ := http.NewRequest("PUT", "/v2/actors/0", bytes.NewBufferString(`{"name":`))
+		request.SetBasicAuth("admin", "admin")
+		request.Header.Set("Content-Type", "text/plain")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 400, writer.Code)
+
+		problem := api.ErrorResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &problem)
+		assert.Equal(t, "invalid_json", problem.Code)
+	})
+}
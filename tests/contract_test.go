@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	api_models "filmoteka/api/models"
+	"filmoteka/docs"
+	"filmoteka/openapi"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// undocumentedRoutes are served but are not a part of the API.
+var undocumentedRoutes = []string{"GET /swagger/*", "GET /debug/vars", "GET /livez", "GET /readyz"}
+
+// sinceV2Routes are not served by API v1.
+var sinceV2Routes = []string{"GET /users/me"}
+
+// loadSpec reads the document served at /swagger/doc.json.
+func loadSpec(t *testing.T) *openapi.Spec {
+	spec, err := openapi.Parse([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+// routePattern is a chi pattern written like OpenAPI paths.
+func routePattern(pattern string) string {
+	if len(pattern) > 1 {
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	return pattern
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func pathParams(pattern string) []string {
+	params := make([]string, 0)
+	for _, part := range strings.Split(pattern, "/") {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params = append(params, strings.SplitN(part[1:len(part)-1], ":", 2)[0])
+		}
+	}
+	sort.Strings(params)
+	return params
+}
+
+// TestContractRoutes fails when routes of the router and operations of
+// the spec differ or disagree on path params. The spec describes v2, v1
+// and unprefixed routes serve the same paths.
+func TestContractRoutes(t *testing.T) {
+	spec := loadSpec(t)
+
+	routes := map[string][]string{}
+	err := chi.Walk(router, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		operation := method + " " + routePattern(route)
+		for _, skip := range undocumentedRoutes {
+			if operation == skip {
+				return nil
+			}
+		}
+		prefix := ""
+		for _, version := range []string{"/v1", "/v2"} {
+			if strings.HasPrefix(route, version+"/") {
+				prefix = version
+			}
+		}
+		routes[prefix] = append(routes[prefix], method+" "+routePattern(strings.TrimPrefix(route, prefix)))
+		return nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	v1Operations := make([]string, 0)
+	for _, operation := range spec.Operations() {
+		if !contains(sinceV2Routes, operation) {
+			v1Operations = append(v1Operations, operation)
+		}
+	}
+	for prefix, expected := range map[string][]string{"/v2": spec.Operations(), "/v1": v1Operations, "": spec.Operations()} {
+		sort.Strings(routes[prefix])
+		assert.Equal(t, expected, routes[prefix], "routes of %q", prefix)
+	}
+
+	for path, methods := range spec.Paths {
+		for method, operation := range methods {
+			params := make([]string, 0)
+			for _, param := range operation.Parameters {
+				if param.In == "path" {
+					params = append(params, param.Name)
+				}
+			}
+			sort.Strings(params)
+			assert.Equal(t, pathParams(path), params, "path params of %s %s", method, path)
+		}
+	}
+}
+
+type contractRequest struct {
+	method    string
+	operation string
+	url       string
+	body      string
+	username  string
+	status    int
+}
+
+// TestContractResponses sends requests to every kind of endpoint and fails
+// when statuses, query params or response bodies are not documented.
+func TestContractResponses(t *testing.T) {
+	spec := loadSpec(t)
+
+	actorID := createTestActor(t, "ContractActor")
+	costarID := createTestActor(t, "ContractCostar")
+	body := fmt.Sprintf(`{"name":"ContractFilm","description":"Contract","date":"2003-03-03","rate":7,"actors":[%d,%d],"roles":{"%d":"Lead"}}`, actorID, costarID, actorID)
+	request, _ := http.NewRequest("POST", "/films", bytes.NewBufferString(body))
+	request.SetBasicAuth("admin", "admin")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	created := &api_models.FilmResponse{}
+	json.Unmarshal(writer.Body.Bytes(), created)
+	if !assert.NotNil(t, created.Film, writer.Body.String()) {
+		return
+	}
+	filmID := created.Film.ID
+
+	requests := []contractRequest{
+		{"GET", "/films", "/films", "", "client", 200},
+		{"GET", "/films", "/films?sortBy=name&filter=name.Contract&include=actors&limit=1&offset=0", "", "client", 200},
+		{"GET", "/films", "/films", "", "", 401},
+		{"GET", "/films", "/films?limit=0", "", "client", 400},
+		{"POST", "/films", "/films", `{"name":"","date":"2003-03-03"}`, "admin", 400},
+		{"POST", "/films", "/films", `{"name":"Forbidden","date":"2003-03-03"}`, "client", 403},
+		{"GET", "/films/{filmID}", fmt.Sprintf("/films/%d", filmID), "", "client", 200},
+		{"GET", "/films/{filmID}", "/films/100500", "", "client", 404},
+		{"PUT", "/films/{filmID}", fmt.Sprintf("/films/%d?expand=actors", filmID), `{"name":"ContractFilm","date":"2003-03-03","rate":8}`, "admin", 200},
+		{"GET", "/films/by-external/{source}/{id}", "/films/by-external/imdb/tt0000000", "", "client", 404},
+		{"GET", "/actors", "/actors?include=films&limit=10", "", "client", 200},
+		{"GET", "/actors/{actorID}", fmt.Sprintf("/actors/%d", actorID), "", "client", 200},
+		{"PUT", "/actors/{actorID}", fmt.Sprintf("/actors/%d", actorID), `{"name":"ContractActress"}`, "admin", 200},
+		{"GET", "/actors/{actorID}/filmography", fmt.Sprintf("/actors/%d/filmography", actorID), "", "client", 200},
+		{"GET", "/actors/{actorID}/costars", fmt.Sprintf("/actors/%d/costars", actorID), "", "client", 200},
+		{"GET", "/actors/path", fmt.Sprintf("/actors/path?from=%d&to=%d", actorID, costarID), "", "client", 200},
+		{"GET", "/search", "/search?q=contract&limit=5", "", "client", 200},
+		{"GET", "/autocomplete", "/autocomplete?q=contr&type=film", "", "client", 200},
+		{"GET", "/webhooks", "/webhooks", "", "admin", 200},
+		{"GET", "/webhooks/{webhookID}", "/webhooks/100500", "", "admin", 404},
+		{"GET", "/import/{jobID}", "/import/missing", "", "admin", 404},
+		{"GET", "/graphql", "/graphql?query=" + url.QueryEscape("{ films { name } }"), "", "client", 200},
+		{"POST", "/graphql", "/graphql", `{"query":"{ actors { name } }"}`, "client", 200},
+		{"GET", "/users/me", "/users/me", "", "client", 200},
+		{"GET", "/healthcheck", "/healthcheck", "", "", 200},
+		{"DELETE", "/films/{filmID}", fmt.Sprintf("/films/%d", filmID), "", "admin", 200},
+		{"DELETE", "/actors/{actorID}", fmt.Sprintf("/actors/%d", actorID), "", "admin", 200},
+		{"DELETE", "/actors/{actorID}", fmt.Sprintf("/actors/%d", costarID), "", "admin", 200},
+	}
+	for _, req := range requests {
+		// The spec describes the latest version.
+		req.url = "/v2" + req.url
+		t.Run(req.method+" "+req.url, func(t *testing.T) {
+			operation := spec.Paths[req.operation][strings.ToLower(req.method)]
+			if !assert.NotNil(t, operation, "operation is not documented") {
+				return
+			}
+
+			request, _ := http.NewRequest(req.method, req.url, bytes.NewBufferString(req.body))
+			if req.username != "" {
+				request.SetBasicAuth(req.username, req.username)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			if !assert.Equal(t, req.status, writer.Code, writer.Body.String()) {
+				return
+			}
+
+			declared := map[string]bool{}
+			for _, param := range operation.Parameters {
+				if param.In == "query" {
+					declared[param.Name] = true
+				}
+			}
+			for name := range request.URL.Query() {
+				assert.True(t, declared[name], "query param %s is not documented", name)
+			}
+
+			response, ok := operation.Responses[strconv.Itoa(writer.Code)]
+			if !assert.True(t, ok, "status %d is not documented", writer.Code) || response == nil || response.Schema == nil {
+				return
+			}
+			if !strings.Contains(writer.Header().Get("Content-Type"), "json") {
+				return
+			}
+			var value interface{}
+			if !assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), &value)) {
+				return
+			}
+			assert.Empty(t, spec.ValidateStrict(response.Schema, value, "body"))
+		})
+	}
+}
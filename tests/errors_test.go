@@ -0,0 +1,193 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"filmoteka/api"
+	api_models "filmoteka/api/models"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemDetails(t *testing.T) {
+	invalidFilm, _ := json.Marshal(api_models.CreateFilmRequest{
+		Name: "Film",
+		Date: "2001-12-12",
+		Rate: 11,
+	})
+
+	testCases := []struct {
+		name     string
+		method   string
+		url      string
+		body     []byte
+		username string
+		password string
+		status   int
+		code     string
+		field    string
+	}{
+		{
+			name:   "Unauthorized",
+			method: "GET",
+			url:    "/films",
+			status: 401,
+			code:   "unauthorized",
+		},
+		{
+			name:     "Wrong Password",
+			method:   "GET",
+			url:      "/films",
+			username: "admin",
+			password: "wrong",
+			status:   401,
+			code:     "unauthorized",
+		},
+		{
+			name:     "Forbidden",
+			method:   "POST",
+			url:      "/films",
+			body:     invalidFilm,
+			username: "client",
+			password: "client",
+			status:   403,
+			code:     "forbidden",
+		},
+		{
+			name:     "Not Found",
+			method:   "GET",
+			url:      "/films/100500",
+			username: "client",
+			password: "client",
+			status:   404,
+			code:     "film_not_found",
+		},
+		{
+			name:     "Invalide Id",
+			method:   "GET",
+			url:      "/actors/first",
+			username: "client",
+			password: "client",
+			status:   400,
+			code:     "invalid_param",
+		},
+		{
+			name:     "Invalide JSON",
+			method:   "POST",
+			url:      "/actors",
+			body:     []byte("{"),
+			username: "admin",
+			password: "admin",
+			status:   400,
+			code:     "invalid_json",
+		},
+		{
+			name:     "Validation",
+			method:   "POST",
+			url:      "/films",
+			body:     invalidFilm,
+			username: "admin",
+			password: "admin",
+			status:   400,
+			code:     "validation_failed",
+			field:    "rate",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tc.method, tc.url, bytes.NewBuffer(tc.body))
+			if tc.username != "" && tc.password != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.status, writer.Code)
+			assert.Equal(t, "application/problem+json", writer.Header().Get("Content-Type"))
+
+			problem := api.ErrorResponse{}
+			err := json.Unmarshal(writer.Body.Bytes(), &problem)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, problem.Status)
+			assert.Equal(t, tc.code, problem.Code)
+			assert.Equal(t, "/problems/"+tc.code, problem.Type)
+			assert.False(t, problem.Success)
+			if tc.field != "" && assert.Len(t, problem.Errors, 1) {
+				assert.Equal(t, tc.field, problem.Errors[0].Field)
+			}
+		})
+	}
+}
+
+func TestValidationMessages(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{
+		"name": "",
+		"date": "12.12.2001",
+		"rate": 4,
+	})
+
+	testCases := []struct {
+		name     string
+		language string
+		message  string
+	}{
+		{
+			name:    "Default English",
+			message: "date must be a date in YYYY-MM-DD format",
+		},
+		{
+			name:     "Russian",
+			language: "ru-RU,ru;q=0.9,en;q=0.8",
+			message:  "date должно быть датой в формате ГГГГ-ММ-ДД",
+		},
+		{
+			name:     "Unknown Language",
+			language: "de-DE",
+			message:  "date must be a date in YYYY-MM-DD format",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", "/films", bytes.NewBuffer(body))
+			request.SetBasicAuth("admin", "admin")
+			if tc.language != "" {
+				request.Header.Set("Accept-Language", tc.language)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, 400, writer.Code)
+
+			problem := api.ErrorResponse{}
+			json.Unmarshal(writer.Body.Bytes(), &problem)
+			rules := map[string]string{}
+			messages := map[string]string{}
+			for _, field := range problem.Errors {
+				rules[field.Field] = field.Rule
+				messages[field.Field] = field.Message
+			}
+			assert.Equal(t, map[string]string{"name": "min", "date": "date"}, rules)
+			assert.Equal(t, tc.message, messages["date"])
+		})
+	}
+}
+
+func TestAuthDatabaseFailure(t *testing.T) {
+	unreachable := pg.Connect(&pg.Options{Addr: "127.0.0.1:1"})
+	defer unreachable.Close()
+	failing := api.StartAPI(unreachable, testConfig)
+
+	request, _ := http.NewRequest("GET", "/v2/films/1", nil)
+	request.SetBasicAuth("client", "client")
+	writer := httptest.NewRecorder()
+	failing.ServeHTTP(writer, request)
+	assert.Equal(t, 500, writer.Code)
+
+	problem := api.ErrorResponse{}
+	json.Unmarshal(writer.Body.Bytes(), &problem)
+	assert.Equal(t, "internal", problem.Code)
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"filmoteka/api"
+	"filmoteka/db"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamedEvent struct {
+	id    string
+	event string
+	data  db.Event
+}
+
+// readStream runs the stream for a while and parses the received events.
+func readStream(t *testing.T, url string, lastEventID string) (*httptest.ResponseRecorder, []*streamedEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	request.SetBasicAuth("client", "client")
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	events := make([]*streamedEvent, 0)
+	for _, message := range strings.Split(writer.Body.String(), "\n\n") {
+		event := &streamedEvent{}
+		for _, line := range strings.Split(message, "\n") {
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				event.id = value
+			case "event":
+				event.event = value
+			case "data":
+				assert.NoError(t, json.Unmarshal([]byte(value), &event.data))
+			}
+		}
+		if event.id != "" {
+			events = append(events, event)
+		}
+	}
+	return writer, events
+}
+
+func TestEventStream(t *testing.T) {
+	create := func(url string, body string) {
+		request, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 200, writer.Code)
+	}
+
+	create("/actors", `{"name":"StreamedActorOne","sex":"male","birth":"1970-01-01"}`)
+	writer, events := readStream(t, "/events?entity=actor&last_event_id=0", "")
+	assert.Equal(t, 200, writer.Code)
+	assert.Equal(t, "text/event-stream", writer.Header().Get("Content-Type"))
+
+	lastID := ""
+	for _, event := range events {
+		assert.Equal(t, db.EntityActor, event.data.Entity)
+		if strings.Contains(string(event.data.Payload), "StreamedActorOne") {
+			lastID = event.id
+		}
+	}
+	if !assert.NotEmpty(t, lastID) {
+		return
+	}
+
+	create("/actors", `{"name":"StreamedActorTwo","sex":"male","birth":"1970-01-01"}`)
+	create("/films", `{"name":"StreamedFilm","date":"2010-01-01","rate":3}`)
+	_, events = readStream(t, "/events?entity=actor", lastID)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, db.EventActorCreated, events[0].event)
+		assert.Equal(t, events[0].id, strconv.FormatInt(events[0].data.ID, 10))
+		assert.Contains(t, string(events[0].data.Payload), "StreamedActorTwo")
+	}
+}
+
+func TestEventIDsFollowCatalogueVersion(t *testing.T) {
+	before, err := db.LastEventID(testDB)
+	assert.NoError(t, err)
+
+	err = testDB.RunInTransaction(context.Background(), func(tx *pg.Tx) error {
+		actor, err := db.CreateActor(tx, &db.Actor{Name: "VersionedActor", Sex: "male", Birth: time.Now()})
+		if err != nil {
+			return err
+		}
+		return db.DeleteActor(tx, actor.ID)
+	})
+	assert.NoError(t, err)
+
+	events, err := db.GetEvents(testDB, before, []string{db.EntityActor}, 10)
+	if assert.NoError(t, err) && assert.Len(t, events, 2) {
+		assert.Equal(t, before+1, events[0].ID)
+		assert.Equal(t, before+2, events[1].ID)
+	}
+}
+
+func TestEventStreamEndsOnShutdown(t *testing.T) {
+	shutdown := make(chan struct{})
+	close(shutdown)
+	ctx, cancel := context.WithTimeout(api.WithShutdown(context.Background(), shutdown), 5*time.Second)
+	defer cancel()
+	request, _ := http.NewRequestWithContext(ctx, "GET", "/events", nil)
+	request.SetBasicAuth("client", "client")
+	writer := httptest.NewRecorder()
+
+	start := time.Now()
+	router.ServeHTTP(writer, request)
+	assert.Equal(t, 200, writer.Code)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestEventStreamErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		url      string
+		username string
+		status   int
+		code     string
+	}{
+		{
+			name:   "Unauthorized",
+			url:    "/events",
+			status: 401,
+			code:   "unauthorized",
+		},
+		{
+			name:     "Unknown Entity",
+			url:      "/events?entity=film,director",
+			username: "client",
+			status:   400,
+			code:     "invalid_param",
+		},
+		{
+			name:     "Invalid Last Event Id",
+			url:      "/events?last_event_id=first",
+			username: "client",
+			status:   400,
+			code:     "invalid_param",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", tc.url, nil)
+			if tc.username != "" {
+				request.SetBasicAuth(tc.username, tc.username)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.status, writer.Code)
+
+			problem := api.ErrorResponse{}
+			json.Unmarshal(writer.Body.Bytes(), &problem)
+			assert.Equal(t, tc.code, problem.Code)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"filmoteka/exporter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExport(t *testing.T) {
+	actor := createTestActor(t, "ExportActor")
+	createTestFilm(t, "ExportFilm", "2011-11-11", []int64{actor})
+
+	testCases := []struct {
+		name        string
+		url         string
+		username    string
+		password    string
+		code        int
+		contentType string
+	}{
+		{
+			name: "No Auth",
+			url:  "/export/films",
+			code: 401,
+		},
+		{
+			name:     "Unknown Format",
+			url:      "/export/films?format=pdf",
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+		{
+			name:     "Unknown Sort Field",
+			url:      "/export/films?sortBy=unknown",
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+		{
+			name:        "Films CSV",
+			url:         "/export/films?filter=name.ExportFilm",
+			username:    "client",
+			password:    "client",
+			code:        200,
+			contentType: "text/csv; charset=utf-8",
+		},
+		{
+			name:        "Actors NDJSON",
+			url:         "/export/actors?format=ndjson",
+			username:    "client",
+			password:    "client",
+			code:        200,
+			contentType: "application/x-ndjson",
+		},
+		{
+			name:        "Films XLSX",
+			url:         "/export/films?format=xlsx&sortBy=name",
+			username:    "client",
+			password:    "client",
+			code:        200,
+			contentType: exporter.ContentType(exporter.FormatXLSX),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", tc.url, nil)
+			if tc.username != "" && tc.password != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.code, writer.Code)
+			if tc.code != 200 {
+				return
+			}
+			assert.Equal(t, tc.contentType, writer.Header().Get("Content-Type"))
+			assert.Contains(t, writer.Header().Get("Content-Disposition"), "attachment")
+		})
+	}
+
+	t.Run("Films Rows", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/export/films?filter=name.ExportFilm", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		rows, err := csv.NewReader(writer.Body).ReadAll()
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, exporter.FilmColumns, rows[0])
+			assert.Equal(t, "ExportFilm", rows[1][1])
+			assert.Equal(t, "2011-11-11", rows[1][3])
+			assert.Equal(t, "ExportActor", rows[1][5])
+		}
+	})
+
+	t.Run("Actors Rows", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/export/actors?format=ndjson", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		found := false
+		scanner := bufio.NewScanner(writer.Body)
+		for scanner.Scan() {
+			row := struct {
+				Name  string   `json:"name"`
+				Birth string   `json:"birth"`
+				Films []string `json:"films"`
+			}{}
+			err := json.Unmarshal(scanner.Bytes(), &row)
+			assert.NoError(t, err)
+			if row.Name == "ExportActor" {
+				found = true
+				assert.Equal(t, "1990-01-01", row.Birth)
+				assert.Equal(t, []string{"ExportFilm"}, row.Films)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Films Workbook", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/export/films?format=xlsx&filter=name.ExportFilm", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+
+		book, err := excelize.OpenReader(writer.Body)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer book.Close()
+		rows, err := book.GetRows("Sheet1")
+		assert.NoError(t, err)
+		if assert.Len(t, rows, 2) {
+			assert.Equal(t, "ExportFilm", rows[1][1])
+			assert.True(t, strings.HasPrefix(rows[1][3], "2011-11-11"))
+		}
+	})
+}
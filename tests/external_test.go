@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api_models "filmoteka/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalIDs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		username string
+		password string
+		code     int
+		cast     []string
+	}{
+		{
+			name:     "Create Actor Client",
+			method:   "PUT",
+			url:      "/actors/by-external/imdb/nm0000206",
+			body:     `{"name":"Keanu Reeves","sex":"male","birth":"1964-09-02"}`,
+			username: "client",
+			password: "client",
+			code:     403,
+		},
+		{
+			name:     "Create Actor",
+			method:   "PUT",
+			url:      "/actors/by-external/imdb/nm0000206",
+			body:     `{"name":"Keanu Reeves","sex":"male","birth":"1964-09-02","external_ids":{"kinopoisk":"9144"}}`,
+			username: "admin",
+			password: "admin",
+			code:     201,
+		},
+		{
+			name:     "Create Second Actor",
+			method:   "PUT",
+			url:      "/actors/by-external/imdb/nm0000401",
+			body:     `{"name":"Laurence Fishburne","sex":"male","birth":"1961-07-30"}`,
+			username: "admin",
+			password: "admin",
+			code:     201,
+		},
+		{
+			name:     "Update Actor",
+			method:   "PUT",
+			url:      "/actors/by-external/kinopoisk/9144",
+			body:     `{"name":"Keanu Charles Reeves","sex":"male","birth":"1964-09-02"}`,
+			username: "admin",
+			password: "admin",
+			code:     200,
+		},
+		{
+			name:     "Unknown Source",
+			method:   "GET",
+			url:      "/films/by-external/netflix/1",
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+		{
+			name:     "Unknown Source In Body",
+			method:   "PUT",
+			url:      "/films/by-external/imdb/tt0133093",
+			body:     `{"name":"The Matrix","date":"1999-03-31","rate":9,"external_ids":{"netflix":"1"}}`,
+			username: "admin",
+			password: "admin",
+			code:     400,
+		},
+		{
+			name:     "Contradicting Id",
+			method:   "PUT",
+			url:      "/films/by-external/imdb/tt0133093",
+			body:     `{"name":"The Matrix","date":"1999-03-31","rate":9,"external_ids":{"imdb":"tt0000001"}}`,
+			username: "admin",
+			password: "admin",
+			code:     400,
+		},
+		{
+			name:     "Unknown Cast",
+			method:   "PUT",
+			url:      "/films/by-external/imdb/tt0133093",
+			body:     `{"name":"The Matrix","date":"1999-03-31","rate":9,"cast":[{"external":{"source":"imdb","id":"nm404"}}]}`,
+			username: "admin",
+			password: "admin",
+			code:     409,
+		},
+		{
+			name:     "Get Missing Film",
+			method:   "GET",
+			url:      "/films/by-external/imdb/tt0133093",
+			username: "client",
+			password: "client",
+			code:     404,
+		},
+		{
+			name:   "Create Film",
+			method: "PUT",
+			url:    "/films/by-external/imdb/tt0133093",
+			body: `{"name":"The Matrix","date":"1999-03-31","rate":9,"external_ids":{"kinopoisk":"301"},` +
+				`"cast":[{"external":{"source":"imdb","id":"nm0000206"},"role":"Neo"},{"external":{"source":"kinopoisk","id":"9144"},"role":"Thomas Anderson"}]}`,
+			username: "admin",
+			password: "admin",
+			code:     201,
+			cast:     []string{"Keanu Charles Reeves"},
+		},
+		{
+			name:   "Reconcile Cast",
+			method: "PUT",
+			url:    "/films/by-external/kinopoisk/301",
+			body: `{"name":"The Matrix","date":"1999-03-31","rate":10,` +
+				`"cast":[{"external":{"source":"imdb","id":"nm0000401"},"role":"Morpheus"},{"external":{"source":"imdb","id":"nm0000206"},"role":"Neo"}]}`,
+			username: "admin",
+			password: "admin",
+			code:     200,
+			cast:     []string{"Keanu Charles Reeves", "Laurence Fishburne"},
+		},
+		{
+			name:     "Keep Cast",
+			method:   "PUT",
+			url:      "/films/by-external/imdb/tt0133093",
+			body:     `{"name":"The Matrix","date":"1999-03-31","rate":10}`,
+			username: "admin",
+			password: "admin",
+			code:     200,
+			cast:     []string{"Keanu Charles Reeves", "Laurence Fishburne"},
+		},
+		{
+			name:     "Get Film",
+			method:   "GET",
+			url:      "/films/by-external/kinopoisk/301",
+			username: "client",
+			password: "client",
+			code:     200,
+			cast:     []string{"Keanu Charles Reeves", "Laurence Fishburne"},
+		},
+		{
+			name:     "Clear Cast",
+			method:   "PUT",
+			url:      "/films/by-external/imdb/tt0133093",
+			body:     `{"name":"The Matrix","date":"1999-03-31","rate":10,"cast":[]}`,
+			username: "admin",
+			password: "admin",
+			code:     200,
+			cast:     []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			request.SetBasicAuth(tc.username, tc.password)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+
+			assert.Equal(t, tc.code, writer.Code)
+			if tc.cast == nil {
+				return
+			}
+
+			resp := api_models.FilmResponse{}
+			err := json.Unmarshal(writer.Body.Bytes(), &resp)
+			assert.NoError(t, err)
+			if !assert.NotNil(t, resp.Film) {
+				return
+			}
+			assert.Equal(t, map[string]string{"imdb": "tt0133093", "kinopoisk": "301"}, resp.Film.ExternalIDs)
+			names := make([]string, 0)
+			for _, actor := range resp.Film.Actors {
+				names = append(names, actor.Name)
+			}
+			assert.ElementsMatch(t, tc.cast, names)
+		})
+	}
+}
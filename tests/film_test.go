@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	api_models "filmoteka/api/models"
@@ -54,10 +55,10 @@ func TestGetFilms(t *testing.T) {
 
 			assert.Equal(t, tc.code, writer.Code)
 			if writer.Code == 200 {
-				films := api_models.FilmsResponse{}
+				films := api_models.FilmsResponseV1{}
 				err := json.Unmarshal(writer.Body.Bytes(), &films)
 				if err != nil {
-					assert.Fail(t, "Cant parse response to api_models.FilmsResponse")
+					assert.Fail(t, "Cant parse response to api_models.FilmsResponseV1")
 				}
 			}
 		})
@@ -90,7 +91,7 @@ func TestCreateFilms(t *testing.T) {
 			name:     "Client Auth",
 			username: "client",
 			password: "client",
-			code:     401,
+			code:     403,
 		},
 		{
 			name:        "Admin Auth Valid Data",
@@ -213,7 +214,7 @@ func TestUpdateFilms(t *testing.T) {
 			username: "client",
 			password: "client",
 			film_id:  "1",
-			code:     401,
+			code:     403,
 		},
 		{
 			name:        "Admin Auth Valid Data",
@@ -337,7 +338,7 @@ func TestDeleteFilms(t *testing.T) {
 			film_id:  "1",
 			username: "client",
 			password: "client",
-			code:     401,
+			code:     403,
 		},
 		{
 			name:     "Admin Auth Valid Data No ID",
@@ -357,7 +358,7 @@ func TestDeleteFilms(t *testing.T) {
 			username: "admin",
 			password: "admin",
 			wrong_id: "45",
-			code:     400,
+			code:     404,
 		},
 	}
 	for _, tc := range testCases {
@@ -379,7 +380,7 @@ func TestDeleteFilms(t *testing.T) {
 			writer := httptest.NewRecorder()
 			router.ServeHTTP(writer, request)
 
-			films := api_models.FilmsResponse{}
+			films := api_models.FilmsResponseV1{}
 			err := json.Unmarshal(writer.Body.Bytes(), &films)
 			if err != nil {
 				panic(err)
@@ -414,7 +415,7 @@ func TestDeleteFilms(t *testing.T) {
 				writer = httptest.NewRecorder()
 				router.ServeHTTP(writer, request)
 
-				films := api_models.FilmsResponse{}
+				films := api_models.FilmsResponseV1{}
 				err := json.Unmarshal(writer.Body.Bytes(), &films)
 				if err != nil {
 					panic(err)
@@ -425,3 +426,176 @@ func TestDeleteFilms(t *testing.T) {
 		})
 	}
 }
+
+func TestGetFilmsView(t *testing.T) {
+	actor := createTestActor(t, "ViewActor")
+	createTestFilm(t, "ViewFilm", "2003-03-03", []int64{actor})
+
+	testCases := []struct {
+		name   string
+		query  string
+		code   int
+		keys   []string
+		nested bool
+	}{
+		{
+			name: "Default Without Actors",
+			code: 200,
+			keys: []string{"id", "name", "description", "date", "rate"},
+		},
+		{
+			name:   "Expand Actors",
+			query:  "?expand=actors",
+			code:   200,
+			keys:   []string{"id", "name", "description", "date", "rate", "actors"},
+			nested: true,
+		},
+		{
+			name:  "Selected Fields",
+			query: "?fields=id,name,rate",
+			code:  200,
+			keys:  []string{"id", "name", "rate"},
+		},
+		{
+			name:   "Include Actors Fields",
+			query:  "?include=actors&fields[films]=id,name&fields[actors]=id,name",
+			code:   200,
+			keys:   []string{"id", "name", "actors"},
+			nested: true,
+		},
+		{
+			name:  "Sparse Fieldset",
+			query: "?fields[films]=id,name,rate",
+			code:  200,
+			keys:  []string{"id", "name", "rate"},
+		},
+		{
+			name:  "Invalide Expand",
+			query: "?expand=directors",
+			code:  400,
+		},
+		{
+			name:  "Invalide Fields",
+			query: "?fields=id,budget",
+			code:  400,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", "/films"+tc.query, nil)
+			request.SetBasicAuth("client", "client")
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.code, writer.Code)
+
+			if writer.Code == 200 {
+				resp := struct {
+					Films []map[string]json.RawMessage `json:"film"`
+				}{}
+				json.Unmarshal(writer.Body.Bytes(), &resp)
+				for _, film := range resp.Films {
+					if string(film["name"]) != `"ViewFilm"` {
+						continue
+					}
+					keys := make([]string, 0)
+					for key := range film {
+						keys = append(keys, key)
+					}
+					assert.ElementsMatch(t, tc.keys, keys)
+
+					if tc.nested {
+						actors := []map[string]json.RawMessage{}
+						json.Unmarshal(film["actors"], &actors)
+						if assert.Len(t, actors, 1) {
+							assert.NotContains(t, actors[0], "films")
+							if strings.Contains(tc.query, "fields[actors]") {
+								assert.Len(t, actors[0], 2)
+							}
+						}
+					}
+					return
+				}
+				assert.Fail(t, "ViewFilm not found in response")
+			}
+		})
+	}
+}
+
+func TestGetFilm(t *testing.T) {
+	actor := createTestActor(t, "SingleFilmActor")
+	createTestFilm(t, "SingleFilm", "2004-04-04", []int64{actor})
+
+	request, _ := http.NewRequest("GET", "/actors/"+strconv.FormatInt(actor, 10)+"?include=films", nil)
+	request.SetBasicAuth("client", "client")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	assert.Equal(t, 200, writer.Code)
+
+	resp := api_models.ActorResponse{}
+	json.Unmarshal(writer.Body.Bytes(), &resp)
+	if !assert.NotNil(t, resp.Actor) || !assert.Len(t, resp.Actor.Films, 1) {
+		return
+	}
+	filmID := strconv.Itoa(resp.Actor.Films[0].ID)
+
+	testCases := []struct {
+		name     string
+		url      string
+		username string
+		password string
+		code     int
+		keys     []string
+	}{
+		{
+			name: "No Auth",
+			url:  "/films/" + filmID,
+			code: 401,
+		},
+		{
+			name:     "Client Auth",
+			url:      "/films/" + filmID,
+			username: "client",
+			password: "client",
+			code:     200,
+			keys:     []string{"id", "name", "description", "date", "rate", "actors"},
+		},
+		{
+			name:     "Sparse Fieldset",
+			url:      "/films/" + filmID + "?fields[films]=name,rate",
+			username: "client",
+			password: "client",
+			code:     200,
+			keys:     []string{"name", "rate"},
+		},
+		{
+			name:     "Not Found",
+			url:      "/films/100500",
+			username: "client",
+			password: "client",
+			code:     404,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", tc.url, nil)
+			if tc.username != "" && tc.password != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.code, writer.Code)
+
+			if writer.Code == 200 {
+				resp := struct {
+					Film map[string]json.RawMessage `json:"film"`
+				}{}
+				json.Unmarshal(writer.Body.Bytes(), &resp)
+				keys := make([]string, 0)
+				for key := range resp.Film {
+					keys = append(keys, key)
+				}
+				assert.ElementsMatch(t, tc.keys, keys)
+			}
+		})
+	}
+}
@@ -4,15 +4,30 @@ import (
 	"filmoteka/api"
 	"filmoteka/config"
 	"filmoteka/db"
+	"filmoteka/grpcapi"
 	"filmoteka/logger"
+	"filmoteka/webhooks"
 	"log/slog"
 	"os"
 	"testing"
 
-	"github.com/go-chi/chi/v5"
+	"github.com/go-pg/pg/v10"
+	"google.golang.org/grpc/test/bufconn"
 )
 
-var router *chi.Mux
+var router *api.API
+
+// dispatcher sends webhook deliveries when tests call RunOnce.
+var dispatcher *webhooks.Dispatcher
+
+// grpcListener serves gRPC API in memory for grpc tests.
+var grpcListener *bufconn.Listener
+
+// testDB and testConfig build routers with changed config.
+var (
+	testDB     *pg.DB
+	testConfig *config.Config
+)
 
 func TestMain(m *testing.M) {
 	cnf_var := os.Getenv("CONFIG_PATH")
@@ -25,10 +40,15 @@ func TestMain(m *testing.M) {
 
 	pgdb, err := db.StartDB(cfg)
 	if err != nil {
-		log.Error("error starting the database %v", err)
+		log.Error("error starting the database", "err", err)
+		os.Exit(1)
 	}
 
 	router = api.StartAPI(pgdb, cfg)
+	testDB, testConfig = pgdb, cfg
+	dispatcher = webhooks.NewDispatcher(pgdb, cfg.Webhooks)
+	grpcListener = bufconn.Listen(1 << 20)
+	go grpcapi.NewServer(pgdb).Serve(grpcListener)
 
 	// err = http.ListenAndServe(cfg.HTTPServer.Address, router)
 	// if err != nil {
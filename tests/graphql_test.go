@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type graphqlResult struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func graphqlRequest(t *testing.T, username string, query string, variables map[string]interface{}) (*httptest.ResponseRecorder, *graphqlResult) {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	request, _ := http.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+	if username != "" {
+		request.SetBasicAuth(username, username)
+	}
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	result := &graphqlResult{}
+	if writer.Code == 200 {
+		assert.NoError(t, json.Unmarshal(writer.Body.Bytes(), result))
+	}
+	return writer, result
+}
+
+func TestGraphQL(t *testing.T) {
+	createActor := `mutation($input: ActorInput!) { createActor(input: $input) { id name birthday } }`
+	_, result := graphqlRequest(t, "admin", createActor, map[string]interface{}{
+		"input": map[string]interface{}{"name": "GraphActor", "sex": "female", "birth": "1977-07-07"},
+	})
+	if !assert.Empty(t, result.Errors) {
+		return
+	}
+	actor := struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Birthday string `json:"birthday"`
+	}{}
+	json.Unmarshal(result.Data["createActor"], &actor)
+	assert.Equal(t, "1977-07-07", actor.Birthday)
+
+	createFilm := `mutation($input: FilmInput!) { createFilm(input: $input) { id } }`
+	_, result = graphqlRequest(t, "admin", createFilm, map[string]interface{}{
+		"input": map[string]interface{}{
+			"name": "GraphFilm", "date": "2005-05-05", "rate": 8,
+			"cast": []map[string]interface{}{{"actorId": actor.ID, "role": "Lead"}},
+		},
+	})
+	if !assert.Empty(t, result.Errors) {
+		return
+	}
+	film := struct {
+		ID string `json:"id"`
+	}{}
+	json.Unmarshal(result.Data["createFilm"], &film)
+
+	t.Run("Nested Query", func(t *testing.T) {
+		query := `query($id: ID!) { film(id: $id) { name cast { role actor { name filmography { role film { name } } } } } }`
+		_, result := graphqlRequest(t, "client", query, map[string]interface{}{"id": film.ID})
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"name":"GraphFilm","cast":[{"role":"Lead","actor":{"name":"GraphActor",
+			"filmography":[{"role":"Lead","film":{"name":"GraphFilm"}}]}}]}`, string(result.Data["film"]))
+	})
+
+	t.Run("Missing Film", func(t *testing.T) {
+		_, result := graphqlRequest(t, "client", `{ film(id: "100500") { name } }`, nil)
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, "null", string(result.Data["film"]))
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		query := `mutation($id: ID!) { updateFilm(id: $id, input: {rate: 9, cast: []}) { name rate cast { role } } }`
+		_, result := graphqlRequest(t, "admin", query, map[string]interface{}{"id": film.ID})
+		assert.Empty(t, result.Errors)
+		assert.JSONEq(t, `{"name":"GraphFilm","rate":9,"cast":[]}`, string(result.Data["updateFilm"]))
+	})
+
+	t.Run("Forbidden Mutation", func(t *testing.T) {
+		_, result := graphqlRequest(t, "client", `mutation($id: ID!) { deleteFilm(id: $id) }`, map[string]interface{}{"id": film.ID})
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "forbidden", result.Errors[0].Extensions["code"])
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, result := graphqlRequest(t, "admin", createFilm, map[string]interface{}{
+			"input": map[string]interface{}{"name": "GraphFilm", "date": "05.05.2005", "rate": 11},
+		})
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "validation_failed", result.Errors[0].Extensions["code"])
+			assert.Len(t, result.Errors[0].Extensions["errors"], 2)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, result := graphqlRequest(t, "admin", `mutation($id: ID!) { deleteFilm(id: $id) }`, map[string]interface{}{"id": film.ID})
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, `"`+film.ID+`"`, string(result.Data["deleteFilm"]))
+	})
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	t.Run("Unauthorized", func(t *testing.T) {
+		writer, _ := graphqlRequest(t, "", `{ actors { name } }`, nil)
+		assert.Equal(t, 401, writer.Code)
+	})
+
+	t.Run("Too Deep", func(t *testing.T) {
+		query := `{ film(id: "1") { cast { actor { filmography { film { cast { actor { filmography { role } } } } } } } } }`
+		_, result := graphqlRequest(t, "client", query, nil)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "query_too_deep", result.Errors[0].Extensions["code"])
+		}
+		assert.Nil(t, result.Data)
+	})
+
+	t.Run("Too Complex", func(t *testing.T) {
+		query := `{ films { name cast { role actor { name filmography { role film { name } } } } } }`
+		_, result := graphqlRequest(t, "client", query, nil)
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "query_too_complex", result.Errors[0].Extensions["code"])
+		}
+	})
+
+	t.Run("Paged Lists", func(t *testing.T) {
+		// Limits above list_size are clamped, negative ones page one item.
+		pages := map[string]int{
+			`{ actors(limit: 1000) { name } }`:          10,
+			`{ actors(limit: 2, offset: 1) { name } }`:  2,
+			`{ films(limit: -5, offset: -5) { name } }`: 1,
+		}
+		for query, size := range pages {
+			_, result := graphqlRequest(t, "client", query, nil)
+			assert.Empty(t, result.Errors, query)
+			for _, list := range result.Data {
+				items := []map[string]interface{}{}
+				json.Unmarshal(list, &items)
+				assert.LessOrEqual(t, len(items), size, query)
+			}
+		}
+
+		// Cost follows the effective limit.
+		nested := `{ name cast { role actor { name filmography { role film { name } } } } }`
+		_, result := graphqlRequest(t, "client", `{ films(limit: 1) `+nested+` }`, nil)
+		assert.Empty(t, result.Errors)
+		_, result = graphqlRequest(t, "client", `query($limit: Int) { films(limit: $limit) `+nested+` }`, map[string]interface{}{"limit": 1e30})
+		if assert.Len(t, result.Errors, 1) {
+			assert.Equal(t, "query_too_complex", result.Errors[0].Extensions["code"])
+		}
+	})
+
+	t.Run("Out Of Range Limit", func(t *testing.T) {
+		expensive := `films { name cast { role actor { name filmography { role film { name } } } } }`
+		queries := []struct {
+			query     string
+			variables map[string]interface{}
+		}{
+			{`{ search(query: "x", limit: -1000000) { name } ` + expensive + ` }`, nil},
+			{`query($limit: Int) { search(query: "x", limit: $limit) { name } ` + expensive + ` }`, map[string]interface{}{"limit": -1e6}},
+			{`query($limit: Int) { search(query: "x", limit: $limit) { name } ` + expensive + ` }`, map[string]interface{}{"limit": 1e30}},
+		}
+		for _, tc := range queries {
+			_, result := graphqlRequest(t, "client", tc.query, tc.variables)
+			if assert.Len(t, result.Errors, 1) {
+				assert.Equal(t, "query_too_complex", result.Errors[0].Extensions["code"])
+			}
+		}
+	})
+
+	t.Run("Mutation Over GET", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/graphql?query="+strings.ReplaceAll(`mutation { deleteFilm(id: "1") }`, " ", "+"), nil)
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 400, writer.Code)
+	})
+}
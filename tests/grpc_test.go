@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	filmotekav1 "filmoteka/proto/filmoteka/v1"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func grpcClient(t *testing.T) *grpc.ClientConn {
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return grpcListener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// grpcContext authenticates calls like REST tests, password is the username.
+func grpcContext(username string) context.Context {
+	ctx := context.Background()
+	if username == "" {
+		return ctx
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + username))
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Basic "+auth)
+}
+
+func grpcReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestGRPCHealth(t *testing.T) {
+	client := healthpb.NewHealthClient(grpcClient(t))
+
+	for _, service := range []string{"", "filmoteka.v1.FilmService", "filmoteka.v1.ActorService"} {
+		res, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if assert.NoError(t, err, service) {
+			assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status, service)
+		}
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	conn := grpcClient(t)
+	users := filmotekav1.NewUserServiceClient(conn)
+	films := filmotekav1.NewFilmServiceClient(conn)
+
+	_, err := users.GetCurrentUser(grpcContext(""), &filmotekav1.GetCurrentUserRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = users.GetCurrentUser(grpcContext("nobody"), &filmotekav1.GetCurrentUserRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	user, err := users.GetCurrentUser(grpcContext("client"), &filmotekav1.GetCurrentUserRequest{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "client", user.Username)
+		assert.Equal(t, filmotekav1.Role_ROLE_CLIENT, user.Role)
+	}
+
+	_, err = films.CreateFilm(grpcContext("client"), &filmotekav1.CreateFilmRequest{Name: "GrpcForbidden", Date: "2001-01-01"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "forbidden", grpcReason(err))
+}
+
+func TestGRPCFilms(t *testing.T) {
+	conn := grpcClient(t)
+	actors := filmotekav1.NewActorServiceClient(conn)
+	films := filmotekav1.NewFilmServiceClient(conn)
+	admin := grpcContext("admin")
+
+	actor, err := actors.CreateActor(admin, &filmotekav1.CreateActorRequest{
+		Name: "GrpcActor", Sex: filmotekav1.Sex_SEX_MALE, Birthday: "1970-01-02",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	film, err := films.CreateFilm(admin, &filmotekav1.CreateFilmRequest{
+		Name: "GrpcFilm", Description: "Streamed", Date: "2010-10-10", Rate: 6,
+		Cast: []*filmotekav1.CastInput{{ActorId: actor.Id, Role: "Hero"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "2010-10-10", film.Date)
+	if assert.Len(t, film.Cast, 1) {
+		assert.True(t, proto.Equal(&filmotekav1.CastMember{ActorId: actor.Id, ActorName: "GrpcActor", Role: "Hero"}, film.Cast[0]))
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		got, err := films.GetFilm(grpcContext("client"), &filmotekav1.GetFilmRequest{Id: film.Id})
+		if assert.NoError(t, err) {
+			assert.True(t, proto.Equal(film, got))
+		}
+
+		gotActor, err := actors.GetActor(grpcContext("client"), &filmotekav1.GetActorRequest{Id: actor.Id})
+		if assert.NoError(t, err) && assert.Len(t, gotActor.Films, 1) {
+			assert.True(t, proto.Equal(&filmotekav1.ActorFilm{FilmId: film.Id, Name: "GrpcFilm", Role: "Hero"}, gotActor.Films[0]))
+		}
+	})
+
+	t.Run("List Stream", func(t *testing.T) {
+		stream, err := films.ListFilms(grpcContext("client"), &filmotekav1.ListFilmsRequest{
+			SortBy: filmotekav1.FilmSort_FILM_SORT_NAME,
+			Filter: &filmotekav1.FilmFilter{Field: filmotekav1.FilmFilterField_FILM_FILTER_FIELD_NAME, Value: "GrpcFilm"},
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		received := make([]*filmotekav1.Film, 0)
+		for {
+			film, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			received = append(received, film)
+		}
+		if assert.Len(t, received, 1) {
+			assert.True(t, proto.Equal(film, received[0]))
+		}
+	})
+
+	t.Run("Update Present Fields", func(t *testing.T) {
+		rate := int32(9)
+		updated, err := films.UpdateFilm(admin, &filmotekav1.UpdateFilmRequest{Id: film.Id, Rate: &rate, ReplaceCast: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "GrpcFilm", updated.Name)
+			assert.Equal(t, int32(9), updated.Rate)
+			assert.Empty(t, updated.Cast)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		empty := ""
+		_, err := films.UpdateFilm(admin, &filmotekav1.UpdateFilmRequest{Id: film.Id, Name: &empty})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "validation_failed", grpcReason(err))
+		var fields []string
+		for _, detail := range status.Convert(err).Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				for _, violation := range badRequest.FieldViolations {
+					fields = append(fields, violation.Field)
+				}
+			}
+		}
+		assert.Equal(t, []string{"name"}, fields)
+
+		_, err = actors.CreateActor(admin, &filmotekav1.CreateActorRequest{Name: "NoSex", Birthday: "1970-01-02"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := films.DeleteFilm(admin, &filmotekav1.DeleteFilmRequest{Id: film.Id})
+		assert.NoError(t, err)
+
+		_, err = films.GetFilm(admin, &filmotekav1.GetFilmRequest{Id: film.Id})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, "film_not_found", grpcReason(err))
+
+		_, err = actors.DeleteActor(admin, &filmotekav1.DeleteActorRequest{Id: actor.Id})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"filmoteka/api"
+	"filmoteka/db"
+	"filmoteka/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(ctx context.Context, url string) (*httptest.ResponseRecorder, *health.Report) {
+	request, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+
+	report := &health.Report{}
+	json.Unmarshal(writer.Body.Bytes(), report)
+	return writer, report
+}
+
+func findCheck(report *health.Report, name string) *health.Result {
+	for _, result := range report.Checks {
+		if result.Name == name {
+			return result
+		}
+	}
+	return nil
+}
+
+func TestLivez(t *testing.T) {
+	writer, report := probe(context.Background(), "/livez")
+	assert.Equal(t, 200, writer.Code)
+	assert.Equal(t, health.StatusOK, report.Status)
+}
+
+func TestReadyz(t *testing.T) {
+	writer, report := probe(context.Background(), "/readyz")
+	assert.Equal(t, "no-store", writer.Header().Get("Cache-Control"))
+
+	database := findCheck(report, "database")
+	if assert.NotNil(t, database) {
+		assert.Equal(t, health.StatusOK, database.Status)
+		assert.GreaterOrEqual(t, database.Latency, 0.0)
+	}
+	assert.NotNil(t, findCheck(report, "migrations"))
+	assert.NotNil(t, findCheck(report, "cache"))
+
+	// Test tables are temporary, so migrations may be missing on other
+	// connections of the pool and the status is checked to match the code.
+	if report.Status == health.StatusOK {
+		assert.Equal(t, 200, writer.Code)
+	} else {
+		assert.Equal(t, 503, writer.Code)
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	// Schema updates were applied when the database was started.
+	assert.NoError(t, db.CheckSchema(ctx, testDB))
+}
+
+func TestReadyzOnShutdown(t *testing.T) {
+	shutdown := make(chan struct{})
+	close(shutdown)
+	writer, report := probe(api.WithShutdown(context.Background(), shutdown), "/readyz")
+	assert.Equal(t, 503, writer.Code)
+	assert.Equal(t, health.StatusFail, report.Status)
+	if check := findCheck(report, "shutdown"); assert.NotNil(t, check) {
+		assert.Equal(t, health.StatusFail, check.Status)
+	}
+
+	writer, _ = probe(api.WithShutdown(context.Background(), shutdown), "/livez")
+	assert.Equal(t, 200, writer.Code)
+}
+
+func TestHealthcheck(t *testing.T) {
+	for _, url := range []string{"/healthcheck", "/v1/healthcheck"} {
+		writer, _ := probe(context.Background(), url)
+		assert.Equal(t, 200, writer.Code, url)
+		assert.Equal(t, "OK", writer.Body.String(), url)
+	}
+}
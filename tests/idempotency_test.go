@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"filmoteka/api"
+	api_models "filmoteka/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	post := func(url string, key string, body string, username string) *httptest.ResponseRecorder {
+		request, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+		request.SetBasicAuth(username, username)
+		if key != "" {
+			request.Header.Set("Idempotency-Key", key)
+		}
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		return writer
+	}
+	actorID := func(writer *httptest.ResponseRecorder) int64 {
+		resp := api_models.ActorResponse{}
+		err := json.Unmarshal(writer.Body.Bytes(), &resp)
+		if assert.NoError(t, err) && assert.NotNil(t, resp.Actor) {
+			return resp.Actor.ID
+		}
+		return 0
+	}
+	body := `{"name":"IdempotentActor","sex":"male","birth":"1980-01-01"}`
+
+	t.Run("Replay", func(t *testing.T) {
+		first := post("/actors", "idem-key-1", body, "admin")
+		assert.Equal(t, 200, first.Code)
+		assert.Empty(t, first.Header().Get("Idempotent-Replayed"))
+
+		second := post("/actors", "idem-key-1", body, "admin")
+		assert.Equal(t, 200, second.Code)
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, actorID(first), actorID(second))
+	})
+
+	t.Run("Without Key", func(t *testing.T) {
+		first := post("/actors", "", body, "admin")
+		second := post("/actors", "", body, "admin")
+		assert.NotEqual(t, actorID(first), actorID(second))
+	})
+
+	t.Run("Another Body", func(t *testing.T) {
+		post("/actors", "idem-key-2", body, "admin")
+		writer := post("/actors", "idem-key-2", `{"name":"OtherActor","sex":"male","birth":"1980-01-01"}`, "admin")
+		assert.Equal(t, 422, writer.Code)
+
+		problem := api.ErrorResponse{}
+		err := json.Unmarshal(writer.Body.Bytes(), &problem)
+		assert.NoError(t, err)
+		assert.Equal(t, "idempotency_key_reused", problem.Code)
+	})
+
+	t.Run("Another Endpoint", func(t *testing.T) {
+		post("/actors", "idem-key-3", body, "admin")
+		writer := post("/films", "idem-key-3", body, "admin")
+		assert.Equal(t, 422, writer.Code)
+	})
+
+	t.Run("Errors Are Replayed", func(t *testing.T) {
+		invalid := `{"name":"IdempotentActor","sex":"unknown","birth":"1980-01-01"}`
+		first := post("/actors", "idem-key-4", invalid, "admin")
+		assert.Equal(t, 400, first.Code)
+		second := post("/actors", "idem-key-4", invalid, "admin")
+		assert.Equal(t, 400, second.Code)
+		assert.Equal(t, "true", second.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, "application/problem+json", second.Header().Get("Content-Type"))
+	})
+
+	t.Run("Forbidden Is Not Stored", func(t *testing.T) {
+		first := post("/actors", "idem-key-5", body, "client")
+		assert.Equal(t, 403, first.Code)
+		second := post("/actors", "idem-key-5", body, "client")
+		assert.Equal(t, 403, second.Code)
+		assert.Empty(t, second.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("Keys Are Per User", func(t *testing.T) {
+		first := post("/actors", "idem-key-6", body, "admin")
+		assert.Equal(t, 200, first.Code)
+		second := post("/actors", "idem-key-6", body, "client")
+		assert.Equal(t, 403, second.Code)
+	})
+
+	t.Run("Body Too Large", func(t *testing.T) {
+		// Uploads larger than idempotency.max_body_size are not buffered.
+		upload := "name,description,date,rate\n" + strings.Repeat("Film,Description,2001-01-01,5\n", 4096)
+		request, _ := http.NewRequest("POST", "/import?type=films", strings.NewReader(upload))
+		request.SetBasicAuth("admin", "admin")
+		request.Header.Set("Content-Type", "text/csv")
+		request.Header.Set("Idempotency-Key", "idem-key-7")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 413, writer.Code)
+
+		problem := api.ErrorResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &problem)
+		assert.Equal(t, "payload_too_large", problem.Code)
+		assert.Contains(t, problem.Detail, "Idempotency-Key")
+	})
+}
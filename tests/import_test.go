@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	api_models "filmoteka/api/models"
+	"filmoteka/importer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func startImport(t *testing.T, url string, contentType string, body string) (int, *importer.Job) {
+	request, _ := http.NewRequest("POST", url, bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", contentType)
+	request.SetBasicAuth("admin", "admin")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	if writer.Code != 202 {
+		return writer.Code, nil
+	}
+
+	resp := api_models.ImportJobResponse{}
+	err := json.Unmarshal(writer.Body.Bytes(), &resp)
+	assert.NoError(t, err)
+	assert.Equal(t, "/import/"+resp.Job.ID, writer.Header().Get("Location"))
+	return writer.Code, resp.Job
+}
+
+// waitImport polls the job until it is finished.
+func waitImport(t *testing.T, id string) *importer.Job {
+	for i := 0; i < 100; i++ {
+		request, _ := http.NewRequest("GET", "/import/"+id, nil)
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		if !assert.Equal(t, 200, writer.Code) {
+			return nil
+		}
+
+		resp := api_models.ImportJobResponse{}
+		err := json.Unmarshal(writer.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		if resp.Job.Status == importer.JobDone || resp.Job.Status == importer.JobFailed {
+			return resp.Job
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("import job %s is not finished", id)
+	return nil
+}
+
+func TestImport(t *testing.T) {
+	testCases := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		username    string
+		password    string
+		code        int
+	}{
+		{
+			name:        "Client",
+			url:         "/import?type=actors",
+			contentType: "text/csv",
+			body:        "name,sex,birth\n",
+			username:    "client",
+			password:    "client",
+			code:        403,
+		},
+		{
+			name:        "Unknown Type",
+			url:         "/import?type=users",
+			contentType: "text/csv",
+			body:        "name\n",
+			username:    "admin",
+			password:    "admin",
+			code:        400,
+		},
+		{
+			name:        "Unknown Format",
+			url:         "/import?type=films",
+			contentType: "application/json",
+			body:        "{}",
+			username:    "admin",
+			password:    "admin",
+			code:        400,
+		},
+		{
+			name:        "Empty Body",
+			url:         "/import?type=films&format=csv",
+			contentType: "text/csv",
+			username:    "admin",
+			password:    "admin",
+			code:        400,
+		},
+		{
+			name:        "Too Large",
+			url:         "/import?type=films&format=csv",
+			contentType: "text/csv",
+			body:        "name,date\n" + strings.Repeat("Film,2001-01-01\n", 70000),
+			username:    "admin",
+			password:    "admin",
+			code:        413,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("POST", tc.url, bytes.NewBufferString(tc.body))
+			request.Header.Set("Content-Type", tc.contentType)
+			request.SetBasicAuth(tc.username, tc.password)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.code, writer.Code)
+		})
+	}
+
+	t.Run("Unknown Job", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/import/unknown", nil)
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 404, writer.Code)
+	})
+
+	t.Run("Actors CSV", func(t *testing.T) {
+		body := "Full Name,Gender,Birthday\n" +
+			"ImportActor1,female,1970-01-01\n" +
+			"ImportActor2,male,1975-05-05\n" +
+			"ImportActor3,unknown,1980-01-01\n" +
+			"ImportActor4,male,05.05.1975\n"
+		code, job := startImport(t, "/import?type=actors&map=full%20name=name", "text/csv", body)
+		if !assert.Equal(t, 202, code) {
+			return
+		}
+		job = waitImport(t, job.ID)
+		assert.Equal(t, importer.JobDone, job.Status)
+		assert.Equal(t, 4, job.Report.Processed)
+		assert.Equal(t, 2, job.Report.Created)
+		assert.Equal(t, 2, job.Report.Failed)
+		if assert.Len(t, job.Report.Errors, 2) {
+			assert.Equal(t, 4, job.Report.Errors[0].Row)
+			assert.Equal(t, "sex", job.Report.Errors[0].Field)
+			assert.Equal(t, 5, job.Report.Errors[1].Row)
+			assert.Equal(t, "birth", job.Report.Errors[1].Field)
+		}
+
+		// The same rows are matched by name and birth on the second run.
+		code, job = startImport(t, "/import?type=actors&format=csv&map=full%20name=name", "text/plain", body)
+		if assert.Equal(t, 202, code) {
+			job = waitImport(t, job.ID)
+			assert.Equal(t, 0, job.Report.Created)
+			assert.Equal(t, 2, job.Report.Updated)
+		}
+	})
+
+	t.Run("Films NDJSON", func(t *testing.T) {
+		actor := createTestActor(t, "ImportActor5")
+		body := `{"title":"ImportFilm1","release_date":"2001-01-01","rating":8,"cast":["importactor1",{"name":"ImportActor2","role":"Hero"}]}` + "\n" +
+			`{"title":"ImportFilm2","release_date":"2002-02-02","cast":["#` + strconv.FormatInt(actor, 10) + `"]}` + "\n" +
+			"\n" +
+			`{"title":"ImportFilm3","release_date":"2003-03-03","cast":["Nobody"]}` + "\n" +
+			`{"title":"ImportFilm4","release_date":"2004-04-04","rating":11}` + "\n" +
+			`not json` + "\n"
+
+		code, job := startImport(t, "/import?type=films&dry_run=true", "application/x-ndjson", body)
+		if !assert.Equal(t, 202, code) {
+			return
+		}
+		job = waitImport(t, job.ID)
+		assert.True(t, job.Report.DryRun)
+		assert.Equal(t, 2, job.Report.Created)
+		assert.Equal(t, 3, job.Report.Failed)
+		if assert.Len(t, job.Report.Errors, 3) {
+			assert.Equal(t, 4, job.Report.Errors[0].Row)
+			assert.Equal(t, "actors", job.Report.Errors[0].Field)
+			assert.Equal(t, "rate", job.Report.Errors[1].Field)
+			assert.Equal(t, 6, job.Report.Errors[2].Row)
+		}
+
+		request, _ := http.NewRequest("GET", "/films?filter=name.ImportFilm", nil)
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		films := api_models.FilmsResponseV1{}
+		json.Unmarshal(writer.Body.Bytes(), &films)
+		assert.Len(t, films.Films, 0, "dry run must not write")
+
+		code, job = startImport(t, "/import?type=films", "application/x-ndjson", body)
+		if !assert.Equal(t, 202, code) {
+			return
+		}
+		job = waitImport(t, job.ID)
+		assert.False(t, job.Report.DryRun)
+		assert.Equal(t, 2, job.Report.Created)
+		assert.Equal(t, 1.0, job.Progress)
+
+		writer = httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		json.Unmarshal(writer.Body.Bytes(), &films)
+		assert.Len(t, films.Films, 2)
+	})
+}
+
+func TestImportJobsWait(t *testing.T) {
+	spool := func(rows int) (string, int64) {
+		file, err := os.CreateTemp("", "import-*.csv")
+		if !assert.NoError(t, err) {
+			return "", 0
+		}
+		defer file.Close()
+		file.WriteString("name,description,date,rate\n")
+		for i := 0; i < rows; i++ {
+			file.WriteString("WaitedFilm" + strconv.Itoa(i) + ",Imported before shutdown,2001-01-01,5\n")
+		}
+		info, _ := file.Stat()
+		return file.Name(), info.Size()
+	}
+	opts := importer.Options{Type: importer.TypeFilms, Format: importer.FormatCSV, DryRun: true}
+
+	t.Run("Finished", func(t *testing.T) {
+		jobs := importer.NewJobs(testDB)
+		path, size := spool(10)
+		job := jobs.Start(opts, path, size)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.NoError(t, jobs.Wait(ctx))
+		finished, _ := jobs.Get(job.ID)
+		assert.Equal(t, importer.JobDone, finished.Status)
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		jobs := importer.NewJobs(testDB)
+		path, size := spool(5000)
+		job := jobs.Start(opts, path, size)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		jobs.Wait(ctx)
+		// Imports are stopped when Wait returns, so the pool can be closed.
+		stopped, _ := jobs.Get(job.ID)
+		assert.Contains(t, []string{importer.JobDone, importer.JobFailed}, stopped.Status)
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"filmoteka/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestValidation(t *testing.T) {
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+		body   string
+		status int
+		code   string
+		fields map[string]string
+	}{
+		{
+			name:   "Path Param Type",
+			method: "GET",
+			url:    "/films/first",
+			status: 400,
+			code:   "invalid_param",
+			fields: map[string]string{"filmID": "type"},
+		},
+		{
+			name:   "Query Params",
+			method: "GET",
+			url:    "/films?sortBy=id&filter=actors.Keanu&limit=5000",
+			status: 400,
+			code:   "invalid_param",
+			fields: map[string]string{"sortBy": "enum", "filter": "format", "limit": "maximum"},
+		},
+		{
+			name:   "Required Query Param",
+			method: "GET",
+			url:    "/actors/path?from=1",
+			status: 400,
+			code:   "invalid_param",
+			fields: map[string]string{"to": "required"},
+		},
+		{
+			name:   "Body Types",
+			method: "POST",
+			url:    "/actors",
+			body:   `{"name":7,"sex":"unknown","birth":"2001-01-01"}`,
+			status: 400,
+			code:   "validation_failed",
+			fields: map[string]string{"name": "type", "sex": "enum"},
+		},
+		{
+			name:   "Nested Body Types",
+			method: "POST",
+			url:    "/films",
+			body:   `{"name":"Film","date":"2001-01-01","actors":[1,"two"],"roles":{"1":true}}`,
+			status: 400,
+			code:   "validation_failed",
+			fields: map[string]string{"actors[1]": "type", "roles.1": "type"},
+		},
+		{
+			name:   "Body Syntax",
+			method: "PUT",
+			url:    "/actors/1",
+			body:   `{"name":`,
+			status: 400,
+			code:   "invalid_json",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			request.SetBasicAuth("admin", "admin")
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.status, writer.Code)
+
+			problem := api.ErrorResponse{}
+			json.Unmarshal(writer.Body.Bytes(), &problem)
+			assert.Equal(t, tc.code, problem.Code)
+			rules := map[string]string{}
+			for _, field := range problem.Errors {
+				rules[field.Field] = field.Rule
+				assert.NotEmpty(t, field.Message)
+			}
+			if tc.fields != nil {
+				assert.Equal(t, tc.fields, rules)
+			}
+		})
+	}
+
+	t.Run("Valid Request Passes", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/films?sortBy=date&filter=name.Film&limit=10&offset=0", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 200, writer.Code)
+	})
+}
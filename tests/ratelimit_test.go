@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"filmoteka/api"
+
+	"github.com/go-pg/pg/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("Headers", func(t *testing.T) {
+		request, _ := http.NewRequest("GET", "/v2/films", nil)
+		request.SetBasicAuth("client", "client")
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		assert.Equal(t, 200, writer.Code)
+		assert.Equal(t, "1000", writer.Header().Get("RateLimit-Limit"))
+		assert.NotEmpty(t, writer.Header().Get("RateLimit-Remaining"))
+		assert.NotEmpty(t, writer.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "1000;w=1", writer.Header().Get("RateLimit-Policy"))
+	})
+
+	t.Run("Write Limit", func(t *testing.T) {
+		cfg := *testConfig
+		cfg.RateLimit.WriteRate = 0.01
+		cfg.RateLimit.WriteBurst = 1
+		limited := api.StartAPI(testDB, &cfg)
+
+		codes := make([]int, 0)
+		for i := 0; i < 2; i++ {
+			request, _ := http.NewRequest("DELETE", "/v2/films/999999", nil)
+			request.SetBasicAuth("admin", "admin")
+			writer := httptest.NewRecorder()
+			limited.ServeHTTP(writer, request)
+			codes = append(codes, writer.Code)
+
+			if writer.Code == 429 {
+				assert.Equal(t, "100", writer.Header().Get("Retry-After"))
+				assert.Equal(t, "0", writer.Header().Get("RateLimit-Remaining"))
+				problem := api.ErrorResponse{}
+				json.Unmarshal(writer.Body.Bytes(), &problem)
+				assert.Equal(t, "rate_limited", problem.Code)
+			}
+		}
+		assert.Equal(t, []int{404, 429}, codes)
+
+		// Reads have their own limit.
+		request, _ := http.NewRequest("GET", "/v2/films", nil)
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		limited.ServeHTTP(writer, request)
+		assert.Equal(t, 200, writer.Code)
+
+		// Other users are limited apart.
+		request, _ = http.NewRequest("DELETE", "/v2/films/999999", nil)
+		request.SetBasicAuth("client", "client")
+		writer = httptest.NewRecorder()
+		limited.ServeHTTP(writer, request)
+		assert.Equal(t, 403, writer.Code)
+	})
+
+	t.Run("Limited Before Auth", func(t *testing.T) {
+		// Rejected requests do not look up credentials in the database.
+		unreachable := pg.Connect(&pg.Options{Addr: "127.0.0.1:1"})
+		defer unreachable.Close()
+		cfg := *testConfig
+		cfg.RateLimit.WriteRate = 0.01
+		cfg.RateLimit.WriteBurst = 1
+		limited := api.StartAPI(unreachable, &cfg)
+
+		codes := make([]int, 0)
+		for i := 0; i < 2; i++ {
+			request, _ := http.NewRequest("DELETE", "/v2/films/999999", nil)
+			request.SetBasicAuth("admin", "wrong")
+			writer := httptest.NewRecorder()
+			limited.ServeHTTP(writer, request)
+			codes = append(codes, writer.Code)
+		}
+		assert.Equal(t, []int{500, 429}, codes)
+	})
+
+	t.Run("Spoofed Username", func(t *testing.T) {
+		cfg := *testConfig
+		cfg.RateLimit.WriteRate = 0.01
+		cfg.RateLimit.WriteBurst = 1
+		limited := api.StartAPI(testDB, &cfg)
+
+		// Failed logins are charged to the IP, not to the claimed user.
+		codes := make([]int, 0)
+		for i := 0; i < 2; i++ {
+			request, _ := http.NewRequest("DELETE", "/v2/films/999999", nil)
+			request.RemoteAddr = "203.0.113.7:1234"
+			request.SetBasicAuth("admin", "wrong")
+			writer := httptest.NewRecorder()
+			limited.ServeHTTP(writer, request)
+			codes = append(codes, writer.Code)
+		}
+		assert.Equal(t, []int{401, 429}, codes)
+
+		request, _ := http.NewRequest("DELETE", "/v2/films/999999", nil)
+		request.SetBasicAuth("admin", "admin")
+		writer := httptest.NewRecorder()
+		limited.ServeHTTP(writer, request)
+		assert.Equal(t, 404, writer.Code)
+	})
+
+	t.Run("Rotated Username", func(t *testing.T) {
+		cfg := *testConfig
+		cfg.RateLimit.WriteRate = 0.01
+		cfg.RateLimit.WriteBurst = 1
+		limited := api.StartAPI(testDB, &cfg)
+
+		// Unknown usernames do not get buckets of their own.
+		codes := make([]int, 0)
+		for _, username := range []string{"random1", "random2", "random3"} {
+			request, _ := http.NewRequest("DELETE", "/v2/films/999999", nil)
+			request.SetBasicAuth(username, "wrong")
+			writer := httptest.NewRecorder()
+			limited.ServeHTTP(writer, request)
+			codes = append(codes, writer.Code)
+		}
+		assert.Equal(t, []int{401, 429, 429}, codes)
+	})
+
+	t.Run("Daily Quota", func(t *testing.T) {
+		codes := make([]int, 0)
+		for i := 0; i < 3; i++ {
+			request, _ := http.NewRequest("GET", "/v2/films", nil)
+			request.SetBasicAuth("client", "client")
+			request.Header.Set("X-API-Key", "limited-key")
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			codes = append(codes, writer.Code)
+
+			assert.Equal(t, "1000;w=1, 2;w=86400", writer.Header().Get("RateLimit-Policy"))
+			if writer.Code == 429 {
+				assert.NotEmpty(t, writer.Header().Get("Retry-After"))
+				assert.Equal(t, "2", writer.Header().Get("RateLimit-Limit"))
+				problem := api.ErrorResponse{}
+				json.Unmarshal(writer.Body.Bytes(), &problem)
+				assert.Equal(t, "quota_exceeded", problem.Code)
+			}
+		}
+		assert.Equal(t, []int{200, 200, 429}, codes)
+	})
+}
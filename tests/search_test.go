@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchLanguage(t *testing.T) {
+	assert.Equal(t, db.LangRussian, db.SearchLanguage("Матрица"))
+	assert.Equal(t, db.LangRussian, db.SearchLanguage("Matrix Перезагрузка"))
+	assert.Equal(t, db.LangEnglish, db.SearchLanguage("Matrix"))
+}
+
+func TestSearch(t *testing.T) {
+	body, _ := json.Marshal(api_models.CreateFilmRequest{
+		Name:        "Матрица",
+		Description: "Хакер узнает правду о симуляции и сражается с машинами",
+		Date:        "1999-03-31",
+		Rate:        9,
+	})
+	request, _ := http.NewRequest("POST", "/films", bytes.NewBuffer(body))
+	request.SetBasicAuth("admin", "admin")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	createTestActor(t, "Keanu Reeves")
+
+	testCases := []struct {
+		name     string
+		query    url.Values
+		username string
+		password string
+		code     int
+		result   string
+	}{
+		{
+			name:  "No Auth",
+			query: url.Values{"q": {"матрица"}},
+			code:  401,
+		},
+		{
+			name:     "Missing Query",
+			query:    url.Values{},
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+		{
+			name:     "Invalide Type",
+			query:    url.Values{"q": {"матрица"}, "type": {"user"}},
+			username: "client",
+			password: "client",
+			code:     400,
+		},
+		{
+			name:     "Russian Stemming",
+			query:    url.Values{"q": {"симуляцией машин"}},
+			username: "client",
+			password: "client",
+			code:     200,
+			result:   "Матрица",
+		},
+		{
+			name:     "Actor By Name",
+			query:    url.Values{"q": {"reeves"}, "type": {"actor"}},
+			username: "client",
+			password: "client",
+			code:     200,
+			result:   "Keanu Reeves",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", "/search?"+tc.query.Encode(), nil)
+			if tc.username != "" && tc.password != "" {
+				request.SetBasicAuth(tc.username, tc.password)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.code, writer.Code)
+
+			if writer.Code == 200 {
+				resp := api_models.SearchResponse{}
+				json.Unmarshal(writer.Body.Bytes(), &resp)
+				if assert.NotEmpty(t, resp.Results) {
+					assert.Equal(t, tc.result, resp.Results[0].Name)
+					assert.Contains(t, resp.Results[0].Snippet, "<b>")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"filmoteka/api"
+	api_models "filmoteka/api/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		url        string
+		accept     string
+		status     int
+		listKey    string
+		deprecated bool
+	}{
+		{
+			name:       "Unprefixed Alias",
+			url:        "/films",
+			status:     200,
+			listKey:    "film",
+			deprecated: true,
+		},
+		{
+			name:    "V1",
+			url:     "/v1/films",
+			status:  200,
+			listKey: "film",
+		},
+		{
+			name:    "V2",
+			url:     "/v2/films",
+			status:  200,
+			listKey: "films",
+		},
+		{
+			name:    "V2 By Accept",
+			url:     "/films",
+			accept:  "application/vnd.filmoteka.v2+json",
+			status:  200,
+			listKey: "films",
+		},
+		{
+			name:   "Unknown Version",
+			url:    "/films",
+			accept: "application/vnd.filmoteka.v9+json",
+			status: 406,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, _ := http.NewRequest("GET", tc.url, nil)
+			request.SetBasicAuth("client", "client")
+			if tc.accept != "" {
+				request.Header.Set("Accept", tc.accept)
+			}
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, request)
+			assert.Equal(t, tc.status, writer.Code)
+
+			if tc.deprecated {
+				assert.NotEmpty(t, writer.Header().Get("Deprecation"))
+				assert.NotEmpty(t, writer.Header().Get("Sunset"))
+				assert.Equal(t, `</v2/films>; rel="successor-version"`, writer.Header().Get("Link"))
+			} else {
+				assert.Empty(t, writer.Header().Get("Deprecation"))
+			}
+			if tc.listKey != "" {
+				body := map[string]json.RawMessage{}
+				json.Unmarshal(writer.Body.Bytes(), &body)
+				assert.Contains(t, body, tc.listKey)
+			}
+		})
+	}
+}
+
+func TestCurrentUser(t *testing.T) {
+	request, _ := http.NewRequest("GET", "/v2/users/me", nil)
+	request.SetBasicAuth("admin", "admin")
+	writer := httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	res := api_models.UserResponse{}
+	json.Unmarshal(writer.Body.Bytes(), &res)
+	if assert.Equal(t, 200, writer.Code) && assert.NotNil(t, res.User) {
+		assert.Equal(t, "admin", res.User.Username)
+		assert.Equal(t, "admin", res.User.Role)
+	}
+
+	request, _ = http.NewRequest("GET", "/v1/users/me", nil)
+	request.SetBasicAuth("admin", "admin")
+	writer = httptest.NewRecorder()
+	router.ServeHTTP(writer, request)
+	assert.Equal(t, 404, writer.Code)
+	problem := api.ErrorResponse{}
+	json.Unmarshal(writer.Body.Bytes(), &problem)
+	assert.Equal(t, "not_found", problem.Code)
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"filmoteka/api"
+	api_models "filmoteka/api/models"
+	"filmoteka/db"
+	"filmoteka/webhooks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hookReceiver records deliveries and answers them with status.
+type hookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (h *hookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests = append(h.requests, r)
+	h.bodies = append(h.bodies, body)
+	w.WriteHeader(h.status)
+}
+
+func TestWebhooks(t *testing.T) {
+	send := func(method string, url string, body string, username string) *httptest.ResponseRecorder {
+		request, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+		request.SetBasicAuth(username, username)
+		writer := httptest.NewRecorder()
+		router.ServeHTTP(writer, request)
+		return writer
+	}
+	subscribe := func(url string, events string) *api_models.WebhookResponse {
+		writer := send("POST", "/webhooks", fmt.Sprintf(`{"url":%q,"events":%s}`, url, events), "admin")
+		assert.Equal(t, 201, writer.Code)
+		resp := &api_models.WebhookResponse{}
+		json.Unmarshal(writer.Body.Bytes(), resp)
+		if assert.NotNil(t, resp.Webhook) {
+			assert.NotEmpty(t, resp.Secret)
+			assert.Equal(t, fmt.Sprintf("/webhooks/%d", resp.Webhook.ID), writer.Header().Get("Location"))
+		}
+		return resp
+	}
+	deliveries := func(webhookID int64) []*db.WebhookDelivery {
+		writer := send("GET", fmt.Sprintf("/webhooks/%d/deliveries", webhookID), "", "admin")
+		assert.Equal(t, 200, writer.Code)
+		resp := &api_models.WebhookDeliveriesResponse{}
+		json.Unmarshal(writer.Body.Bytes(), resp)
+		return resp.Deliveries
+	}
+
+	t.Run("Signed Delivery", func(t *testing.T) {
+		receiver := &hookReceiver{status: 204}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		hook := subscribe(server.URL, `["actor.created"]`)
+		created := send("POST", "/actors", `{"name":"WebhookActor","sex":"female","birth":"1985-03-04"}`, "admin")
+		assert.Equal(t, 200, created.Code)
+		send("POST", "/films", `{"name":"WebhookFilm","date":"2001-01-01","rate":5}`, "admin")
+
+		err := dispatcher.RunOnce(context.Background())
+		assert.NoError(t, err)
+
+		if !assert.Len(t, receiver.requests, 1) {
+			return
+		}
+		request, body := receiver.requests[0], receiver.bodies[0]
+		assert.Equal(t, "actor.created", request.Header.Get(webhooks.HeaderEvent))
+		signature := request.Header.Get(webhooks.HeaderSignature)
+		var timestamp int64
+		var sign string
+		fmt.Sscanf(strings.Replace(signature, ",v1=", " ", 1), "t=%d %s", &timestamp, &sign)
+		assert.Equal(t, webhooks.Sign(hook.Secret, timestamp, body), sign)
+
+		event := db.Event{}
+		json.Unmarshal(body, &event)
+		assert.Equal(t, "actor.created", event.Type)
+		assert.Contains(t, string(event.Payload), "WebhookActor")
+
+		logged := deliveries(hook.Webhook.ID)
+		if assert.Len(t, logged, 1) {
+			assert.Equal(t, db.DeliverySucceeded, logged[0].Status)
+			assert.Equal(t, 1, logged[0].Attempts)
+			assert.Equal(t, 204, logged[0].ResponseStatus)
+		}
+
+		send("DELETE", fmt.Sprintf("/webhooks/%d", hook.Webhook.ID), "", "admin")
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		receiver := &hookReceiver{status: 500}
+		server := httptest.NewServer(receiver)
+		defer server.Close()
+
+		hook := subscribe(server.URL, `["film.*"]`)
+		send("POST", "/films", `{"name":"RetriedFilm","date":"2002-02-02","rate":6}`, "admin")
+
+		err := dispatcher.RunOnce(context.Background())
+		assert.NoError(t, err)
+		// The retry is not due yet.
+		err = dispatcher.RunOnce(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, receiver.requests, 1)
+
+		logged := deliveries(hook.Webhook.ID)
+		if assert.Len(t, logged, 1) {
+			assert.Equal(t, db.DeliveryPending, logged[0].Status)
+			assert.Equal(t, 1, logged[0].Attempts)
+			assert.Equal(t, 500, logged[0].ResponseStatus)
+			assert.True(t, logged[0].NextAttemptAt.After(logged[0].CreatedAt))
+		}
+
+		send("DELETE", fmt.Sprintf("/webhooks/%d", hook.Webhook.ID), "", "admin")
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		writer := send("POST", "/webhooks", `{"url":"ftp://example.com","events":["film.renamed"]}`, "admin")
+		assert.Equal(t, 400, writer.Code)
+
+		problem := api.ErrorResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &problem)
+		fields := []string{}
+		for _, field := range problem.Errors {
+			fields = append(fields, field.Field)
+		}
+		assert.ElementsMatch(t, []string{"url", "events[0]"}, fields)
+	})
+
+	t.Run("Admin Only", func(t *testing.T) {
+		writer := send("GET", "/webhooks", "", "client")
+		assert.Equal(t, 403, writer.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		writer := send("GET", "/webhooks/100500/deliveries", "", "admin")
+		assert.Equal(t, 404, writer.Code)
+
+		problem := api.ErrorResponse{}
+		json.Unmarshal(writer.Body.Bytes(), &problem)
+		assert.Equal(t, "webhook_not_found", problem.Code)
+	})
+}
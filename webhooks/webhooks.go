@@ -0,0 +1,188 @@
+// Package webhooks delivers events recorded in the outbox to subscribed
+// webhooks, failed deliveries are retried with exponential backoff.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"filmoteka/config"
+	"filmoteka/db"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// Headers of delivery requests.
+const (
+	HeaderEvent     = "X-Filmoteka-Event"
+	HeaderDelivery  = "X-Filmoteka-Delivery"
+	HeaderSignature = "X-Filmoteka-Signature"
+)
+
+// maxErrorLength limits response bodies kept in the delivery log.
+const maxErrorLength = 500
+
+// Sign is a hex HMAC-SHA256 of "timestamp.body" with the webhook secret.
+// Receivers compare it with v1 of the signature header and reject stale
+// timestamps to prevent replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureHeader is a value of X-Filmoteka-Signature.
+func SignatureHeader(secret string, timestamp int64, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, Sign(secret, timestamp, body))
+}
+
+// Dispatcher moves events from the outbox into deliveries and sends them.
+type Dispatcher struct {
+	db     *pg.DB
+	cfg    config.Webhooks
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewDispatcher(db *pg.DB, cfg config.Webhooks) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}
+}
+
+// Run dispatches events every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := d.RunOnce(ctx)
+		if err != nil {
+			slog.Error("webhooks dispatch failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce dispatches all pending events and sends due deliveries.
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	for {
+		count, err := db.DispatchEvents(d.db, d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		if count < d.cfg.BatchSize {
+			break
+		}
+	}
+
+	for ctx.Err() == nil {
+		// Claimed deliveries are hidden from other dispatchers until the
+		// request is surely over.
+		deliveries, err := db.ClaimDeliveries(d.db, d.now(), 2*d.cfg.Timeout, d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range deliveries {
+			d.deliver(ctx, delivery)
+			err = db.SaveDeliveryAttempt(d.db, delivery)
+			if err != nil {
+				return err
+			}
+		}
+		if len(deliveries) < d.cfg.BatchSize {
+			break
+		}
+	}
+	return ctx.Err()
+}
+
+// deliver sends the delivery once and records the result in it.
+func (d *Dispatcher) deliver(ctx context.Context, delivery *db.WebhookDelivery) {
+	delivery.Attempts++
+	if delivery.Webhook == nil || delivery.Event == nil || !delivery.Webhook.Active {
+		// The webhook was removed or disabled after the event.
+		delivery.Status = db.DeliveryFailed
+		delivery.Error = "webhook is not active"
+		return
+	}
+
+	status, err := d.send(ctx, delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		now := d.now()
+		delivery.Status = db.DeliverySucceeded
+		delivery.Error = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.Error = err.Error()
+	if delivery.Attempts >= d.cfg.MaxAttempts {
+		delivery.Status = db.DeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *db.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Filmoteka-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderSignature, SignatureHeader(delivery.Webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, text)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorLength))
+	return resp.StatusCode, nil
+}
+
+// backoff is the pause after the attempt, base doubled each time up to max.
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempt && delay < d.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.BackoffMax {
+		delay = d.cfg.BackoffMax
+	}
+	return delay
+}